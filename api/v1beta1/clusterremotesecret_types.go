@@ -0,0 +1,64 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRemoteSecretSpec defines the desired state of ClusterRemoteSecret
+type ClusterRemoteSecretSpec struct {
+	RemoteSecretSpec `json:",inline"`
+	// NamespaceSelector selects the namespaces in the local cluster that the secret and service accounts should be
+	// deployed to in addition to the explicitly listed targets. If not specified, only the explicit targets are used.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// TargetNamespaceTemplate optionally derives the namespace to deploy to from each namespace matching the NamespaceSelector
+	// instead of deploying to the matching namespace itself. It is a Go template that can reference the Name, Labels and
+	// Annotations of the matching namespace, e.g. "{{ .Labels.team }}-shared". The matching namespaces for which the template
+	// cannot be rendered (e.g. because they lack the referenced label) or renders an invalid namespace name are skipped and
+	// reported in the NamespacesSkipped condition.
+	// +optional
+	TargetNamespaceTemplate string `json:"targetNamespaceTemplate,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// ClusterRemoteSecret is the Schema for the ClusterRemoteSecret API. It is a cluster-scoped variant of the RemoteSecret
+// that can deploy the secret to all the namespaces matching a selector.
+type ClusterRemoteSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRemoteSecretSpec `json:"spec,omitempty"`
+	Status RemoteSecretStatus      `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterRemoteSecretList contains a list of ClusterRemoteSecret
+type ClusterRemoteSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRemoteSecret `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRemoteSecret{}, &ClusterRemoteSecretList{})
+}
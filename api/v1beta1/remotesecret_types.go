@@ -19,6 +19,7 @@ package v1beta1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // RemoteSecretSpec defines the desired state of RemoteSecret
@@ -29,17 +30,153 @@ type RemoteSecretSpec struct {
 	// Targets is the list of the target namespaces that the secret and service accounts should be deployed to.
 	// +optional
 	Targets []RemoteSecretTarget `json:"targets,omitempty"`
+	// DataFrom optionally specifies an existing object in the namespace of the remote secret that the secret data is copied from.
+	// If not specified, the data needs to be uploaded to the remote secret.
+	// +optional
+	DataFrom *DataFrom `json:"dataFrom,omitempty"`
+	// DataSources optionally specifies multiple objects in the namespace of the remote secret that the secret data is copied
+	// from. The data of all the sources is merged in the listed order. If DataFrom is also specified, it is the first source.
+	// +optional
+	DataSources []DataFrom `json:"dataSources,omitempty"`
+	// DataSourcesConflictPolicy specifies what happens if multiple data sources contain the same key. "LastWins" (the default)
+	// uses the value from the last source containing the key, "Error" fails the retrieval of the data.
+	// +optional
+	// +kubebuilder:default=LastWins
+	DataSourcesConflictPolicy DataSourcesConflictPolicy `json:"dataSourcesConflictPolicy,omitempty"`
+	// KeyTombstone is the value marking the keys of the secret data as deleted. The keys with this value are removed from the data,
+	// including the values of the same keys in the preceding data sources, and therefore also from the secrets in the targets.
+	// This makes it possible to delete the keys provided by other data sources. Note that the keys are only ever removed from
+	// the targets once their absence is positively reported by the data sources - the failure to obtain the data never does that.
+	// The tombstones are not considered conflicting with the values in the other data sources.
+	// +optional
+	KeyTombstone string `json:"keyTombstone,omitempty"`
+	// Suspend stops the reconciliation of the remote secret. The secrets and service accounts already deployed to the targets
+	// are left intact. The reconciliation resumes once this is set back to false.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// NotBefore delays the deployment of the secret to the targets until the given time. Until then, the remote secret is
+	// reconciled as if it was suspended and the reconciliation is scheduled for the time. This can be used to pre-stage
+	// the changes of the secret that should only take effect at a certain time.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+	// MaxTargets limits the number of the targets the secret can be deployed to. If there are more targets (e.g. because
+	// the namespace selector of a cluster remote secret matches more namespaces than expected), the secret is not deployed
+	// to any of them. The limit of the operator configuration applies, too. Zero means no limit.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxTargets int `json:"maxTargets,omitempty"`
+	// DeletionPolicy specifies what happens to the secrets and service accounts deployed to the targets when the remote secret
+	// is deleted. "Delete" (the default) deletes them, "Orphan" leaves them in place and only removes the labels and annotations
+	// linking them to the remote secret.
+	// +optional
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
+// DataFrom specifies the object to copy the secret data from.
+type DataFrom struct {
+	// Kind is the kind of the object to copy the data from. Either "Secret" (the default), "ConfigMap" or "ExternalSecret".
+	// The data of the ExternalSecret (of the External Secrets Operator) is copied from the secret it produces once it is ready.
+	// +optional
+	// +kubebuilder:default=Secret
+	Kind DataFromKind `json:"kind,omitempty"`
+	// Name is the name of the object to copy the data from. It must not be empty, because there would be no object to copy
+	// the data from.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace is the namespace of the object to copy the data from. It is required by the ClusterRemoteSecrets, which have no
+	// namespace of their own. The RemoteSecrets can only copy the data from their own namespace, so if specified, it must be
+	// the namespace of the RemoteSecret.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// ConsumeUploadData makes the data of the secret copied into the storage of the remote secret after which the secret is
+	// deleted so that there is only a single copy of the data. If the secret is later re-created, its data replaces the stored
+	// data and the secret is deleted again. This only applies to secrets, config maps are never consumed.
+	// +optional
+	ConsumeUploadData bool `json:"consumeUploadData,omitempty"`
+}
+
+// DataFromKind is the kind of the object the secret data is copied from.
+// +kubebuilder:validation:Enum=Secret;ConfigMap;ExternalSecret
+type DataFromKind string
+
+const (
+	DataFromKindSecret    DataFromKind = "Secret"
+	DataFromKindConfigMap DataFromKind = "ConfigMap"
+	// DataFromKindExternalSecret is the ExternalSecret of the External Secrets Operator. The data is copied from the secret
+	// produced by it.
+	DataFromKindExternalSecret DataFromKind = "ExternalSecret"
+)
+
+// DataSourcesConflictPolicy specifies what happens if multiple data sources contain the same key.
+// +kubebuilder:validation:Enum=LastWins;Error
+type DataSourcesConflictPolicy string
+
+const (
+	DataSourcesConflictPolicyLastWins DataSourcesConflictPolicy = "LastWins"
+	DataSourcesConflictPolicyError    DataSourcesConflictPolicy = "Error"
+)
+
+// DeletionPolicy specifies what happens to the objects deployed to the targets when the remote secret is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
 type RemoteSecretTarget struct {
-	// Namespace is the name of the target namespace to which to deploy.
+	// Namespace is the name of the target namespace to which to deploy. It must be a valid DNS-1123 label.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)?$`
 	Namespace string `json:"namespace,omitempty"`
 	// ApiUrl specifies the URL of the API server of a remote Kubernetes cluster that this target points to. If left empty,
 	// the local cluster is assumed.
 	ApiUrl string `json:"apiUrl,omitempty"`
-	// ClusterCredentialsSecret is the name of the secret in the same namespace as the RemoteSecret that contains the token
-	// to use to authenticate with the remote Kubernetes cluster. This is ignored if `apiUrl` is empty.
+	// ClusterCredentialsSecret is the name of the secret in the same namespace as the RemoteSecret that contains the credentials
+	// to use to authenticate with the remote Kubernetes cluster. The secret either contains the kubeconfig under the `kubeconfig` key
+	// or the bearer token under the `token` key, optionally with the CA certificate of the cluster under the `ca.crt` key. The server
+	// configured in the kubeconfig is always replaced by the `apiUrl`. This is required if `apiUrl` is specified and ignored otherwise.
 	ClusterCredentialsSecret string `json:"clusterCredentialsSecret,omitempty"`
+	// Cluster is the name of the remote cluster in the cluster registry. The API URL and the cluster credentials secret of
+	// the cluster are looked up in the registry, so neither `apiUrl` nor `clusterCredentialsSecret` can be specified together
+	// with it. By default, the registry consists of the secrets in the namespace of the RemoteSecret labeled with
+	// `appstudio.redhat.com/cluster-name` the value of which is the name of the cluster. Such secret contains the API URL of
+	// the cluster under the `apiUrl` key and the credentials to it in the same format as the cluster credentials secret.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+	// KeyFilter optionally restricts the keys of the secret data that are deployed to this target.
+	// +optional
+	KeyFilter *KeyFilter `json:"keyFilter,omitempty"`
+	// Wave specifies the order in which the targets are deployed to. The targets with lower waves are deployed to first and
+	// the targets in the next wave are only deployed to once all the targets in the previous waves are synced. The targets
+	// in the same wave are deployed to in no particular order. All targets are in the wave 0 by default.
+	// +optional
+	Wave int `json:"wave,omitempty"`
+	// Type optionally overrides the type of the secret from the secret spec for this target. The secret data must contain
+	// the keys required by the type, otherwise the deployment to the target fails.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Immutable optionally overrides the immutability of the secret from the secret spec for this target.
+	// +optional
+	Immutable *bool `json:"immutable,omitempty"`
+	// Optional marks the target as not essential for the remote secret. The failures to deploy to an optional target are
+	// recorded in its status but they neither mark the deployment as failed nor block the deployment to the later waves.
+	// The deployment to the failed optional targets is retried only occasionally.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// KeyFilter specifies which keys of the secret data should be deployed to a target.
+type KeyFilter struct {
+	// Include is the list of the keys that should be deployed to the target. If empty, all keys are included.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude is the list of the keys that should never be deployed to the target. Exclusion takes precedence over
+	// inclusion.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // RemoteSecretStatus defines the observed state of RemoteSecret
@@ -51,21 +188,94 @@ type RemoteSecretStatus struct {
 	// Targets is the list of the deployment statuses for individual targets in the spec.
 	// +optional
 	Targets []TargetStatus `json:"targets,omitempty"`
+	// TotalTargets is the number of the targets the secret should be deployed to.
+	// +optional
+	TotalTargets int `json:"totalTargets,omitempty"`
+	// SyncedTargets is the number of the targets the secret has been successfully deployed to.
+	// +optional
+	SyncedTargets int `json:"syncedTargets,omitempty"`
+	// ObservedForceSync is the value of the ForceSyncAnnotation that was last processed by the controller.
+	// +optional
+	ObservedForceSync string `json:"observedForceSync,omitempty"`
+	// ObservedGeneration is the generation of the object that has been successfully deployed to all the targets.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastDataObtainedTime is the time when the data of the secret was last successfully obtained from the DataSourceRef. Note that
+	// the data might have been served from the data cache of the operator, if enabled, so the source itself might have been read
+	// up to the TTL of the cache earlier.
+	// +optional
+	LastDataObtainedTime *metav1.Time `json:"lastDataObtainedTime,omitempty"`
+	// DataSourceRef describes the data stores the data of the secret was last successfully obtained from, e.g. "local://" or
+	// "secret://my-secret". Multiple data stores are separated by commas in the order in which their data was merged.
+	// +optional
+	DataSourceRef string `json:"dataSourceRef,omitempty"`
+	// DataKeys is the sorted list of the keys of the secret data last successfully obtained from the DataSourceRef. The values
+	// are never exposed in the status.
+	// +optional
+	DataKeys []string `json:"dataKeys,omitempty"`
+	// ManagedSecrets is the inventory of the secrets in the targets that are managed by the remote secret. It is updated on each
+	// reconciliation and used to find the secrets to clean up even if they cannot be found using their labels.
+	// +optional
+	ManagedSecrets []ManagedSecretReference `json:"managedSecrets,omitempty"`
 }
 
+// ManagedSecretReference identifies a secret managed by the remote secret in one of its targets.
+type ManagedSecretReference struct {
+	// ApiUrl is the URL of the remote Kubernetes cluster the secret lives in. Empty for the local cluster.
+	// +optional
+	ApiUrl string `json:"apiUrl,omitempty"`
+	// Namespace is the namespace of the secret.
+	Namespace string `json:"namespace"`
+	// Name is the name of the secret.
+	Name string `json:"name"`
+}
+
+// ForceSyncAnnotation is the annotation on the remote secret that can be used to force the full re-sync of all the targets. Whenever
+// its value changes (e.g. by setting it to the current timestamp), the secrets in all the targets are updated even if they seem
+// to be up-to-date.
+const ForceSyncAnnotation = "appstudio.redhat.com/force-sync"
+
 type TargetStatus struct {
 	// Namespace is the namespace of the target where the secret and the service accounts have been deployed to.
 	Namespace string `json:"namespace"`
 	// ApiUrl is the URL of the remote Kubernetes cluster to which the target points to.
 	ApiUrl string `json:"apiUrl,omitempty"`
+	// ClusterCredentialsSecret is the name of the secret with the credentials to the remote Kubernetes cluster used when the secret
+	// was deployed to the target. It is used to clean up the target after it is removed from the spec.
+	// +optional
+	ClusterCredentialsSecret string `json:"clusterCredentialsSecret,omitempty"`
 	// SecretName is the name of the secret that is actually deployed to the target namespace
 	SecretName string `json:"secretName"`
+	// DefaultSecretName is true if the name of the secret has been generated using the default prefix, because neither the
+	// name, the generateName nor the generateNamePrefix of the secret is specified.
+	// +optional
+	DefaultSecretName bool `json:"defaultSecretName,omitempty"`
 	// ServiceAccountNames is the names of the service accounts that have been deployed to the target namespace
 	// +optional
 	ServiceAccountNames []string `json:"serviceAccountNames,omitempty"`
+	// ConfigMapName is the name of the config map that has been deployed to the target namespace together with the secret.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
 	// Error the optional error message if the deployment of either the secret or the service accounts failed.
 	// +optional
 	Error string `json:"error,omitempty"`
+	// ErrorReason is the machine-readable classification of the Error, e.g. "ClusterUnreachable", "Unauthorized", "Forbidden"
+	// or "NamespaceNotFound".
+	// +optional
+	ErrorReason string `json:"errorReason,omitempty"`
+	// DataHash is the short hash of the secret data last written to the target.
+	// +optional
+	DataHash string `json:"dataHash,omitempty"`
+	// DataSyncTime is the time when the data with the DataHash has been written to the target.
+	// +optional
+	DataSyncTime *metav1.Time `json:"dataSyncTime,omitempty"`
+	// SecretUID is the UID of the secret in the target as last observed by the controller. A change of it means that the secret
+	// has been deleted and re-created outside of the controller.
+	// +optional
+	SecretUID types.UID `json:"secretUid,omitempty"`
+	// SecretResourceVersion is the resource version of the secret in the target as last observed by the controller.
+	// +optional
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
 }
 
 // RemoteSecretReason is the reconciliation status of the RemoteSecret object
@@ -77,16 +287,29 @@ type RemoteSecretConditionType string
 const (
 	RemoteSecretConditionTypeDeployed     RemoteSecretConditionType = "Deployed"
 	RemoteSecretConditionTypeDataObtained RemoteSecretConditionType = "DataObtained"
+	RemoteSecretConditionTypeSuspended    RemoteSecretConditionType = "Suspended"
+	// RemoteSecretConditionTypeNamespacesSkipped is only used by the cluster remote secrets.
+	RemoteSecretConditionTypeNamespacesSkipped RemoteSecretConditionType = "NamespacesSkipped"
 
 	RemoteSecretReasonAwaitingTokenData RemoteSecretReason = "AwaitingData"
 	RemoteSecretReasonDataFound         RemoteSecretReason = "DataFound"
 	RemoteSecretReasonInjected          RemoteSecretReason = "Injected"
+	RemoteSecretReasonInjecting         RemoteSecretReason = "Injecting"
 	RemoteSecretReasonPartiallyInjected RemoteSecretReason = "PartiallyInjected"
 	RemoteSecretReasonError             RemoteSecretReason = "Error"
+	RemoteSecretReasonDataSourceMissing RemoteSecretReason = "DataSourceMissing"
+	RemoteSecretReasonDataSourceDenied  RemoteSecretReason = "DataSourceDenied"
+	RemoteSecretReasonSuspended         RemoteSecretReason = "Suspended"
+	RemoteSecretReasonNotBefore         RemoteSecretReason = "NotBefore"
+	RemoteSecretReasonTargetsForbidden  RemoteSecretReason = "TargetsForbidden"
+	RemoteSecretReasonCircuitOpen       RemoteSecretReason = "CircuitOpen"
+	RemoteSecretReasonTooManyTargets    RemoteSecretReason = "TooManyTargets"
+	RemoteSecretReasonTemplateFailed    RemoteSecretReason = "TemplateFailed"
 )
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // RemoteSecret is the Schema for the RemoteSecret API
 type RemoteSecret struct {
@@ -111,11 +334,19 @@ func init() {
 }
 
 type LinkableSecretSpec struct {
-	// Name is the name of the secret to be created. If it is not defined a random name based on the name of the binding
-	// is used.
+	// Name is the name of the secret to be created. If neither the name nor the generateName is defined, the secret is
+	// created with a random name prefixed by the generateNamePrefix or, if that is not defined either, by
+	// "<name of the remote secret>-secret-". The name of the secret actually deployed to a target is reported in the status
+	// of the target.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// GenerateName is the prefix of the random name of the secret to be created if the name is not defined.
 	// +optional
-	Name         string `json:"name,omitempty"`
 	GenerateName string `json:"generateName,omitempty"`
+	// GenerateNamePrefix replaces the default prefix "<name of the remote secret>-secret-" of the random name of the secret
+	// created when neither the name nor the generateName is defined.
+	// +optional
+	GenerateNamePrefix string `json:"generateNamePrefix,omitempty"`
 	// Labels contains the labels that the created secret should be labeled with.
 	Labels map[string]string `json:"labels,omitempty"`
 	// Annotations is the keys and values that the create secret should be annotated with.
@@ -126,10 +357,95 @@ type LinkableSecretSpec struct {
 	// Only kubernetes.io/service-account-token, kubernetes.io/dockercfg, kubernetes.io/dockerconfigjson and kubernetes.io/basic-auth
 	// are supported. All other secret types need to have their mapping specified manually using the Fields.
 	Type corev1.SecretType `json:"type,omitempty"`
+	// Immutable makes the created secret immutable. Because Kubernetes doesn't allow to change the immutability of an existing
+	// secret nor to update an immutable secret, the secret is deleted and created again if it needs to change.
+	// +optional
+	Immutable *bool `json:"immutable,omitempty"`
+	// ValidateContents makes the controller check that the data can be parsed as required by the type of the secret before
+	// deploying it. Currently, only the kubernetes.io/ssh-auth secrets are checked - the ssh-privatekey must be an unencrypted
+	// private key and the optional known_hosts must be in the known_hosts format. This is disabled by default, because the
+	// encrypted private keys cannot be parsed.
+	// +optional
+	ValidateContents bool `json:"validateContents,omitempty"`
+	// Templates declares additional keys of the secret data whose values are rendered from Go templates. The templates can
+	// reference the other keys of the secret data, e.g. "postgres://{{.user}}:{{.password}}@{{.host}}/{{.db}}". The templates
+	// are rendered after the data is obtained and before it is deployed to the targets. Only the builtin functions of the Go
+	// templates are available. The keys of the templates must neither be present in the obtained data nor be the key
+	// the registries are aggregated into, because the value of such key would be ambiguous.
+	// +optional
+	Templates map[string]string `json:"templates,omitempty"`
+	// OptionalKeyNames lists the keys of the secret data that are deployed only if they are present in the data. Their absence
+	// doesn't fail the deployment. If not empty, the deployed secret only contains the keys required by the secret type, the
+	// optional keys and the keys of the templates. The optional keys must not include the keys required by the secret type.
+	// +optional
+	OptionalKeyNames []string `json:"optionalKeyNames,omitempty"`
+	// Registries declares the container registry credentials in the secret data that are aggregated into the single
+	// .dockerconfigjson key (or .dockercfg for the kubernetes.io/dockercfg secrets). The auths of the registries are added
+	// to the auths already present under that key in the data, if any. This can only be used with the
+	// kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg secrets.
+	// +optional
+	Registries []RegistryCredentials `json:"registries,omitempty"`
+	// AdoptExisting makes the secret that already exists in the target but is not managed by the remote secret taken over by
+	// it. If false (the default), such secret is left intact and the deployment to the target fails. The secrets controlled
+	// by another controller (i.e. with a controller owner reference) are only adopted if AdoptControlled is true, too.
+	// +optional
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+	// AdoptControlled forces the adoption of the existing secrets that are controlled by another controller. Such controller
+	// will most probably keep overwriting the secret, so this should only be used if the controller is known to tolerate it.
+	// +optional
+	AdoptControlled bool `json:"adoptControlled,omitempty"`
 
 	// LinkedTo specifies the objects that the secret is linked to. Currently, only service accounts are supported.
 	LinkedTo []SecretLink `json:"linkedTo,omitempty"`
+	// DeploymentMode specifies how the secret is deployed to the targets. "Manage" (the default) means that the secret is
+	// fully managed by the remote secret, i.e. created, updated and deleted along with it. The data of the secret is an exact
+	// mirror of the data of the remote secret, the keys added to the secret by anyone else are removed when it is updated
+	// (unless the operator writes the secrets using the server-side apply, in which case only the keys of the remote secret
+	// are owned by it).
+	// "Contribute" means that the secret
+	// with the configured name must already exist in the target and the remote secret only writes its keys into it,
+	// leaving all other keys intact. When the secret is removed from the target, only the contributed keys are removed
+	// from it. The labels, annotations and the type of the secret are ignored in the "Contribute" mode. "CreateOnly" means
+	// that the secret is created if it doesn't exist in the target but once it exists, it is never updated by the remote secret.
+	// +optional
+	// +kubebuilder:default=Manage
+	DeploymentMode SecretDeploymentMode `json:"deploymentMode,omitempty"`
+	// ConfigMap optionally specifies the config map that is deployed to the targets together with the secret. The configured keys
+	// of the secret data are moved from the secret to the config map. The config map is managed by the remote secret just like
+	// the secret, i.e. it is created, updated and deleted along with it.
+	// +optional
+	ConfigMap *CompanionConfigMap `json:"configMap,omitempty"`
 }
+
+// CompanionConfigMap specifies the config map deployed to the targets together with the secret.
+type CompanionConfigMap struct {
+	// Name is the name of the config map.
+	Name string `json:"name"`
+	// Keys are the keys of the secret data that are put into the config map instead of the secret. The keys missing in the secret
+	// data are ignored.
+	Keys []string `json:"keys"`
+}
+
+// RegistryCredentials specifies the keys of the secret data containing the credentials for a container registry.
+type RegistryCredentials struct {
+	// Registry is the host name of the registry, e.g. "quay.io".
+	Registry string `json:"registry"`
+	// UsernameKey is the key of the secret data containing the user name.
+	UsernameKey string `json:"usernameKey"`
+	// PasswordKey is the key of the secret data containing the password or the token.
+	PasswordKey string `json:"passwordKey"`
+}
+
+// SecretDeploymentMode specifies how the secret is deployed to the targets.
+// +kubebuilder:validation:Enum=Manage;Contribute;CreateOnly
+type SecretDeploymentMode string
+
+const (
+	SecretDeploymentModeManage     SecretDeploymentMode = "Manage"
+	SecretDeploymentModeContribute SecretDeploymentMode = "Contribute"
+	SecretDeploymentModeCreateOnly SecretDeploymentMode = "CreateOnly"
+)
+
 type SecretLink struct {
 	// ServiceAccounts lists the service accounts that the secret is linked to.
 	ServiceAccount ServiceAccountLink `json:"serviceAccount,omitempty"`
@@ -187,5 +503,6 @@ type RemoteSecretErrorReason string
 
 const (
 	RemoteSecretErrorReasonTokenRetrieval RemoteSecretErrorReason = "TokenRetrieval"
+	RemoteSecretErrorReasonCircuitOpen    RemoteSecretErrorReason = "CircuitOpen"
 	RemoteSecretErrorReasonNoError        RemoteSecretErrorReason = ""
 )
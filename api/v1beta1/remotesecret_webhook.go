@@ -0,0 +1,46 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+//+kubebuilder:webhook:path=/mutate-appstudio-redhat-com-v1beta1-remotesecret,mutating=true,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=remotesecrets,verbs=create,versions=v1beta1,name=mremotesecret.appstudio.redhat.com,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = (*RemoteSecret)(nil)
+
+// SetupWebhookWithManager registers the defaulting webhook of the RemoteSecret with the manager.
+func (r *RemoteSecret) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).For(r).Complete(); err != nil {
+		return fmt.Errorf("failed to set up the defaulting webhook of the RemoteSecret: %w", err)
+	}
+	return nil
+}
+
+// Default names the secret deployed to the targets after the remote secret itself, unless the name of the secret is configured
+// in some way. This is more predictable than the name generated from the default prefix.
+func (r *RemoteSecret) Default() {
+	secret := &r.Spec.Secret
+	if r.Name == "" || secret.Name != "" || secret.GenerateName != "" || secret.GenerateNamePrefix != "" {
+		return
+	}
+	secret.Name = r.Name
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefault(t *testing.T) {
+	t.Run("defaults the secret name", func(t *testing.T) {
+		rs := &RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs"}}
+		rs.Default()
+		assert.Equal(t, "rs", rs.Spec.Secret.Name)
+	})
+
+	t.Run("keeps the configured names", func(t *testing.T) {
+		for _, spec := range []LinkableSecretSpec{{Name: "secret"}, {GenerateName: "secret-"}, {GenerateNamePrefix: "secret-"}} {
+			rs := &RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs"}, Spec: RemoteSecretSpec{Secret: spec}}
+			rs.Default()
+			assert.Equal(t, spec, rs.Spec.Secret)
+		}
+	})
+
+	t.Run("ignores the generated names of the remote secret", func(t *testing.T) {
+		rs := &RemoteSecret{ObjectMeta: metav1.ObjectMeta{GenerateName: "rs-"}}
+		rs.Default()
+		assert.Empty(t, rs.Spec.Secret.Name)
+	})
+}
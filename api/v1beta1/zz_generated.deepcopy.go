@@ -26,6 +26,146 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRemoteSecret) DeepCopyInto(out *ClusterRemoteSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRemoteSecret.
+func (in *ClusterRemoteSecret) DeepCopy() *ClusterRemoteSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRemoteSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRemoteSecret) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRemoteSecretList) DeepCopyInto(out *ClusterRemoteSecretList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRemoteSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRemoteSecretList.
+func (in *ClusterRemoteSecretList) DeepCopy() *ClusterRemoteSecretList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRemoteSecretList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRemoteSecretList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRemoteSecretSpec) DeepCopyInto(out *ClusterRemoteSecretSpec) {
+	*out = *in
+	in.RemoteSecretSpec.DeepCopyInto(&out.RemoteSecretSpec)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRemoteSecretSpec.
+func (in *ClusterRemoteSecretSpec) DeepCopy() *ClusterRemoteSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRemoteSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompanionConfigMap) DeepCopyInto(out *CompanionConfigMap) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompanionConfigMap.
+func (in *CompanionConfigMap) DeepCopy() *CompanionConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(CompanionConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataFrom) DeepCopyInto(out *DataFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataFrom.
+func (in *DataFrom) DeepCopy() *DataFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(DataFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyFilter) DeepCopyInto(out *KeyFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyFilter.
+func (in *KeyFilter) DeepCopy() *KeyFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LinkableSecretSpec) DeepCopyInto(out *LinkableSecretSpec) {
 	*out = *in
@@ -43,6 +183,28 @@ func (in *LinkableSecretSpec) DeepCopyInto(out *LinkableSecretSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Immutable != nil {
+		in, out := &in.Immutable, &out.Immutable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OptionalKeyNames != nil {
+		in, out := &in.OptionalKeyNames, &out.OptionalKeyNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]RegistryCredentials, len(*in))
+		copy(*out, *in)
+	}
 	if in.LinkedTo != nil {
 		in, out := &in.LinkedTo, &out.LinkedTo
 		*out = make([]SecretLink, len(*in))
@@ -50,6 +212,11 @@ func (in *LinkableSecretSpec) DeepCopyInto(out *LinkableSecretSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(CompanionConfigMap)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkableSecretSpec.
@@ -62,6 +229,21 @@ func (in *LinkableSecretSpec) DeepCopy() *LinkableSecretSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedSecretReference) DeepCopyInto(out *ManagedSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedSecretReference.
+func (in *ManagedSecretReference) DeepCopy() *ManagedSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedServiceAccountSpec) DeepCopyInto(out *ManagedServiceAccountSpec) {
 	*out = *in
@@ -91,6 +273,21 @@ func (in *ManagedServiceAccountSpec) DeepCopy() *ManagedServiceAccountSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryCredentials) DeepCopyInto(out *RegistryCredentials) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCredentials.
+func (in *RegistryCredentials) DeepCopy() *RegistryCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteSecret) DeepCopyInto(out *RemoteSecret) {
 	*out = *in
@@ -157,8 +354,24 @@ func (in *RemoteSecretSpec) DeepCopyInto(out *RemoteSecretSpec) {
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
 		*out = make([]RemoteSecretTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DataFrom != nil {
+		in, out := &in.DataFrom, &out.DataFrom
+		*out = new(DataFrom)
+		**out = **in
+	}
+	if in.DataSources != nil {
+		in, out := &in.DataSources, &out.DataSources
+		*out = make([]DataFrom, len(*in))
 		copy(*out, *in)
 	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteSecretSpec.
@@ -188,6 +401,20 @@ func (in *RemoteSecretStatus) DeepCopyInto(out *RemoteSecretStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastDataObtainedTime != nil {
+		in, out := &in.LastDataObtainedTime, &out.LastDataObtainedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DataKeys != nil {
+		in, out := &in.DataKeys, &out.DataKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedSecrets != nil {
+		in, out := &in.ManagedSecrets, &out.ManagedSecrets
+		*out = make([]ManagedSecretReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteSecretStatus.
@@ -203,6 +430,16 @@ func (in *RemoteSecretStatus) DeepCopy() *RemoteSecretStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteSecretTarget) DeepCopyInto(out *RemoteSecretTarget) {
 	*out = *in
+	if in.KeyFilter != nil {
+		in, out := &in.KeyFilter, &out.KeyFilter
+		*out = new(KeyFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Immutable != nil {
+		in, out := &in.Immutable, &out.Immutable
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteSecretTarget.
@@ -256,6 +493,10 @@ func (in *TargetStatus) DeepCopyInto(out *TargetStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DataSyncTime != nil {
+		in, out := &in.DataSyncTime, &out.DataSyncTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetStatus.
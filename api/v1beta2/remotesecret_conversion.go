@@ -0,0 +1,78 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = (*RemoteSecret)(nil)
+
+// ConvertTo converts this RemoteSecret to the hub version (v1beta1).
+func (src *RemoteSecret) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.RemoteSecret)
+	if !ok {
+		return fmt.Errorf("unexpected conversion target %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertThroughJSON(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("failed to convert the spec to v1beta1: %w", err)
+	}
+	if err := convertThroughJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("failed to convert the status to v1beta1: %w", err)
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the hub version (v1beta1) to this RemoteSecret.
+func (dst *RemoteSecret) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.RemoteSecret)
+	if !ok {
+		return fmt.Errorf("unexpected conversion source %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertThroughJSON(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("failed to convert the spec from v1beta1: %w", err)
+	}
+	if err := convertThroughJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("failed to convert the status from v1beta1: %w", err)
+	}
+
+	return nil
+}
+
+// convertThroughJSON copies the src into dst using their JSON representation. This is enough while v1beta2 mirrors v1beta1.
+// Once the versions diverge, the differing fields need to be converted explicitly after calling this.
+func convertThroughJSON(src any, dst any) error {
+	bytes, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the object: %w", err)
+	}
+
+	if err = json.Unmarshal(bytes, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal the object: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"testing"
+
+	"github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRemoteSecretConversion(t *testing.T) {
+	hub := &v1beta1.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			Labels:    map[string]string{"a": "b"},
+		},
+		Spec: v1beta1.RemoteSecretSpec{
+			Secret: v1beta1.LinkableSecretSpec{
+				Name: "secret",
+				Type: corev1.SecretTypeBasicAuth,
+				LinkedTo: []v1beta1.SecretLink{
+					{ServiceAccount: v1beta1.ServiceAccountLink{Reference: corev1.LocalObjectReference{Name: "sa"}}},
+				},
+			},
+			Targets: []v1beta1.RemoteSecretTarget{{Namespace: "ns", Wave: 1}},
+			DataFrom: &v1beta1.DataFrom{
+				Kind: v1beta1.DataFromKindConfigMap,
+				Name: "cm",
+			},
+		},
+		Status: v1beta1.RemoteSecretStatus{
+			Conditions: []metav1.Condition{{Type: "DataObtained", Status: metav1.ConditionTrue, Reason: "DataFound"}},
+			Targets:    []v1beta1.TargetStatus{{Namespace: "ns", SecretName: "secret"}},
+		},
+	}
+
+	rs := &RemoteSecret{}
+	assert.NoError(t, rs.ConvertFrom(hub))
+	assert.Equal(t, hub.ObjectMeta, rs.ObjectMeta)
+	assert.Equal(t, "sa", rs.Spec.Secret.LinkedTo[0].ServiceAccount.Reference.Name)
+	assert.Equal(t, 1, rs.Spec.Targets[0].Wave)
+	assert.Equal(t, DataFromKindConfigMap, rs.Spec.DataFrom.Kind)
+	assert.Equal(t, "secret", rs.Status.Targets[0].SecretName)
+
+	back := &v1beta1.RemoteSecret{}
+	assert.NoError(t, rs.ConvertTo(back))
+	assert.Equal(t, hub, back)
+}
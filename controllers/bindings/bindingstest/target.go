@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !release
+
+package bindingstest
+
+import (
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// DeploymentTarget is a SecretDeploymentTarget that is fully described by its fields and that is by default backed
+// by a fake Kubernetes client. It is meant to be used in the tests of the consumers of the bindings package so that
+// they don't have to reimplement the deployment target each time.
+//
+// Unlike the real deployment targets, it doesn't have any "status" that would be updated by the controllers. Use
+// the Record method to remember the names of the objects that have been deployed by the DependentsHandler.
+type DeploymentTarget struct {
+	// Client is the client used to deploy the dependent objects. NewDeploymentTarget initializes this with a fake client.
+	Client client.Client
+	// Key is the key of the object that "owns" the deployment target (e.g. the remote secret).
+	Key client.ObjectKey
+	// Namespace is the namespace to deploy the secret and the service accounts to.
+	Namespace string
+	// Spec is the spec of the secret and the service accounts.
+	Spec api.LinkableSecretSpec
+	// SecretName is the actual name of the secret as recorded by the last call to Record.
+	SecretName string
+	// ServiceAccountNames are the actual names of the service accounts as recorded by the last call to Record.
+	ServiceAccountNames []string
+	// ConfigMapName is the actual name of the companion config map as recorded by the last call to Record.
+	ConfigMapName string
+}
+
+var _ bindings.SecretDeploymentTarget = (*DeploymentTarget)(nil)
+
+// NewDeploymentTarget creates a new deployment target backed by a fake client initialized with the provided objects. If the scheme
+// is nil, a new scheme with the core Kubernetes types is used.
+func NewDeploymentTarget(scheme *runtime.Scheme, key client.ObjectKey, namespace string, spec api.LinkableSecretSpec, objects ...client.Object) *DeploymentTarget {
+	if scheme == nil {
+		scheme = runtime.NewScheme()
+		// this can only fail if there are conflicting types in the scheme which cannot happen with the fresh one.
+		_ = corev1.AddToScheme(scheme)
+	}
+
+	return &DeploymentTarget{
+		Client:              fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Key:                 key,
+		Namespace:           namespace,
+		Spec:                spec,
+		ServiceAccountNames: []string{},
+	}
+}
+
+// Record remembers the names of the secret, the service accounts and the config map from the provided dependents. This emulates what the
+// controllers do with the status of the objects after a successful sync.
+func (t *DeploymentTarget) Record(deps *bindings.Dependents) {
+	if deps == nil {
+		t.SecretName = ""
+		t.ServiceAccountNames = []string{}
+		t.ConfigMapName = ""
+		return
+	}
+
+	t.ConfigMapName = deps.ConfigMapName
+
+	if deps.Secret != nil {
+		t.SecretName = deps.Secret.Name
+	} else {
+		t.SecretName = ""
+	}
+
+	t.ServiceAccountNames = make([]string, len(deps.ServiceAccounts))
+	for i, sa := range deps.ServiceAccounts {
+		t.ServiceAccountNames[i] = sa.Name
+	}
+}
+
+// GetActualSecretName implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetActualSecretName() string {
+	return t.SecretName
+}
+
+// GetActualServiceAccountNames implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetActualServiceAccountNames() []string {
+	return t.ServiceAccountNames
+}
+
+// GetActualConfigMapName implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetActualConfigMapName() string {
+	return t.ConfigMapName
+}
+
+// GetClient implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetClient() client.Client {
+	return t.Client
+}
+
+// GetSpec implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetSpec() api.LinkableSecretSpec {
+	return t.Spec
+}
+
+// GetTargetNamespace implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetTargetNamespace() string {
+	return t.Namespace
+}
+
+// GetTargetObjectKey implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetTargetObjectKey() client.ObjectKey {
+	return t.Key
+}
+
+// GetType implements bindings.SecretDeploymentTarget
+func (t *DeploymentTarget) GetType() string {
+	return "Fake"
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingstest
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var rsKey = client.ObjectKey{Name: "rs", Namespace: "default"}
+
+func newHandler(target *DeploymentTarget, data map[string][]byte) *bindings.DependentsHandler[*api.RemoteSecret] {
+	return &bindings.DependentsHandler[*api.RemoteSecret]{
+		Target: target,
+		SecretDataGetter: &bindings.TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return data, "", nil
+			},
+		},
+		ObjectMarker: &namespacetarget.NamespaceObjectMarker{},
+	}
+}
+
+func TestDeploymentTarget(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		target := NewDeploymentTarget(nil, rsKey, "target-ns", api.LinkableSecretSpec{Name: "secret"})
+		h := newHandler(target, map[string][]byte{"a": []byte("b")})
+
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+
+		assert.Equal(t, "secret", target.GetActualSecretName())
+
+		s := &corev1.Secret{}
+		assert.NoError(t, target.Client.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "target-ns"}, s))
+		assert.Equal(t, []byte("b"), s.Data["a"])
+	})
+
+	t.Run("update", func(t *testing.T) {
+		target := NewDeploymentTarget(nil, rsKey, "target-ns", api.LinkableSecretSpec{Name: "secret"})
+
+		deps, _, err := newHandler(target, map[string][]byte{"a": []byte("b")}).Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+
+		deps, _, err = newHandler(target, map[string][]byte{"a": []byte("c")}).Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, target.Client.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "target-ns"}, s))
+		assert.Equal(t, []byte("c"), s.Data["a"])
+	})
+
+	t.Run("stale-detect", func(t *testing.T) {
+		target := NewDeploymentTarget(nil, rsKey, "target-ns", api.LinkableSecretSpec{Name: "secret"})
+		h := newHandler(target, map[string][]byte{"a": []byte("b")})
+
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+
+		cp, err := h.CheckPoint(context.TODO())
+		assert.NoError(t, err)
+
+		// rename the secret in the spec and "forget" the previously deployed one
+		target.Spec.Name = "renamed"
+		target.Record(nil)
+
+		deps, _, err = h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+		assert.Equal(t, "renamed", target.GetActualSecretName())
+
+		// the renamed secret is not part of the checkpoint, so it is detected as stale and deleted
+		assert.NoError(t, h.RevertTo(context.TODO(), cp))
+
+		err = target.Client.Get(context.TODO(), client.ObjectKey{Name: "renamed", Namespace: "target-ns"}, &corev1.Secret{})
+		assert.True(t, errors.IsNotFound(err))
+		assert.NoError(t, target.Client.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "target-ns"}, &corev1.Secret{}))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		unrelated := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "unrelated",
+				Namespace: "target-ns",
+			},
+		}
+		target := NewDeploymentTarget(nil, rsKey, "target-ns", api.LinkableSecretSpec{GenerateName: "secret-"}, unrelated)
+		h := newHandler(target, map[string][]byte{"a": []byte("b")})
+
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		target.Record(deps)
+		assert.NotEmpty(t, target.GetActualSecretName())
+
+		// Cleanup only deletes the secrets listed as managed by the target
+		assert.NoError(t, h.Cleanup(context.TODO()))
+
+		sl := &corev1.SecretList{}
+		assert.NoError(t, target.Client.List(context.TODO(), sl, client.InNamespace("target-ns")))
+		assert.Len(t, sl.Items, 1)
+		assert.Equal(t, "unrelated", sl.Items[0].Name)
+	})
+}
@@ -0,0 +1,161 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// contributionUpdateRetries is the number of times the coalesced contributions are re-applied to the re-read secret if its update
+// fails because of a conflict.
+const contributionUpdateRetries = 5
+
+// ContributionCoalescer batches the contributions of multiple targets to the same secret so that they are written in a single
+// update. The first contribution to a secret waits for the configured window during which the contributions of the other targets
+// to the same secret are collected. All the collected contributions are then applied to the secret read from the cluster and
+// written together. Each contribution is applied to its own copy of the secret, so the failure of one contribution (e.g. because
+// of a key conflict) doesn't affect the others.
+//
+// The nil coalescer is valid and means that the contributions are not coalesced.
+type ContributionCoalescer struct {
+	window time.Duration
+
+	lock    sync.Mutex
+	pending map[client.ObjectKey]*contributionBatch
+}
+
+type contributionBatch struct {
+	contributions []*contribution
+	done          chan struct{}
+	secret        *corev1.Secret
+	err           error
+}
+
+type contribution struct {
+	apply func(*corev1.Secret) (bool, error)
+	err   error
+}
+
+// NewContributionCoalescer creates a new coalescer collecting the contributions for the provided window. If the window is not
+// positive, nil is returned, i.e. the contributions are not coalesced.
+func NewContributionCoalescer(window time.Duration) *ContributionCoalescer {
+	if window <= 0 {
+		return nil
+	}
+	return &ContributionCoalescer{
+		window:  window,
+		pending: map[client.ObjectKey]*contributionBatch{},
+	}
+}
+
+// Contribute applies the contribution to the secret with the provided key using the provided function that modifies the secret
+// and returns true if it changed. The function may be called multiple times on different copies of the secret if the update
+// needs to be retried. This blocks until the batch the contribution is part of is written and returns the written secret.
+func (c *ContributionCoalescer) Contribute(ctx context.Context, cl client.Client, key client.ObjectKey, apply func(*corev1.Secret) (bool, error)) (*corev1.Secret, error) {
+	contrib := &contribution{apply: apply}
+
+	c.lock.Lock()
+	batch, ok := c.pending[key]
+	leader := !ok
+	if leader {
+		batch = &contributionBatch{done: make(chan struct{})}
+		c.pending[key] = batch
+	}
+	batch.contributions = append(batch.contributions, contrib)
+	c.lock.Unlock()
+
+	if leader {
+		timer := time.NewTimer(c.window)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+
+		c.lock.Lock()
+		delete(c.pending, key)
+		c.lock.Unlock()
+
+		// the batch is written even if the context of the leader is done, because the other contributors are waiting for it. The
+		// write fails fast in that case.
+		batch.write(ctx, cl, key)
+		close(batch.done)
+	} else {
+		select {
+		case <-batch.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("interrupted while waiting for the contributions to the secret %s to be written: %w", key.Name, ctx.Err())
+		}
+	}
+
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	if contrib.err != nil {
+		return nil, contrib.err
+	}
+	return batch.secret, nil
+}
+
+// write applies all the contributions in the batch to the secret read from the cluster and updates it if any of them changed it.
+func (b *contributionBatch) write(ctx context.Context, cl client.Client, key client.ObjectKey) {
+	contributionBatchSizeMetric.Observe(float64(len(b.contributions)))
+
+	attempts := 0
+	b.err = updateWithRetries(contributionUpdateRetries, ctx, cl, func() (client.Object, error) {
+		attempts++
+		if attempts > 1 {
+			contributionConflictsMetric.Inc()
+		}
+
+		secret, err := getContributionSecret(ctx, cl, key)
+		if err != nil {
+			// there is no point in retrying if we cannot even get the secret
+			return nil, backoff.Permanent(err) //nolint:wrapcheck // This is an "indication error" to the Backoff framework that is not exposed further.
+		}
+
+		changed := false
+		for _, c := range b.contributions {
+			updated := secret.DeepCopy()
+			var ch bool
+			if ch, c.err = c.apply(updated); c.err == nil {
+				secret = updated
+				changed = changed || ch
+			}
+		}
+
+		b.secret = secret
+		if !changed {
+			return nil, nil
+		}
+		return secret, nil
+	}, "retrying to write the coalesced contributions to the secret", fmt.Sprintf("failed to contribute the data to the secret %s", key.Name))
+
+	if b.err == nil {
+		return
+	}
+	b.secret = nil
+	if kerrors.IsConflict(b.err) {
+		contributionConflictsMetric.Inc()
+	}
+}
@@ -0,0 +1,159 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"context"
+	gosync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type updateCountingClient struct {
+	client.Client
+	updates atomic.Int32
+}
+
+func (c *updateCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates.Add(1)
+	return c.Client.Update(ctx, obj, opts...) //nolint:wrapcheck // this is just a test client
+}
+
+func TestContributionCoalescer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cl := &updateCountingClient{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"},
+				Data:       map[string][]byte{"theirs": []byte("value")},
+			}).
+			Build(),
+	}
+
+	newHandler := func(name string, coalescer *ContributionCoalescer, data map[string][]byte) *secretHandler[*api.RemoteSecret] {
+		return &secretHandler[*api.RemoteSecret]{
+			Target: &TestDeploymentTarget{
+				GetClientImpl:          func() client.Client { return cl },
+				GetTargetObjectKeyImpl: func() client.ObjectKey { return client.ObjectKey{Name: name, Namespace: "default"} },
+				GetTargetNamespaceImpl: func() string { return "default" },
+				GetSpecImpl: func() api.LinkableSecretSpec {
+					return api.LinkableSecretSpec{Name: "shared", DeploymentMode: api.SecretDeploymentModeContribute}
+				},
+			},
+			ObjectMarker: &TestObjectMarker{
+				MarkReferencedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+					labels := o.GetLabels()
+					if labels == nil {
+						labels = map[string]string{}
+					}
+					labels["referenced-"+name] = "true"
+					o.SetLabels(labels)
+					return true, nil
+				},
+			},
+			SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+				GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+					return data, "", nil
+				},
+			},
+			Coalescer: coalescer,
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, NewContributionCoalescer(0))
+	})
+
+	t.Run("batches contributions", func(t *testing.T) {
+		coalescer := NewContributionCoalescer(100 * time.Millisecond)
+		names := []string{"a", "b", "c", "d", "e"}
+		errs := make([]error, len(names))
+
+		wg := gosync.WaitGroup{}
+		for i, name := range names {
+			i, name := i, name
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h := newHandler(name, coalescer, map[string][]byte{name: []byte(name)})
+				_, _, errs[i] = h.contribute(context.TODO(), map[string][]byte{name: []byte(name)})
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), cl.updates.Load())
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "shared", Namespace: "default"}, s))
+		assert.Equal(t, []byte("value"), s.Data["theirs"])
+		for _, name := range names {
+			assert.Equal(t, []byte(name), s.Data[name])
+			assert.Equal(t, "true", s.Labels["referenced-"+name])
+		}
+	})
+
+	t.Run("failed contribution doesn't affect the others", func(t *testing.T) {
+		cl.updates.Store(0)
+		coalescer := NewContributionCoalescer(100 * time.Millisecond)
+
+		var okErr, conflictErr error
+		wg := gosync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, okErr = newHandler("f", coalescer, nil).contribute(context.TODO(), map[string][]byte{"f": []byte("f")})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, conflictErr = newHandler("g", coalescer, nil).contribute(context.TODO(), map[string][]byte{"theirs": []byte("mine")})
+		}()
+		wg.Wait()
+
+		assert.NoError(t, okErr)
+		assert.ErrorIs(t, conflictErr, contributionKeyConflictError)
+		assert.Equal(t, int32(1), cl.updates.Load())
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "shared", Namespace: "default"}, s))
+		assert.Equal(t, []byte("value"), s.Data["theirs"])
+		assert.Equal(t, []byte("f"), s.Data["f"])
+		assert.NotContains(t, s.Labels, "referenced-g")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		coalescer := NewContributionCoalescer(time.Millisecond)
+		h := newHandler("h", coalescer, nil)
+		h.Target.(*TestDeploymentTarget).GetSpecImpl = func() api.LinkableSecretSpec {
+			return api.LinkableSecretSpec{Name: "missing", DeploymentMode: api.SecretDeploymentModeContribute}
+		}
+		_, _, err := h.contribute(context.TODO(), map[string][]byte{"h": []byte("h")})
+		assert.ErrorIs(t, err, contributionSecretNotFoundError)
+	})
+}
@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// configMapHandler deploys the companion config map of the secret.
+type configMapHandler struct {
+	Target       SecretDeploymentTarget
+	ObjectMarker ObjectMarker
+}
+
+// splitCompanionData moves the keys of the companion config map from the provided data to the returned config map data. The returned
+// config map data is never nil so that it can be distinguished from the data that has not been computed at all.
+func splitCompanionData(spec *api.LinkableSecretSpec, data map[string][]byte) (map[string][]byte, map[string][]byte) {
+	companion := map[string][]byte{}
+	if spec.ConfigMap == nil {
+		return data, companion
+	}
+
+	ret := make(map[string][]byte, len(data))
+	for k, v := range data {
+		ret[k] = v
+	}
+
+	for _, k := range spec.ConfigMap.Keys {
+		if v, ok := ret[k]; ok {
+			companion[k] = v
+			delete(ret, k)
+		}
+	}
+
+	return ret, companion
+}
+
+// Sync creates or updates the companion config map configured in the spec so that it contains the provided data. Nil is returned if
+// there is no companion config map configured. The config map that already exists and is not managed by the target is only taken over
+// if the spec allows the adoption of the existing objects.
+func (h *configMapHandler) Sync(ctx context.Context, data map[string][]byte) (*corev1.ConfigMap, string, error) {
+	spec := h.Target.GetSpec()
+	if spec.ConfigMap == nil {
+		return nil, "", nil
+	}
+
+	desired := &corev1.ConfigMap{}
+	setConfigMapData(desired, data)
+
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Name: spec.ConfigMap.Name, Namespace: h.Target.GetTargetNamespace()}
+	if err := h.Target.GetClient().Get(ctx, key, cm); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to get the config map %s in the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+		}
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+			},
+			Data:       desired.Data,
+			BinaryData: desired.BinaryData,
+		}
+		if _, err := h.ObjectMarker.MarkManaged(ctx, h.Target.GetTargetObjectKey(), cm); err != nil {
+			return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to mark the config map %s as managed in the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+		}
+		if err := h.Target.GetClient().Create(ctx, cm); err != nil {
+			return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to create the config map %s in the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+		}
+		return cm, "", nil
+	}
+
+	managed, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), cm)
+	if err != nil {
+		return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to determine if the config map %s is managed by the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+	}
+	if !managed && !spec.AdoptExisting {
+		return nil, string(ErrorReasonConfigMapConflict), fmt.Errorf("%w: %s", ConfigMapConflictError, key.Name)
+	}
+
+	marked, err := h.ObjectMarker.MarkManaged(ctx, h.Target.GetTargetObjectKey(), cm)
+	if err != nil {
+		return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to mark the config map %s as managed in the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+	}
+
+	if !marked && equalConfigMapData(cm, desired) {
+		return cm, "", nil
+	}
+
+	cm.Data = desired.Data
+	cm.BinaryData = desired.BinaryData
+	if err := h.Target.GetClient().Update(ctx, cm); err != nil {
+		return nil, string(ErrorReasonConfigMapUpdate), fmt.Errorf("failed to update the config map %s in the deployment target (%s): %w", key.Name, h.Target.GetType(), err)
+	}
+
+	return cm, "", nil
+}
+
+// DeleteStale deletes the managed config maps in the target namespace other than the one with the provided name. This is used to
+// remove the config map that is no longer configured in the spec or that has been renamed.
+func (h *configMapHandler) DeleteStale(ctx context.Context, keep string) error {
+	cms, err := h.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, cm := range cms {
+		if cm.Name == keep {
+			continue
+		}
+		log.FromContext(ctx).V(logs.DebugLevel).Info("deleting the stale companion config map", "configMap", client.ObjectKeyFromObject(cm))
+		if err := h.Target.GetClient().Delete(ctx, cm); err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the stale config map %s in the deployment target (%s): %w", cm.Name, h.Target.GetType(), err)
+		}
+	}
+
+	return nil
+}
+
+// List lists the config maps in the target namespace that are managed by the target.
+func (h *configMapHandler) List(ctx context.Context) ([]*corev1.ConfigMap, error) {
+	opts, err := h.ObjectMarker.ListManagedOptions(ctx, h.Target.GetTargetObjectKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to formulate the options to list the config maps in the deployment target (%s): %w", h.Target.GetType(), err)
+	}
+
+	opts = append(opts, client.InNamespace(h.Target.GetTargetNamespace()))
+
+	cml := &corev1.ConfigMapList{}
+	if err := h.Target.GetClient().List(ctx, cml, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list the config maps associated with the deployment target (%s) %+v: %w", h.Target.GetType(), h.Target.GetTargetObjectKey(), err)
+	}
+
+	ret := []*corev1.ConfigMap{}
+	for i := range cml.Items {
+		if ok, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), &cml.Items[i]); err != nil {
+			return nil, fmt.Errorf("failed to determine if the config map %s is managed while processing the deployment target (%s) %s: %w",
+				client.ObjectKeyFromObject(&cml.Items[i]),
+				h.Target.GetType(),
+				h.Target.GetTargetObjectKey(),
+				err)
+		} else if ok {
+			ret = append(ret, &cml.Items[i])
+		}
+	}
+
+	return ret, nil
+}
+
+// setConfigMapData puts the provided data into the config map. The values that are valid UTF-8 strings are put into the data,
+// the rest into the binary data of the config map.
+func setConfigMapData(cm *corev1.ConfigMap, data map[string][]byte) {
+	cm.Data = nil
+	cm.BinaryData = nil
+	for k, v := range data {
+		if utf8.Valid(v) {
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			cm.Data[k] = string(v)
+		} else {
+			if cm.BinaryData == nil {
+				cm.BinaryData = map[string][]byte{}
+			}
+			cm.BinaryData[k] = v
+		}
+	}
+}
+
+func equalConfigMapData(a *corev1.ConfigMap, b *corev1.ConfigMap) bool {
+	if len(a.Data) != len(b.Data) || len(a.BinaryData) != len(b.BinaryData) {
+		return false
+	}
+	for k, v := range a.Data {
+		if bv, ok := b.Data[k]; !ok || bv != v {
+			return false
+		}
+	}
+	for k, v := range a.BinaryData {
+		if bv, ok := b.BinaryData[k]; !ok || !bytes.Equal(bv, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSplitCompanionData(t *testing.T) {
+	data := map[string][]byte{"ca.crt": []byte("ca"), "key": []byte("key")}
+
+	secretData, cmData := splitCompanionData(&api.LinkableSecretSpec{}, data)
+	assert.Equal(t, data, secretData)
+	assert.NotNil(t, cmData)
+	assert.Empty(t, cmData)
+
+	secretData, cmData = splitCompanionData(&api.LinkableSecretSpec{
+		ConfigMap: &api.CompanionConfigMap{Name: "cm", Keys: []string{"ca.crt", "missing"}},
+	}, data)
+	assert.Equal(t, map[string][]byte{"key": []byte("key")}, secretData)
+	assert.Equal(t, map[string][]byte{"ca.crt": []byte("ca")}, cmData)
+	// the original data is not modified
+	assert.Len(t, data, 2)
+}
+
+func TestDependentsSyncConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	marker := &TestObjectMarker{
+		IsManagedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+			return o.GetLabels()["managed"] == "obj", nil
+		},
+		MarkManagedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+			if o.GetLabels()["managed"] == "obj" {
+				return false, nil
+			}
+			labels := o.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels["managed"] = "obj"
+			o.SetLabels(labels)
+			return true, nil
+		},
+	}
+
+	newHandler := func(cl client.Client, spec api.LinkableSecretSpec, actualConfigMap string) DependentsHandler[*api.RemoteSecret] {
+		return DependentsHandler[*api.RemoteSecret]{
+			Target: &TestDeploymentTarget{
+				GetClientImpl:              func() client.Client { return cl },
+				GetTargetNamespaceImpl:     func() string { return "default" },
+				GetSpecImpl:                func() api.LinkableSecretSpec { return spec },
+				GetActualConfigMapNameImpl: func() string { return actualConfigMap },
+			},
+			SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+				GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+					return map[string][]byte{"ca.crt": []byte("ca"), "key": []byte("key"), "bin": {0xff}}, "", nil
+				},
+			},
+			ObjectMarker: marker,
+		}
+	}
+
+	spec := api.LinkableSecretSpec{
+		Name:      "secret",
+		ConfigMap: &api.CompanionConfigMap{Name: "cm", Keys: []string{"ca.crt", "bin"}},
+	}
+
+	t.Run("creates both", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		h := newHandler(cl, spec, "")
+
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, "cm", deps.ConfigMapName)
+		assert.Equal(t, map[string][]byte{"key": []byte("key")}, deps.Secret.Data)
+
+		cm := &corev1.ConfigMap{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "cm", Namespace: "default"}, cm))
+		assert.Equal(t, map[string]string{"ca.crt": "ca"}, cm.Data)
+		assert.Equal(t, map[string][]byte{"bin": {0xff}}, cm.BinaryData)
+		assert.Equal(t, "obj", cm.Labels["managed"])
+	})
+
+	t.Run("refuses unmanaged config map", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+			Data:       map[string]string{"theirs": "value"},
+		}).Build()
+		h := newHandler(cl, spec, "")
+
+		_, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.ErrorIs(t, err, ConfigMapConflictError)
+		assert.Equal(t, string(ErrorReasonConfigMapConflict), reason)
+	})
+
+	t.Run("deletes the stale config map", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default", Labels: map[string]string{"managed": "obj"}},
+		}).Build()
+		h := newHandler(cl, spec, "old")
+
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, "cm", deps.ConfigMapName)
+
+		err = cl.Get(context.TODO(), client.ObjectKey{Name: "old", Namespace: "default"}, &corev1.ConfigMap{})
+		assert.True(t, errors.IsNotFound(err))
+
+		// the config map is removed with the target
+		assert.NoError(t, h.Cleanup(context.TODO()))
+		err = cl.Get(context.TODO(), client.ObjectKey{Name: "cm", Namespace: "default"}, &corev1.ConfigMap{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("reverts the new config map", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		h := newHandler(cl, spec, "")
+
+		cp, err := h.CheckPoint(context.TODO())
+		assert.NoError(t, err)
+		_, _, err = h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, h.RevertTo(context.TODO(), cp))
+		err = cl.Get(context.TODO(), client.ObjectKey{Name: "cm", Namespace: "default"}, &corev1.ConfigMap{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
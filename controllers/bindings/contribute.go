@@ -0,0 +1,285 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ContributedKeysAnnotation is the annotation on the secrets that the data is contributed to (as opposed to the secrets that
+// are fully managed). It holds a JSON object mapping the keys of the contributing targets to the lists of the keys
+// in the secret data that they contributed.
+const ContributedKeysAnnotation = "appstudio.redhat.com/contributed-keys"
+
+var (
+	contributionSecretNameMissingError = errors.New("the name of the existing secret to contribute the data to must be specified")
+	contributionSecretNotFoundError    = errors.New("the secret to contribute the data to does not exist")
+	contributionKeyConflictError       = errors.New("the secret already contains a key that was not contributed by the target")
+)
+
+// contribute writes the provided data into the existing secret configured in the target, leaving the rest of the keys in the secret
+// intact. The secret is only marked as referenced by the target, not managed, so that it is not deleted during the cleanup.
+// The keys that are no longer in the data but were contributed previously are removed from the secret. If the Coalescer is
+// configured, the contribution is written together with the contributions of the other targets to the same secret.
+func (h *secretHandler[K]) contribute(ctx context.Context, data map[string][]byte) (*corev1.Secret, string, error) {
+	secretName := h.Target.GetActualSecretName()
+	if secretName == "" {
+		secretName = h.Target.GetSpec().Name
+	}
+	if secretName == "" {
+		return nil, string(ErrorReasonSecretUpdate), contributionSecretNameMissingError
+	}
+
+	secretKey := client.ObjectKey{Name: secretName, Namespace: h.Target.GetTargetNamespace()}
+	apply := func(secret *corev1.Secret) (bool, error) {
+		return h.applyContribution(ctx, secret, data)
+	}
+
+	if h.Coalescer != nil {
+		secret, err := h.Coalescer.Contribute(ctx, h.Target.GetClient(), secretKey, apply)
+		if err != nil {
+			return nil, contributionErrorReason(err), err
+		}
+		return secret, "", nil
+	}
+
+	secret, err := getContributionSecret(ctx, h.Target.GetClient(), secretKey)
+	if err != nil {
+		return nil, string(ErrorReasonSecretUpdate), err
+	}
+
+	changed, err := apply(secret)
+	if err != nil {
+		return nil, contributionErrorReason(err), err
+	}
+
+	if changed {
+		if err := h.Target.GetClient().Update(ctx, secret); err != nil {
+			if kerrors.IsConflict(err) {
+				contributionConflictsMetric.Inc()
+			}
+			return nil, string(ErrorReasonSecretUpdate), fmt.Errorf("failed to contribute the data to the secret %s: %w", secretName, err)
+		}
+	}
+
+	return secret, "", nil
+}
+
+// applyContribution modifies the provided secret so that it contains the provided data contributed by the target and returns true
+// if the secret changed.
+func (h *secretHandler[K]) applyContribution(ctx context.Context, secret *corev1.Secret, data map[string][]byte) (bool, error) {
+	contributions, err := getContributedKeys(secret)
+	if err != nil {
+		return false, err
+	}
+
+	targetKey := h.Target.GetTargetObjectKey().String()
+	previous := map[string]bool{}
+	for _, k := range contributions[targetKey] {
+		previous[k] = true
+	}
+
+	for k := range data {
+		if _, exists := secret.Data[k]; exists && !previous[k] {
+			return false, fmt.Errorf("%w: %s", contributionKeyConflictError, k)
+		}
+	}
+
+	changed := false
+	for k := range previous {
+		if _, ok := data[k]; !ok {
+			delete(secret.Data, k)
+			changed = true
+		}
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	keys := make([]string, 0, len(data))
+	for k, v := range data {
+		keys = append(keys, k)
+		if current, ok := secret.Data[k]; !ok || !bytes.Equal(current, v) {
+			secret.Data[k] = v
+			changed = true
+		}
+	}
+	sort.Strings(keys)
+
+	// the contributions of the other targets count towards the size of the secret, too
+	if err := checkSecretDataSize(secret.Data); err != nil {
+		return false, err
+	}
+
+	if !equalKeys(contributions[targetKey], keys) {
+		contributions[targetKey] = keys
+		if err := setContributedKeys(secret, contributions); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	marked, err := h.ObjectMarker.MarkReferenced(ctx, h.Target.GetTargetObjectKey(), secret)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark the secret as referenced in the deployment target (%s): %w", h.Target.GetType(), err)
+	}
+
+	return changed || marked, nil
+}
+
+// contributionErrorReason returns the error reason of the failed contribution.
+func contributionErrorReason(err error) string {
+	if errors.Is(err, SecretTooLargeError) {
+		return string(ErrorReasonSecretTooLarge)
+	}
+	return string(ErrorReasonSecretUpdate)
+}
+
+// getContributionSecret gets the existing secret the data is contributed to.
+func getContributionSecret(ctx context.Context, cl client.Client, key client.ObjectKey) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := cl.Get(ctx, key, secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", contributionSecretNotFoundError, key.Name)
+		}
+		return nil, fmt.Errorf("failed to get the secret %s to contribute the data to: %w", key.Name, err)
+	}
+	return secret, nil
+}
+
+// listContributed lists the secrets in the target namespace that the target contributed the data to.
+func (h *secretHandler[K]) listContributed(ctx context.Context) ([]*corev1.Secret, error) {
+	opts, err := h.ObjectMarker.ListReferencedOptions(ctx, h.Target.GetTargetObjectKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to formulate the options to list the referenced secrets in the deployment target (%s): %w", h.Target.GetType(), err)
+	}
+
+	opts = append(opts, client.InNamespace(h.Target.GetTargetNamespace()))
+
+	sl := &corev1.SecretList{}
+	if err := h.Target.GetClient().List(ctx, sl, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list the secrets referenced by the deployment target (%s) %+v: %w", h.Target.GetType(), h.Target.GetTargetObjectKey(), err)
+	}
+
+	targetKey := h.Target.GetTargetObjectKey().String()
+	ret := []*corev1.Secret{}
+	for i := range sl.Items {
+		s := &sl.Items[i]
+		if managed, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), s); err != nil {
+			return nil, fmt.Errorf("failed to determine if the secret %s is managed while processing the deployment target (%s) %s: %w",
+				client.ObjectKeyFromObject(s),
+				h.Target.GetType(),
+				h.Target.GetTargetObjectKey(),
+				err)
+		} else if managed {
+			continue
+		}
+
+		contributions, err := getContributedKeys(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := contributions[targetKey]; ok {
+			ret = append(ret, s)
+		}
+	}
+
+	return ret, nil
+}
+
+// withdraw removes the keys contributed by the target from the secret and returns true if the secret was changed. If removeData
+// is false, only the record of the contribution is removed, leaving the data in the secret.
+func (h *secretHandler[K]) withdraw(secret *corev1.Secret, removeData bool) (bool, error) {
+	contributions, err := getContributedKeys(secret)
+	if err != nil {
+		return false, err
+	}
+
+	targetKey := h.Target.GetTargetObjectKey().String()
+	keys, ok := contributions[targetKey]
+	if !ok {
+		return false, nil
+	}
+
+	if removeData {
+		for _, k := range keys {
+			delete(secret.Data, k)
+		}
+	}
+
+	delete(contributions, targetKey)
+	if err := setContributedKeys(secret, contributions); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func getContributedKeys(obj client.Object) (map[string][]string, error) {
+	contributions := map[string][]string{}
+	val := obj.GetAnnotations()[ContributedKeysAnnotation]
+	if val == "" {
+		return contributions, nil
+	}
+
+	if err := json.Unmarshal([]byte(val), &contributions); err != nil {
+		return nil, fmt.Errorf("failed to parse the contributed keys annotation on the object %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	return contributions, nil
+}
+
+func setContributedKeys(obj client.Object, contributions map[string][]string) error {
+	annos := obj.GetAnnotations()
+	if len(contributions) == 0 {
+		delete(annos, ContributedKeysAnnotation)
+		return nil
+	}
+
+	val, err := json.Marshal(contributions)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the contributed keys of the object %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	if annos == nil {
+		annos = map[string]string{}
+	}
+	annos[ContributedKeysAnnotation] = string(val)
+	obj.SetAnnotations(annos)
+
+	return nil
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
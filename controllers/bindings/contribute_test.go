@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestContribute(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "shared",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"theirs": []byte("value"),
+			},
+		}).
+		Build()
+
+	data := map[string][]byte{"a": []byte("a"), "b": []byte("b")}
+
+	h := DependentsHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetClientImpl: func() client.Client {
+				return cl
+			},
+			GetTargetObjectKeyImpl: func() client.ObjectKey {
+				return client.ObjectKey{Name: "rs", Namespace: "default"}
+			},
+			GetTargetNamespaceImpl: func() string {
+				return "default"
+			},
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{
+					Name:           "shared",
+					DeploymentMode: api.SecretDeploymentModeContribute,
+				}
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return data, "", nil
+			},
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsReferencedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				return o.GetLabels()["referenced"] == "true", nil
+			},
+			MarkReferencedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				if o.GetLabels()["referenced"] == "true" {
+					return false, nil
+				}
+				o.SetLabels(map[string]string{"referenced": "true"})
+				return true, nil
+			},
+			UnmarkReferencedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				if o.GetLabels()["referenced"] != "true" {
+					return false, nil
+				}
+				delete(o.GetLabels(), "referenced")
+				return true, nil
+			},
+		},
+	}
+
+	getShared := func(t *testing.T) *corev1.Secret {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "shared", Namespace: "default"}, s))
+		return s
+	}
+
+	t.Run("contributes keys", func(t *testing.T) {
+		deps, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, "shared", deps.Secret.Name)
+
+		s := getShared(t)
+		assert.Equal(t, []byte("value"), s.Data["theirs"])
+		assert.Equal(t, []byte("a"), s.Data["a"])
+		assert.Equal(t, []byte("b"), s.Data["b"])
+		assert.Equal(t, "true", s.Labels["referenced"])
+		assert.Equal(t, `{"default/rs":["a","b"]}`, s.Annotations[ContributedKeysAnnotation])
+	})
+
+	t.Run("removes no longer contributed keys", func(t *testing.T) {
+		data = map[string][]byte{"a": []byte("changed")}
+
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+
+		s := getShared(t)
+		assert.Equal(t, []byte("value"), s.Data["theirs"])
+		assert.Equal(t, []byte("changed"), s.Data["a"])
+		assert.NotContains(t, s.Data, "b")
+	})
+
+	t.Run("refuses to overwrite other keys", func(t *testing.T) {
+		data = map[string][]byte{"theirs": []byte("ours")}
+
+		_, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.ErrorIs(t, err, contributionKeyConflictError)
+		assert.Equal(t, string(ErrorReasonSecretUpdate), reason)
+
+		assert.Equal(t, []byte("value"), getShared(t).Data["theirs"])
+		data = map[string][]byte{"a": []byte("changed")}
+	})
+
+	t.Run("cleanup removes only the contributed keys", func(t *testing.T) {
+		assert.NoError(t, h.Cleanup(context.TODO()))
+
+		s := getShared(t)
+		assert.Equal(t, map[string][]byte{"theirs": []byte("value")}, s.Data)
+		assert.NotContains(t, s.Annotations, ContributedKeysAnnotation)
+		assert.NotContains(t, s.Labels, "referenced")
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -29,6 +30,26 @@ type DependentsHandler[K any] struct {
 	Target           SecretDeploymentTarget
 	SecretDataGetter SecretDataGetter[K]
 	ObjectMarker     ObjectMarker
+	// SecretListPageSize enables the server-side pagination when listing the secrets in the target namespace if set to a positive
+	// number. This is only useful with non-caching clients (e.g. the ones used for the remote clusters), because the list calls
+	// against the cache don't support continuation.
+	SecretListPageSize int64
+	// ForceSecretUpdate makes Sync update the secret in the target even if it seems to be up-to-date.
+	ForceSecretUpdate bool
+	// SpecChanged signals that the spec of the object has changed since the targets were last deployed to so that the changed
+	// labels and annotations of the secret are applied even if its data stays the same.
+	SpecChanged bool
+	// RecordedDataHash is the hash of the data last written to the target. If it is the same as the hash of the data to write
+	// and the secret in the target still contains that data, the secret is not written to at all.
+	RecordedDataHash string
+	// RecordedSecretUID is the UID of the secret last observed in the target. If the secret in the target has a different UID,
+	// it has been re-created outside of the controller and Sync restores its markers and data even if they seem up-to-date.
+	RecordedSecretUID types.UID
+	// ServerSideApply makes Sync write the secret using the server-side apply instead of updating it as a whole.
+	ServerSideApply bool
+	// ContributionCoalescer optionally batches the contributions of the targets to the same secret into fewer updates. It must only
+	// be shared by the handlers with the clients of the same cluster.
+	ContributionCoalescer *ContributionCoalescer
 }
 
 // Dependents represent the secret and the list of the service accounts that are
@@ -36,6 +57,10 @@ type DependentsHandler[K any] struct {
 type Dependents struct {
 	Secret          *corev1.Secret
 	ServiceAccounts []*corev1.ServiceAccount
+	// DataHash is the hash of the data written to the secret. See DataHash function.
+	DataHash string
+	// ConfigMapName is the name of the companion config map deployed together with the secret, if any.
+	ConfigMapName string
 }
 
 type serviceAccountLink struct {
@@ -52,6 +77,7 @@ type serviceAccountNamesAndLinkTypes map[string]serviceAccountLink
 type CheckPoint struct {
 	secretName          string
 	serviceAccountNames serviceAccountNamesAndLinkTypes
+	configMapName       string
 }
 
 // CheckPoint creates an instance of CheckPoint struct that captures the secret name and the list of known service account
@@ -72,6 +98,7 @@ func (d *DependentsHandler[K]) CheckPoint(ctx context.Context) (CheckPoint, erro
 	return CheckPoint{
 		secretName:          secretName,
 		serviceAccountNames: names,
+		configMapName:       d.Target.GetActualConfigMapName(),
 	}, nil
 }
 
@@ -108,6 +135,7 @@ func (d *DependentsHandler[K]) Sync(ctx context.Context, dataKey K) (*Dependents
 	// Third, the service account needs to be updated with the link to the secret.
 
 	secretsHandler, saHandler := d.childHandlers()
+	cmHandler := d.configMapHandler()
 
 	serviceAccounts, errorReason, err := saHandler.Sync(ctx)
 	if err != nil {
@@ -119,13 +147,36 @@ func (d *DependentsHandler[K]) Sync(ctx context.Context, dataKey K) (*Dependents
 		return nil, errorReason, err
 	}
 
+	// the companion config map is left intact if the data of the secret has not been computed, e.g. because the secret is only
+	// created once.
+	configMapName := d.Target.GetActualConfigMapName()
+	if secretsHandler.companionData != nil {
+		cm, errorReason, err := cmHandler.Sync(ctx, secretsHandler.companionData)
+		if err != nil {
+			return nil, errorReason, err
+		}
+		configMapName = ""
+		if cm != nil {
+			configMapName = cm.Name
+		}
+	}
+
 	if err = saHandler.LinkToSecret(ctx, serviceAccounts, sec); err != nil {
 		return nil, errorReason, err
 	}
 
+	// the config map is only deleted after everything else succeeded, because the deletion cannot be reverted.
+	if previous := d.Target.GetActualConfigMapName(); previous != "" && previous != configMapName {
+		if err = cmHandler.DeleteStale(ctx, configMapName); err != nil {
+			return nil, string(ErrorReasonConfigMapUpdate), err
+		}
+	}
+
 	deps := &Dependents{
 		Secret:          sec,
 		ServiceAccounts: serviceAccounts,
+		DataHash:        secretsHandler.dataHash,
+		ConfigMapName:   configMapName,
 	}
 
 	return deps, "", nil
@@ -150,6 +201,31 @@ func (d *DependentsHandler[K]) Cleanup(ctx context.Context) error {
 			err)
 	}
 
+	// the listing relies on the labels, so let's make sure we don't miss the secret the target knows about.
+	known, err := secretsHandler.findExistingManaged(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find the secret to clean for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+	if known != nil && !containsObject(sl, known) {
+		sl = append(sl, known)
+	}
+
+	csl, err := secretsHandler.listContributed(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the secrets with the contributed data to clean for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	// the service accounts need to be unlinked from both the managed secrets and the secrets we contributed the data to.
+	linkedSecrets := make([]*corev1.Secret, 0, len(sl)+len(csl))
+	linkedSecrets = append(linkedSecrets, sl...)
+	linkedSecrets = append(linkedSecrets, csl...)
+
 	for _, sa := range sal {
 		if managed, err := d.ObjectMarker.IsManagedBy(ctx, d.Target.GetTargetObjectKey(), sa); err != nil {
 			return fmt.Errorf("failed to determine if the service account (%s) is managed while processing the secret deployment target (%s) %s: %w",
@@ -167,10 +243,19 @@ func (d *DependentsHandler[K]) Cleanup(ctx context.Context) error {
 			}
 		} else {
 			persist := false
-			for _, s := range sl {
+			for _, s := range linkedSecrets {
 				// Unlink must go first, because Go only has lazy bool eval
 				persist = saHandler.Unlink(s, sa) || persist
 			}
+			unmarked, err := d.ObjectMarker.UnmarkReferenced(ctx, d.Target.GetTargetObjectKey(), sa)
+			if err != nil {
+				return fmt.Errorf("failed to unmark the service account %s as referenced while cleaning up dependent objects of the secret deployment target (%s) %s: %w",
+					client.ObjectKeyFromObject(sa),
+					d.Target.GetType(),
+					d.Target.GetTargetObjectKey(),
+					err)
+			}
+			persist = unmarked || persist
 			if persist {
 				if err := d.Target.GetClient().Update(ctx, sa); err != nil {
 					return fmt.Errorf("failed to remove the linked secrets from the service account %s while cleaning up dependent objects of the secret deployment target (%s) %s: %w",
@@ -193,6 +278,140 @@ func (d *DependentsHandler[K]) Cleanup(ctx context.Context) error {
 		}
 	}
 
+	for _, s := range csl {
+		if err := d.withdrawContribution(ctx, secretsHandler, s, true); err != nil {
+			return err
+		}
+	}
+
+	if err := d.configMapHandler().DeleteStale(ctx, ""); err != nil {
+		return fmt.Errorf("failed to delete the config maps while cleaning up dependent objects of secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	return nil
+}
+
+// withdrawContribution removes the record of the contribution of the target (and optionally also the contributed keys) from the secret
+// and persists it.
+func (d *DependentsHandler[K]) withdrawContribution(ctx context.Context, secretsHandler *secretHandler[K], s *corev1.Secret, removeData bool) error {
+	withdrawn, err := secretsHandler.withdraw(s, removeData)
+	if err != nil {
+		return fmt.Errorf("failed to withdraw the contributed data from the secret %s of the secret deployment target (%s) %s: %w",
+			client.ObjectKeyFromObject(s),
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	unmarked, err := d.ObjectMarker.UnmarkReferenced(ctx, d.Target.GetTargetObjectKey(), s)
+	if err != nil {
+		return fmt.Errorf("failed to unmark the secret %s as referenced by the secret deployment target (%s) %s: %w",
+			client.ObjectKeyFromObject(s),
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	if withdrawn || unmarked {
+		if err := d.Target.GetClient().Update(ctx, s); err != nil {
+			return fmt.Errorf("failed to update the secret %s after withdrawing the contributed data of the secret deployment target (%s) %s: %w",
+				client.ObjectKeyFromObject(s),
+				d.Target.GetType(),
+				d.Target.GetTargetObjectKey(),
+				err)
+		}
+	}
+
+	return nil
+}
+
+// Orphan leaves the dependent objects of the target in the cluster but removes all the markings linking them to the target. After
+// this, the secret and the service accounts are no longer considered managed or referenced by the target and are left to be managed
+// by whoever else takes over them. The links between the service accounts and the secret are kept intact.
+func (d *DependentsHandler[K]) Orphan(ctx context.Context) error {
+	secretsHandler, saHandler := d.childHandlers()
+
+	sal, err := saHandler.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the service accounts to orphan for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	sl, err := secretsHandler.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the secrets to orphan for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	csl, err := secretsHandler.listContributed(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the secrets with the contributed data to orphan for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	// the contributed data is left in the secret, only the record of the contribution is removed.
+	for _, s := range csl {
+		if err := d.withdrawContribution(ctx, secretsHandler, s, false); err != nil {
+			return err
+		}
+	}
+
+	cml, err := d.configMapHandler().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the config maps to orphan for the secret deployment target (%s) %s: %w",
+			d.Target.GetType(),
+			d.Target.GetTargetObjectKey(),
+			err)
+	}
+
+	objs := make([]client.Object, 0, len(sal)+len(sl)+len(cml))
+	for _, sa := range sal {
+		objs = append(objs, sa)
+	}
+	for _, s := range sl {
+		objs = append(objs, s)
+	}
+	for _, cm := range cml {
+		objs = append(objs, cm)
+	}
+
+	for _, o := range objs {
+		unmanaged, err := d.ObjectMarker.UnmarkManaged(ctx, d.Target.GetTargetObjectKey(), o)
+		if err != nil {
+			return fmt.Errorf("failed to unmark the object %s as managed while orphaning the dependent objects of the secret deployment target (%s) %s: %w",
+				client.ObjectKeyFromObject(o),
+				d.Target.GetType(),
+				d.Target.GetTargetObjectKey(),
+				err)
+		}
+		unreferenced, err := d.ObjectMarker.UnmarkReferenced(ctx, d.Target.GetTargetObjectKey(), o)
+		if err != nil {
+			return fmt.Errorf("failed to unmark the object %s as referenced while orphaning the dependent objects of the secret deployment target (%s) %s: %w",
+				client.ObjectKeyFromObject(o),
+				d.Target.GetType(),
+				d.Target.GetTargetObjectKey(),
+				err)
+		}
+		if unmanaged || unreferenced {
+			if err := d.Target.GetClient().Update(ctx, o); err != nil {
+				return fmt.Errorf("failed to update the orphaned object %s of the secret deployment target (%s) %s: %w",
+					client.ObjectKeyFromObject(o),
+					d.Target.GetType(),
+					d.Target.GetTargetObjectKey(),
+					err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -216,6 +435,14 @@ func (d *DependentsHandler[K]) RevertTo(ctx context.Context, checkPoint CheckPoi
 		}
 	}
 
+	// the companion config map configured since the checkpoint is deleted, too, so that the secret and the config map are
+	// deployed together or not at all.
+	if cm := d.Target.GetSpec().ConfigMap; cm != nil && cm.Name != checkPoint.configMapName {
+		if err := d.configMapHandler().DeleteStale(ctx, checkPoint.configMapName); err != nil {
+			return err
+		}
+	}
+
 	sal, err := serviceAccountHandler.List(ctx)
 	if err != nil {
 		return err
@@ -303,9 +530,16 @@ func (d *DependentsHandler[K]) RevertTo(ctx context.Context, checkPoint CheckPoi
 // childHandlers is a utility function instantiating the auxilliary handlers for secrets and service accounts.
 func (d *DependentsHandler[K]) childHandlers() (*secretHandler[K], *serviceAccountHandler) {
 	secretsHandler := &secretHandler[K]{
-		Target:           d.Target,
-		ObjectMarker:     d.ObjectMarker,
-		SecretDataGetter: d.SecretDataGetter,
+		Target:            d.Target,
+		ObjectMarker:      d.ObjectMarker,
+		SecretDataGetter:  d.SecretDataGetter,
+		ListPageSize:      d.SecretListPageSize,
+		ForceUpdate:       d.ForceSecretUpdate,
+		SpecChanged:       d.SpecChanged,
+		RecordedDataHash:  d.RecordedDataHash,
+		RecordedSecretUID: d.RecordedSecretUID,
+		ServerSideApply:   d.ServerSideApply,
+		Coalescer:         d.ContributionCoalescer,
 	}
 
 	saHandler := &serviceAccountHandler{
@@ -315,3 +549,22 @@ func (d *DependentsHandler[K]) childHandlers() (*secretHandler[K], *serviceAccou
 
 	return secretsHandler, saHandler
 }
+
+// configMapHandler instantiates the handler of the companion config map.
+func (d *DependentsHandler[K]) configMapHandler() *configMapHandler {
+	return &configMapHandler{
+		Target:       d.Target,
+		ObjectMarker: d.ObjectMarker,
+	}
+}
+
+// containsObject returns true if the provided list contains an object with the same key as the provided object.
+func containsObject[T client.Object](list []T, obj client.Object) bool {
+	key := client.ObjectKeyFromObject(obj)
+	for _, o := range list {
+		if client.ObjectKeyFromObject(o) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -136,6 +136,142 @@ func TestDependentsCleanup(t *testing.T) {
 	})
 }
 
+func TestDependentsCleanupKnownSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret",
+					Namespace: "default",
+					Labels: map[string]string{
+						"managed": "obj",
+					},
+				},
+			},
+		).
+		Build()
+
+	h := DependentsHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetClientImpl: func() client.Client {
+				return cl
+			},
+			GetTargetNamespaceImpl: func() string {
+				return "default"
+			},
+			GetActualSecretNameImpl: func() string {
+				return "secret"
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{},
+		ObjectMarker: &TestObjectMarker{
+			// simulate the listing not finding the secret
+			ListManagedOptionsImpl: func(ctx context.Context, _ client.ObjectKey) ([]client.ListOption, error) {
+				return []client.ListOption{client.MatchingLabels{"nonexistent": "label"}}, nil
+			},
+			IsManagedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				return o.GetLabels()["managed"] == "obj", nil
+			},
+		},
+	}
+
+	assert.NoError(t, h.Cleanup(context.TODO()))
+
+	err := cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "default"}, &corev1.Secret{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDependentsOrphan(t *testing.T) {
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret",
+					Namespace: "default",
+					Labels: map[string]string{
+						"managed": "obj",
+					},
+				},
+			},
+			&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "sa-managed",
+					Namespace: "default",
+					Labels: map[string]string{
+						"managed": "obj",
+					},
+					Annotations: map[string]string{
+						"linked": "obj",
+					},
+				},
+				Secrets: []corev1.ObjectReference{
+					{
+						Name: "secret",
+					},
+				},
+			},
+		).
+		Build()
+
+	h := DependentsHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetClientImpl: func() client.Client {
+				return cl
+			},
+			GetTargetNamespaceImpl: func() string {
+				return "default"
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				return o.GetLabels()["managed"] == "obj", nil
+			},
+			IsReferencedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				return o.GetAnnotations()["linked"] == "obj", nil
+			},
+			UnmarkManagedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				if o.GetLabels()["managed"] != "obj" {
+					return false, nil
+				}
+				delete(o.GetLabels(), "managed")
+				return true, nil
+			},
+			UnmarkReferencedImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				if o.GetAnnotations()["linked"] != "obj" {
+					return false, nil
+				}
+				delete(o.GetAnnotations(), "linked")
+				return true, nil
+			},
+		},
+	}
+
+	assert.NoError(t, h.Orphan(context.TODO()))
+
+	t.Run("keeps and unmarks SAs", func(t *testing.T) {
+		sa := &corev1.ServiceAccount{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "sa-managed", Namespace: "default"}, sa))
+		assert.NotContains(t, sa.Labels, "managed")
+		assert.NotContains(t, sa.Annotations, "linked")
+		assert.Len(t, sa.Secrets, 1)
+	})
+
+	t.Run("keeps and unmarks secrets", func(t *testing.T) {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "default"}, s))
+		assert.NotContains(t, s.Labels, "managed")
+	})
+}
+
 func TestDependentsRevertTo(t *testing.T) {
 	scheme := runtime.NewScheme()
 	assert.NoError(t, corev1.AddToScheme(scheme))
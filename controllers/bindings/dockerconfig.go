@@ -0,0 +1,186 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dockerConfigJson is the structure of the data in the kubernetes.io/dockerconfigjson secrets. The legacy kubernetes.io/dockercfg
+// secrets contain just the contents of the Auths field.
+type dockerConfigJson struct {
+	Auths map[string]json.RawMessage `json:"auths"`
+}
+
+// ensureDockerConfigData tries to make the data of the docker config secret types contain the key required by the type. If the
+// required key is missing but the data contains the docker config in the other format (i.e. .dockerconfigjson for kubernetes.io/dockercfg
+// secrets and .dockercfg for kubernetes.io/dockerconfigjson secrets), the config is converted into the required format. Otherwise
+// the data is returned unchanged.
+func ensureDockerConfigData(secretType corev1.SecretType, data map[string][]byte) (map[string][]byte, error) {
+	var requiredKey, otherKey string
+	var convert func([]byte) ([]byte, error)
+
+	switch secretType {
+	case corev1.SecretTypeDockercfg:
+		requiredKey, otherKey, convert = corev1.DockerConfigKey, corev1.DockerConfigJsonKey, dockerConfigJsonToDockercfg
+	case corev1.SecretTypeDockerConfigJson:
+		requiredKey, otherKey, convert = corev1.DockerConfigJsonKey, corev1.DockerConfigKey, dockercfgToDockerConfigJson
+	default:
+		return data, nil
+	}
+
+	if _, ok := data[requiredKey]; ok {
+		return data, nil
+	}
+
+	other, ok := data[otherKey]
+	if !ok {
+		// nothing to convert. The missing key is reported by the caller.
+		return data, nil
+	}
+
+	converted, err := convert(other)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the %s key to %s: %w", otherKey, requiredKey, err)
+	}
+
+	ret := make(map[string][]byte, len(data))
+	for k, v := range data {
+		if k != otherKey {
+			ret[k] = v
+		}
+	}
+	ret[requiredKey] = converted
+
+	return ret, nil
+}
+
+// dockerAuth is the entry of the auths of the docker config.
+type dockerAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// aggregateRegistryCredentials returns a copy of the data with the key required by the docker config secret type containing the
+// auths of all the provided registries. The auths already present under that key in the data are kept unless they are for one of the
+// registries. The keys with the user names and passwords are left in the data.
+func aggregateRegistryCredentials(secretType corev1.SecretType, registries []api.RegistryCredentials, data map[string][]byte) (map[string][]byte, error) {
+	cfg := dockerConfigJson{}
+	var key string
+	var existing any
+
+	switch secretType {
+	case corev1.SecretTypeDockerConfigJson:
+		key, existing = corev1.DockerConfigJsonKey, &cfg
+	case corev1.SecretTypeDockercfg:
+		key, existing = corev1.DockerConfigKey, &cfg.Auths
+	default:
+		return nil, fmt.Errorf("%w: the registries can only be used with the %s and %s secrets", InvalidRegistryCredentialsError, corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg)
+	}
+
+	if value, ok := data[key]; ok {
+		if err := json.Unmarshal(value, existing); err != nil {
+			return nil, fmt.Errorf("failed to parse the %s key: %w", key, err)
+		}
+	}
+	if cfg.Auths == nil {
+		cfg.Auths = map[string]json.RawMessage{}
+	}
+
+	for i, r := range registries {
+		var problems []string
+		if r.Registry == "" {
+			problems = append(problems, "the registry is empty")
+		}
+		username := data[r.UsernameKey]
+		if len(username) == 0 {
+			problems = append(problems, fmt.Sprintf("no user name in the key '%s'", r.UsernameKey))
+		}
+		password := data[r.PasswordKey]
+		if len(password) == 0 {
+			problems = append(problems, fmt.Sprintf("no password in the key '%s'", r.PasswordKey))
+		}
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("%w: registry at the index %d: %s", InvalidRegistryCredentialsError, i, strings.Join(problems, ", "))
+		}
+
+		auth, err := json.Marshal(dockerAuth{
+			Username: string(username),
+			Password: string(password),
+			Auth:     base64.StdEncoding.EncodeToString([]byte(string(username) + ":" + string(password))),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize the auth of the registry %s: %w", r.Registry, err)
+		}
+		cfg.Auths[r.Registry] = auth
+	}
+
+	// the map keys are serialized in the sorted order, so the result is stable
+	var aggregated []byte
+	var err error
+	if key == corev1.DockerConfigJsonKey {
+		aggregated, err = json.Marshal(cfg)
+	} else {
+		aggregated, err = json.Marshal(cfg.Auths)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the %s key: %w", key, err)
+	}
+
+	ret := make(map[string][]byte, len(data)+1)
+	for k, v := range data {
+		ret[k] = v
+	}
+	ret[key] = aggregated
+
+	return ret, nil
+}
+
+func dockerConfigJsonToDockercfg(data []byte) ([]byte, error) {
+	cfg := dockerConfigJson{}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the docker config json: %w", err)
+	}
+
+	if cfg.Auths == nil {
+		cfg.Auths = map[string]json.RawMessage{}
+	}
+
+	ret, err := json.Marshal(cfg.Auths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the docker config: %w", err)
+	}
+	return ret, nil
+}
+
+func dockercfgToDockerConfigJson(data []byte) ([]byte, error) {
+	cfg := dockerConfigJson{}
+	if err := json.Unmarshal(data, &cfg.Auths); err != nil {
+		return nil, fmt.Errorf("failed to parse the docker config: %w", err)
+	}
+
+	ret, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the docker config json: %w", err)
+	}
+	return ret, nil
+}
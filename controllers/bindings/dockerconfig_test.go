@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEnsureDockerConfigData(t *testing.T) {
+	t.Run("other types untouched", func(t *testing.T) {
+		data := map[string][]byte{"a": []byte("b")}
+		ret, err := ensureDockerConfigData(corev1.SecretTypeOpaque, data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, ret)
+	})
+
+	t.Run("dockercfg with required key", func(t *testing.T) {
+		data := map[string][]byte{corev1.DockerConfigKey: []byte(`{"quay.io":{"auth":"a"}}`)}
+		ret, err := ensureDockerConfigData(corev1.SecretTypeDockercfg, data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, ret)
+	})
+
+	t.Run("dockercfg converted from dockerconfigjson", func(t *testing.T) {
+		data := map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"a"}}}`),
+			"extra":                    []byte("value"),
+		}
+		ret, err := ensureDockerConfigData(corev1.SecretTypeDockercfg, data)
+		assert.NoError(t, err)
+		assert.Len(t, ret, 2)
+		assert.JSONEq(t, `{"quay.io":{"auth":"a"}}`, string(ret[corev1.DockerConfigKey]))
+		assert.Equal(t, []byte("value"), ret["extra"])
+	})
+
+	t.Run("dockerconfigjson converted from dockercfg", func(t *testing.T) {
+		data := map[string][]byte{corev1.DockerConfigKey: []byte(`{"quay.io":{"auth":"a"}}`)}
+		ret, err := ensureDockerConfigData(corev1.SecretTypeDockerConfigJson, data)
+		assert.NoError(t, err)
+		assert.Len(t, ret, 1)
+		assert.JSONEq(t, `{"auths":{"quay.io":{"auth":"a"}}}`, string(ret[corev1.DockerConfigJsonKey]))
+	})
+
+	t.Run("dockercfg missing required key", func(t *testing.T) {
+		data := map[string][]byte{"a": []byte("b")}
+		ret, err := ensureDockerConfigData(corev1.SecretTypeDockercfg, data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, ret)
+		assert.Equal(t, []string{corev1.DockerConfigKey}, missingRequiredKeys(corev1.SecretTypeDockercfg, ret))
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := ensureDockerConfigData(corev1.SecretTypeDockercfg, map[string][]byte{corev1.DockerConfigJsonKey: []byte("not json")})
+		assert.Error(t, err)
+	})
+}
+
+func TestAggregateRegistryCredentials(t *testing.T) {
+	registries := []api.RegistryCredentials{
+		{Registry: "quay.io", UsernameKey: "quay-user", PasswordKey: "quay-password"},
+		{Registry: "registry.redhat.io", UsernameKey: "rh-user", PasswordKey: "rh-token"},
+	}
+	data := map[string][]byte{
+		"quay-user":     []byte("a"),
+		"quay-password": []byte("b"),
+		"rh-user":       []byte("c"),
+		"rh-token":      []byte("d"),
+	}
+
+	t.Run("dockerconfigjson", func(t *testing.T) {
+		withExisting := map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"docker.io":{"auth":"x"},"quay.io":{"auth":"old"}}}`)}
+		for k, v := range data {
+			withExisting[k] = v
+		}
+
+		ret, err := aggregateRegistryCredentials(corev1.SecretTypeDockerConfigJson, registries, withExisting)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("a"), ret["quay-user"])
+		assert.JSONEq(t, `{"auths":{
+			"docker.io":{"auth":"x"},
+			"quay.io":{"username":"a","password":"b","auth":"YTpi"},
+			"registry.redhat.io":{"username":"c","password":"d","auth":"Yzpk"}
+		}}`, string(ret[corev1.DockerConfigJsonKey]))
+		assert.Empty(t, missingRequiredKeys(corev1.SecretTypeDockerConfigJson, ret))
+	})
+
+	t.Run("dockercfg", func(t *testing.T) {
+		ret, err := aggregateRegistryCredentials(corev1.SecretTypeDockercfg, registries[:1], data)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"quay.io":{"username":"a","password":"b","auth":"YTpi"}}`, string(ret[corev1.DockerConfigKey]))
+	})
+
+	t.Run("stable output", func(t *testing.T) {
+		first, err := aggregateRegistryCredentials(corev1.SecretTypeDockerConfigJson, registries, data)
+		assert.NoError(t, err)
+		second, err := aggregateRegistryCredentials(corev1.SecretTypeDockerConfigJson, []api.RegistryCredentials{registries[1], registries[0]}, data)
+		assert.NoError(t, err)
+		assert.Equal(t, first[corev1.DockerConfigJsonKey], second[corev1.DockerConfigJsonKey])
+	})
+
+	t.Run("incomplete credentials", func(t *testing.T) {
+		_, err := aggregateRegistryCredentials(corev1.SecretTypeDockerConfigJson, []api.RegistryCredentials{
+			{Registry: "quay.io", UsernameKey: "quay-user", PasswordKey: "missing"},
+		}, data)
+		assert.ErrorIs(t, err, InvalidRegistryCredentialsError)
+		assert.Contains(t, err.Error(), "missing")
+	})
+
+	t.Run("wrong secret type", func(t *testing.T) {
+		_, err := aggregateRegistryCredentials(corev1.SecretTypeOpaque, registries, data)
+		assert.ErrorIs(t, err, InvalidRegistryCredentialsError)
+	})
+}
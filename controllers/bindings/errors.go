@@ -14,7 +14,13 @@
 
 package bindings
 
-import "errors"
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
 
 type ErrorReason string
 
@@ -31,8 +37,119 @@ const (
 	// - api.SPIAccessTokenBindingErrorReasonServiceAccountUpdate in ensureReferencedServiceAccount -> serviceAccountHandler.Sync
 	// - api.SPIAccessTokenBindingErrorReasonTokenSync in ensureReferencedServiceAccount -> serviceAccountHandler.Sync
 	ErrorReasonServiceAccountUpdate ErrorReason = "ServiceAccountUpdate"
+	// ErrorReasonInvalidSecretData is used when the secret data is not compatible with the type of the secret.
+	ErrorReasonInvalidSecretData ErrorReason = "InvalidSecretData"
+	// ErrorReasonMissingRequiredKeys is used when the secret data was obtained but it lacks some keys required by the type of the secret.
+	ErrorReasonMissingRequiredKeys ErrorReason = "MissingRequiredKeys"
+	// ErrorReasonSecretConflict is used when a secret with the name of the secret to deploy already exists in the target and is not
+	// managed by the remote secret or cannot be adopted because it is controlled by another controller.
+	ErrorReasonSecretConflict ErrorReason = "SecretConflict"
+	// ErrorReasonConfigMapUpdate is used when the companion config map of the secret cannot be created or updated.
+	ErrorReasonConfigMapUpdate ErrorReason = "ConfigMapUpdate"
+	// ErrorReasonConfigMapConflict is used when the companion config map of the secret already exists in the target and is not
+	// managed by the remote secret.
+	ErrorReasonConfigMapConflict ErrorReason = "ConfigMapConflict"
+	// ErrorReasonInvalidKeyNames is used when the optional keys of the secret include the keys required by the type of the secret.
+	ErrorReasonInvalidKeyNames ErrorReason = "InvalidKeyNames"
+	// ErrorReasonInvalidTemplate is used when a template of the secret data cannot be parsed or rendered.
+	ErrorReasonInvalidTemplate ErrorReason = "InvalidTemplate"
+	// ErrorReasonTemplateKeyMissing is used when a template of the secret data references a key that is not in the data.
+	ErrorReasonTemplateKeyMissing ErrorReason = "TemplateKeyMissing"
+	// ErrorReasonKeyCollision is used when a key of the secret data is produced by more than one of the obtained data, the templates
+	// and the aggregation of the registry credentials.
+	ErrorReasonKeyCollision ErrorReason = "KeyCollision"
+	// ErrorReasonSecretTooLarge is used when the data of the secret exceeds the maximum size of the secrets in Kubernetes.
+	ErrorReasonSecretTooLarge ErrorReason = "SecretTooLarge"
+	// ErrorReasonInvalidRegistryCredentials is used when the registry credentials of the secret are incomplete or cannot be
+	// used with the type of the secret.
+	ErrorReasonInvalidRegistryCredentials ErrorReason = "InvalidRegistryCredentials"
+	// ErrorReasonInvalidSecretMetadata is used when the labels or annotations of the secret are not syntactically valid.
+	ErrorReasonInvalidSecretMetadata ErrorReason = "InvalidSecretMetadata"
+	// ErrorReasonTargetNotAllowed is used when the operator configuration doesn't allow the remote secret to deploy to the namespace
+	// of the target.
+	ErrorReasonTargetNotAllowed ErrorReason = "TargetNotAllowed"
+	// ErrorReasonRedundantApiUrl is used when the API URL of the target points at the cluster the operator runs in. Such targets
+	// must not specify the API URL at all.
+	ErrorReasonRedundantApiUrl ErrorReason = "RedundantApiUrl"
+	// ErrorReasonClusterUnreachable is used when the cluster of the target could not be connected to, e.g. because of a DNS, network
+	// or TLS failure.
+	ErrorReasonClusterUnreachable ErrorReason = "ClusterUnreachable"
+	// ErrorReasonUnauthorized is used when the cluster of the target refused the credentials used to connect to it.
+	ErrorReasonUnauthorized ErrorReason = "Unauthorized"
+	// ErrorReasonForbidden is used when the credentials used to connect to the cluster of the target lack the permissions to deploy the secret.
+	ErrorReasonForbidden ErrorReason = "Forbidden"
+	// ErrorReasonNamespaceNotFound is used when the namespace of the target doesn't exist.
+	ErrorReasonNamespaceNotFound ErrorReason = "NamespaceNotFound"
+	// ErrorReasonClusterCredentialsNotFound is used when the secret with the credentials to the cluster of the target doesn't exist.
+	ErrorReasonClusterCredentialsNotFound ErrorReason = "ClusterCredentialsNotFound"
+	// ErrorReasonInvalidClusterCredentials is used when the credentials to the cluster of the target are missing or cannot be used
+	// to construct the client of the cluster.
+	ErrorReasonInvalidClusterCredentials ErrorReason = "InvalidClusterCredentials"
+	// ErrorReasonClusterNotFound is used when the cluster referenced by the target cannot be found in the cluster registry.
+	ErrorReasonClusterNotFound ErrorReason = "ClusterNotFound"
+	// ErrorReasonSelfReferencingTarget is used when the secret in the target is the same secret the data is copied from.
+	ErrorReasonSelfReferencingTarget ErrorReason = "SelfReferencingTarget"
 )
 
 var (
-	SecretDataNotFoundError = errors.New("data not found")
+	SecretDataNotFoundError         = errors.New("data not found")
+	MissingRequiredKeysError        = errors.New("the secret data is missing keys required by the secret type")
+	InvalidSecretContentsError      = errors.New("the secret data cannot be parsed as required by the secret type")
+	OptionalKeysRequiredError       = errors.New("the optional keys include keys required by the secret type")
+	SecretConflictError             = errors.New("the secret already exists in the target and is not managed by the remote secret")
+	SecretControlledError           = errors.New("the secret already exists in the target and is controlled by another controller")
+	ConfigMapConflictError          = errors.New("the config map already exists in the target and is not managed by the remote secret")
+	InvalidTemplateError            = errors.New("failed to render the template")
+	TemplateKeyMissingError         = errors.New("the template references a key missing in the secret data")
+	KeyCollisionError               = errors.New("the key of the secret data is produced more than once")
+	SecretTooLargeError             = errors.New("the secret data exceeds the maximum size of a secret")
+	InvalidRegistryCredentialsError = errors.New("invalid registry credentials")
+	InvalidSecretMetadataError      = errors.New("invalid labels or annotations of the secret")
+	ClusterCredentialsNotFoundError = errors.New("the cluster credentials secret not found")
+	InvalidClusterCredentialsError  = errors.New("invalid cluster credentials")
+	ClusterNotFoundError            = errors.New("the cluster not found in the cluster registry")
 )
+
+// ClassifyTargetError classifies the errors caused by the cluster of the target into the error reasons. ErrorReasonNone is
+// returned if the error cannot be classified.
+func ClassifyTargetError(err error) ErrorReason {
+	if err == nil {
+		return ErrorReasonNone
+	}
+
+	switch {
+	case errors.Is(err, ClusterCredentialsNotFoundError):
+		return ErrorReasonClusterCredentialsNotFound
+	case errors.Is(err, InvalidClusterCredentialsError):
+		return ErrorReasonInvalidClusterCredentials
+	case errors.Is(err, ClusterNotFoundError):
+		return ErrorReasonClusterNotFound
+	case kerrors.IsUnauthorized(err):
+		return ErrorReasonUnauthorized
+	case kerrors.IsForbidden(err):
+		return ErrorReasonForbidden
+	case kerrors.IsNotFound(err) && isNamespaceNotFound(err):
+		return ErrorReasonNamespaceNotFound
+	}
+
+	var netErr net.Error
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certInvalidErr x509.CertificateInvalidError
+	if errors.As(err, &netErr) || errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &certInvalidErr) {
+		return ErrorReasonClusterUnreachable
+	}
+
+	return ErrorReasonNone
+}
+
+// isNamespaceNotFound checks whether the not found error is caused by the missing namespace rather than a missing object in it.
+func isNamespaceNotFound(err error) bool {
+	var statusErr kerrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	details := statusErr.Status().Details
+	return details != nil && details.Kind == "namespaces"
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClassifyTargetError(t *testing.T) {
+	unreachable := &url.Error{
+		Op:  "Get",
+		URL: "https://remote.cluster",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+	}
+
+	test := func(name string, err error, expected ErrorReason) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, ClassifyTargetError(fmt.Errorf("wrapped: %w", err)))
+		})
+	}
+
+	test("unreachable", unreachable, ErrorReasonClusterUnreachable)
+	test("unauthorized", kerrors.NewUnauthorized("nope"), ErrorReasonUnauthorized)
+	test("forbidden", kerrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "s", errors.New("nope")), ErrorReasonForbidden)
+	test("namespace not found", kerrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "ns"), ErrorReasonNamespaceNotFound)
+	test("object not found", kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "s"), ErrorReasonNone)
+	test("other", errors.New("other"), ErrorReasonNone)
+}
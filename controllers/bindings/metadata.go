@@ -0,0 +1,35 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"fmt"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateSecretMetadata checks that the labels and annotations of the secret spec conform to the Kubernetes syntax rules so that
+// the offending entries are reported before the cluster of the target rejects the secret with a less specific message.
+func validateSecretMetadata(spec *api.LinkableSecretSpec) error {
+	errs := metav1validation.ValidateLabels(spec.Labels, field.NewPath("labels"))
+	errs = append(errs, apivalidation.ValidateAnnotations(spec.Annotations, field.NewPath("annotations"))...)
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", InvalidSecretMetadataError, errs.ToAggregate().Error())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redhat-appstudio/remote-secret/pkg/config"
+)
+
+var contributionConflictsMetric = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: config.MetricsNamespace,
+	Subsystem: config.MetricsSubsystem,
+	Name:      "contribution_conflicts_total",
+	Help:      "The number of the conflicts encountered when updating the secrets the data is contributed to",
+})
+
+var contributionBatchSizeMetric = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: config.MetricsNamespace,
+	Subsystem: config.MetricsSubsystem,
+	Name:      "contribution_batch_size",
+	Help:      "The number of the contributions written to a secret in a single update when the contributions are coalesced",
+	Buckets:   []float64{1, 2, 5, 10, 20, 50, 100},
+})
+
+// RegisterMetrics registers the metrics of the deployment to the targets with the provided registerer.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, m := range []prometheus.Collector{contributionConflictsMetric, contributionBatchSizeMetric} {
+		if err := registerer.Register(m); err != nil {
+			if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+				return fmt.Errorf("failed to register the contribution metrics: %w", err)
+			}
+		}
+	}
+	return nil
+}
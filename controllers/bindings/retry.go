@@ -19,7 +19,9 @@ import (
 	"fmt"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	"github.com/redhat-appstudio/remote-secret/pkg/sync"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -66,3 +68,31 @@ func updateWithRetries(retries uint64, ctx context.Context, cl client.Client, ob
 
 	return nil
 }
+
+// syncWithRetries syncs the blueprint to the cluster using the provided syncer and retries the sync if it fails because of a conflict,
+// i.e. when the object has been modified in the cluster between reading it and updating it. The sync re-reads the object from
+// the cluster each time, so each retry applies the blueprint to the most recent state of the object.
+func syncWithRetries(retries uint64, ctx context.Context, syncer sync.Syncer, blueprint client.Object, diffOpts cmp.Option) (client.Object, error) {
+	debugLog := log.FromContext(ctx).V(logs.DebugLevel)
+
+	var ret client.Object
+	err := backoff.Retry(func() error {
+		// the syncer modifies the blueprint during the update, so we need to start from scratch each time
+		_, obj, err := syncer.Sync(ctx, nil, blueprint.DeepCopyObject().(client.Object), diffOpts)
+		if err != nil {
+			if errors.IsConflict(err) {
+				debugLog.Info("retrying the sync of the object due to a conflict", "obj", client.ObjectKeyFromObject(blueprint))
+				return err //nolint:wrapcheck // this will cause a retry or bubble up as a returned error from Retry...
+			}
+			return backoff.Permanent(err) //nolint:wrapcheck // This is an "indication error" to the Backoff framework that is not exposed further.
+		}
+		ret = obj
+		return nil
+	}, backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), retries), ctx))
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the callers wrap the error
+	}
+
+	return ret, nil
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/redhat-appstudio/remote-secret/pkg/sync"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -196,3 +197,51 @@ func (*FakeUpdateError) Error() string {
 
 var _ (apierrors.APIStatus) = (*FakeUpdateError)(nil)
 var _ (error) = (*FakeUpdateError)(nil)
+
+// conflictingClient fails the first Conflicts updates with a conflict error.
+type conflictingClient struct {
+	client.Client
+	Conflicts int
+	Updates   int
+}
+
+func (c *conflictingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.Updates++
+	if c.Updates <= c.Conflicts {
+		return &FakeUpdateError{Reason: metav1.StatusReasonConflict}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestSyncWithRetries(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s", Namespace: "default"},
+		Data:       map[string][]byte{"a": []byte("old")},
+	}
+
+	blueprint := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "s", Namespace: "default"},
+		Data:       map[string][]byte{"a": []byte("new")},
+	}
+
+	t.Run("conflict is retried", func(t *testing.T) {
+		cl := &conflictingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing.DeepCopy()).Build(), Conflicts: 1}
+
+		obj, err := syncWithRetries(3, context.TODO(), sync.New(cl), blueprint, secretDiffOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cl.Updates)
+		assert.Equal(t, []byte("new"), obj.(*corev1.Secret).Data["a"])
+	})
+
+	t.Run("gives up after retries", func(t *testing.T) {
+		cl := &conflictingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing.DeepCopy()).Build(), Conflicts: 10}
+
+		_, err := syncWithRetries(2, context.TODO(), sync.New(cl), blueprint, secretDiffOpts)
+		assert.True(t, apierrors.IsConflict(err))
+		assert.Equal(t, 3, cl.Updates)
+	})
+}
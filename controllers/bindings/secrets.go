@@ -15,19 +15,40 @@
 package bindings
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
 	"github.com/redhat-appstudio/remote-secret/pkg/logs"
 	"github.com/redhat-appstudio/remote-secret/pkg/sync"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// secretSyncRetryCount is the number of times the sync of the secret is retried when it fails because of a conflict with
+	// a concurrent writer.
+	secretSyncRetryCount = 3
+
+	// SecretFieldManager is the name of the field manager used when applying the secrets using the server-side apply.
+	SecretFieldManager = "remote-secret"
+
+	// MaxSecretDataSize is the maximum total size of the values in the secret data accepted by the Kubernetes API server.
+	MaxSecretDataSize = 1024 * 1024
+)
+
 var (
 	// pre-allocated empty map so that we don't have to allocate new empty instances in the serviceAccountSecretDiffOpts
 	emptySecretData = map[string][]byte{}
@@ -62,20 +83,159 @@ var (
 		}),
 		),
 	}
+
+	// forcedUpdateDiffOpts make the secrets always look different so that they always get updated.
+	forcedUpdateDiffOpts = cmp.Options{
+		cmp.Comparer(func(a *corev1.Secret, b *corev1.Secret) bool {
+			return false
+		}),
+	}
 )
 
 type secretHandler[K any] struct {
 	Target           SecretDeploymentTarget
 	ObjectMarker     ObjectMarker
 	SecretDataGetter SecretDataGetter[K]
+	// ListPageSize is the maximum number of secrets to request from the cluster in a single call when listing. If it is
+	// not positive, all the secrets are listed in a single call.
+	ListPageSize int64
+	// ForceUpdate makes Sync update the secret even if it doesn't differ from the desired state. This also restores
+	// the labels and annotations that are otherwise not considered when looking for the differences.
+	ForceUpdate bool
+	// SpecChanged signals that the spec of the object has changed since the secret was last written. The labels and annotations
+	// of the spec that are missing in the secret are then restored even if the data of the secret stays the same. Otherwise, the
+	// changes of the metadata done by others are left intact.
+	SpecChanged bool
+	// RecordedDataHash is the hash of the data last written to the target.
+	RecordedDataHash string
+	// RecordedSecretUID is the UID of the secret last observed in the target.
+	RecordedSecretUID types.UID
+	// ServerSideApply makes Sync write the secret using the server-side apply so that only the fields set by Sync are owned
+	// by it and the fields set by the other field managers are left intact.
+	ServerSideApply bool
+	// Coalescer optionally batches the contributions to the same secret. Only used in the Contribute deployment mode.
+	Coalescer *ContributionCoalescer
+
+	// dataHash is the hash of the data in the secret after a successful Sync.
+	dataHash string
+	// recreated is true if Sync found out that the secret has been re-created outside of the controller.
+	recreated bool
+	// companionData is the data of the companion config map split from the secret data during Sync. It is nil if Sync didn't
+	// compute the data, e.g. because the secret is only created once and already exists.
+	companionData map[string][]byte
 }
 
 func (h *secretHandler[K]) Sync(ctx context.Context, key K) (*corev1.Secret, string, error) {
+	if h.Target.GetSpec().DeploymentMode != api.SecretDeploymentModeContribute {
+		if errorReason, err := h.repairMarkers(ctx); err != nil {
+			return nil, errorReason, err
+		}
+	}
+
+	if h.Target.GetSpec().DeploymentMode == api.SecretDeploymentModeCreateOnly {
+		existing, err := h.findExistingManaged(ctx)
+		if err != nil {
+			return nil, string(ErrorReasonSecretUpdate), err
+		}
+		if existing != nil {
+			// the secret has already been created and is left to the users from now on.
+			h.dataHash = DataHash(existing.Data)
+			return existing, "", nil
+		}
+	}
+
+	if h.Target.GetSpec().DeploymentMode != api.SecretDeploymentModeContribute {
+		spec := h.Target.GetSpec()
+		if err := validateSecretMetadata(&spec); err != nil {
+			return nil, string(ErrorReasonInvalidSecretMetadata), err
+		}
+	}
+
 	data, errorReason, err := h.SecretDataGetter.GetData(ctx, key)
 	if err != nil {
 		return nil, errorReason, fmt.Errorf("failed to obtain the secret data: %w", err)
 	}
 
+	if len(h.Target.GetSpec().Templates) > 0 {
+		spec := h.Target.GetSpec()
+		if err = checkKeyCollisions(&spec, data); err != nil {
+			return nil, string(ErrorReasonKeyCollision), err
+		}
+		data, errorReason, err = renderTemplates(h.Target.GetSpec().Templates, data)
+		if err != nil {
+			return nil, errorReason, err
+		}
+	}
+
+	if len(h.Target.GetSpec().Registries) > 0 {
+		data, err = aggregateRegistryCredentials(h.Target.GetSpec().Type, h.Target.GetSpec().Registries, data)
+		if err != nil {
+			return nil, string(ErrorReasonInvalidRegistryCredentials), err
+		}
+	}
+
+	spec := h.Target.GetSpec()
+	data, h.companionData = splitCompanionData(&spec, data)
+
+	data, err = projectOptionalKeys(h.Target.GetSpec().Type, h.Target.GetSpec().OptionalKeyNames, h.Target.GetSpec().Templates, data)
+	if err != nil {
+		return nil, string(ErrorReasonInvalidKeyNames), err
+	}
+
+	// fail fast instead of sending the data that the API server would refuse anyway
+	if err = checkSecretDataSize(data); err != nil {
+		return nil, string(ErrorReasonSecretTooLarge), err
+	}
+
+	if h.Target.GetSpec().DeploymentMode == api.SecretDeploymentModeContribute {
+		// the type of the secret is given by the existing secret, so we don't check the data against it.
+		secret, errorReason, err := h.contribute(ctx, data)
+		if err == nil {
+			h.dataHash = DataHash(data)
+		}
+		return secret, errorReason, err
+	}
+
+	data, err = ensureDockerConfigData(h.Target.GetSpec().Type, data)
+	if err != nil {
+		return nil, string(ErrorReasonInvalidSecretData), fmt.Errorf("the secret data is not valid for the secret type: %w", err)
+	}
+
+	if missing := missingRequiredKeys(h.Target.GetSpec().Type, data); len(missing) > 0 {
+		return nil, string(ErrorReasonMissingRequiredKeys), fmt.Errorf("%w: %s", MissingRequiredKeysError, strings.Join(missing, ", "))
+	}
+
+	if h.Target.GetSpec().ValidateContents {
+		if err := validateContents(h.Target.GetSpec().Type, data); err != nil {
+			return nil, string(ErrorReasonInvalidSecretData), err
+		}
+	}
+
+	// the re-created secret might contain anything, so we restore it as a whole.
+	forceUpdate := h.ForceUpdate || h.recreated
+
+	dataHash := DataHash(data)
+	// the diff of the secrets ignores the metadata, so the labels and annotations changed in the spec need the update forced.
+	metadataChanged := false
+	if !forceUpdate && dataHash == h.RecordedDataHash {
+		existing, err := h.findExistingManaged(ctx)
+		if err != nil {
+			return nil, string(ErrorReasonSecretUpdate), err
+		}
+		secretType := h.Target.GetSpec().Type
+		if secretType == "" {
+			secretType = corev1.SecretTypeOpaque
+		}
+		// the change of the immutability needs the secret re-created even if the data stays the same.
+		sameImmutability := existing != nil && pointer.BoolDeref(existing.Immutable, false) == pointer.BoolDeref(h.Target.GetSpec().Immutable, false)
+		metadataChanged = h.SpecChanged && existing != nil && (!containsAll(existing.Labels, h.Target.GetSpec().Labels) || !containsAll(existing.Annotations, h.Target.GetSpec().Annotations))
+		if existing != nil && existing.Type == secretType && sameImmutability && !metadataChanged && DataHash(h.ownedData(existing.Data, data)) == dataHash {
+			// the data last written to the target is still in place, so there is nothing to write.
+			h.dataHash = dataHash
+			return existing, "", nil
+		}
+	}
+
 	secretName := h.Target.GetActualSecretName()
 	if secretName == "" {
 		secretName = h.Target.GetSpec().Name
@@ -87,6 +247,10 @@ func (h *secretHandler[K]) Sync(ctx context.Context, key K) (*corev1.Secret, str
 		diffOpts = serviceAccountSecretDiffOpts
 	}
 
+	if forceUpdate || metadataChanged {
+		diffOpts = forcedUpdateDiffOpts
+	}
+
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
@@ -99,8 +263,13 @@ func (h *secretHandler[K]) Sync(ctx context.Context, key K) (*corev1.Secret, str
 			Labels:       h.Target.GetSpec().Labels,
 			Annotations:  h.Target.GetSpec().Annotations,
 		},
-		Data: data,
-		Type: h.Target.GetSpec().Type,
+		Data:      data,
+		Type:      h.Target.GetSpec().Type,
+		Immutable: h.Target.GetSpec().Immutable,
+	}
+
+	if secret.GenerateName == "" {
+		secret.GenerateName = h.Target.GetSpec().GenerateNamePrefix
 	}
 
 	if secret.GenerateName == "" {
@@ -114,44 +283,326 @@ func (h *secretHandler[K]) Sync(ctx context.Context, key K) (*corev1.Secret, str
 
 	syncer := sync.New(h.Target.GetClient())
 
+	// never log the secret itself, only its metadata. The data must not appear in the logs.
 	lg := log.FromContext(ctx).V(logs.DebugLevel)
-	lg.Info("syncing binding secret", "secret", secret, "secretMetadata", &secret.ObjectMeta)
+	lg.Info("syncing binding secret", "secretMetadata", &secret.ObjectMeta)
 
-	_, obj, err := syncer.Sync(ctx, nil, secret, diffOpts)
+	var existing *corev1.Secret
+	if lg.Enabled() && secret.Name != "" {
+		existing = &corev1.Secret{}
+		if err := h.Target.GetClient().Get(ctx, client.ObjectKeyFromObject(secret), existing); err != nil {
+			// this is only used for logging, so we don't care about the errors here
+			existing = nil
+		}
+	}
+
+	// the server-side apply needs the name of the secret and cannot re-create the immutable secrets if they change nor
+	// the secrets the type of which changes.
+	useApply := h.ServerSideApply && secret.Name != "" && (secret.Immutable == nil || !*secret.Immutable)
+	if useApply {
+		changed, err := h.typeChanged(ctx, secret)
+		if err != nil {
+			return nil, string(ErrorReasonSecretUpdate), err
+		}
+		useApply = !changed
+	}
+
+	var obj client.Object
+	if useApply {
+		obj, err = h.apply(ctx, secret)
+	} else {
+		obj, err = syncWithRetries(secretSyncRetryCount, ctx, syncer, secret, diffOpts)
+	}
 	if err != nil {
 		return nil, string(ErrorReasonSecretUpdate), fmt.Errorf("failed to sync the secret with the token data: %w", err)
 	}
+
+	if lg.Enabled() {
+		changes := diffSecret(existing, secret)
+		lg.Info("synced binding secret",
+			"secret", client.ObjectKeyFromObject(obj),
+			"addedKeys", changes.AddedKeys,
+			"changedKeys", changes.ChangedKeys,
+			"removedKeys", changes.RemovedKeys,
+			"labelsSet", changes.Labels,
+			"annotationsSet", changes.Annotations)
+	}
+
+	h.dataHash = dataHash
+
 	return obj.(*corev1.Secret), "", nil
 }
 
+// containsAll returns true if all the desired entries are present in the actual map with the same values. The other entries of the
+// actual map are ignored, because the labels and annotations added by others are kept in the secrets.
+func containsAll(actual, desired map[string]string) bool {
+	for k, v := range desired {
+		if av, ok := actual[k]; !ok || av != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ownedData returns the part of the existing data that is written by Sync. With the server-side apply, the keys of the other
+// field managers are left in the secret, so only the keys of the desired data are considered. Otherwise, the whole data is
+// owned by Sync.
+func (h *secretHandler[K]) ownedData(existing map[string][]byte, desired map[string][]byte) map[string][]byte {
+	if !h.ServerSideApply {
+		return existing
+	}
+
+	ret := make(map[string][]byte, len(desired))
+	for k := range desired {
+		if v, ok := existing[k]; ok {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// apply writes the secret using the server-side apply with the SecretFieldManager as the field owner.
+func (h *secretHandler[K]) apply(ctx context.Context, secret *corev1.Secret) (*corev1.Secret, error) {
+	secret.GenerateName = ""
+	if err := h.Target.GetClient().Patch(ctx, secret, client.Apply, client.FieldOwner(SecretFieldManager), client.ForceOwnership); err != nil {
+		return nil, fmt.Errorf("failed to apply the secret %s: %w", client.ObjectKeyFromObject(secret), err)
+	}
+	return secret, nil
+}
+
+// typeChanged returns true if the secret with the name of the provided secret exists in the target and has a different type.
+func (h *secretHandler[K]) typeChanged(ctx context.Context, secret *corev1.Secret) (bool, error) {
+	existing := &corev1.Secret{}
+	if err := h.Target.GetClient().Get(ctx, client.ObjectKeyFromObject(secret), existing); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get the secret %s in the deployment target (%s) to check its type: %w", secret.Name, h.Target.GetType(), err)
+	}
+	return sync.SecretType(existing) != sync.SecretType(secret), nil
+}
+
+// repairMarkers makes sure that the secret with the name of the secret of the target is marked as managed by the target if it
+// exists and was deployed by the target before. The markers might have been removed from the secret manually. Without them, the
+// secret would not be found when listing the secrets of the target and therefore never cleaned up. A secret not deployed by the
+// target is only taken over if the spec allows adopting the existing secrets, otherwise an error is returned.
+func (h *secretHandler[K]) repairMarkers(ctx context.Context) (string, error) {
+	deployed := h.Target.GetActualSecretName()
+	secretName := deployed
+	if secretName == "" {
+		secretName = h.Target.GetSpec().Name
+	}
+	if secretName == "" {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := h.Target.GetClient().Get(ctx, client.ObjectKey{Name: secretName, Namespace: h.Target.GetTargetNamespace()}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return string(ErrorReasonSecretUpdate), fmt.Errorf("failed to get the secret %s in the deployment target (%s) to check its markers: %w", secretName, h.Target.GetType(), err)
+	}
+
+	if deployed != "" && h.RecordedSecretUID != "" && secret.UID != h.RecordedSecretUID {
+		log.FromContext(ctx).Info("the secret has been re-created outside of the controller", "secret", client.ObjectKeyFromObject(secret),
+			"recordedUid", h.RecordedSecretUID, "uid", secret.UID)
+		h.recreated = true
+	}
+
+	managed, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), secret)
+	if err != nil {
+		return string(ErrorReasonSecretUpdate), fmt.Errorf("failed to determine if the secret %s is managed by the deployment target (%s): %w", secretName, h.Target.GetType(), err)
+	}
+	if managed {
+		return "", nil
+	}
+
+	if deployed == "" && !h.Target.GetSpec().AdoptExisting {
+		return string(ErrorReasonSecretConflict), fmt.Errorf("%w: %s", SecretConflictError, secretName)
+	}
+
+	// adopting the secret controlled by another controller would make the two controllers fight over its contents.
+	if controller := metav1.GetControllerOf(secret); deployed == "" && controller != nil && !h.Target.GetSpec().AdoptControlled {
+		return string(ErrorReasonSecretConflict), fmt.Errorf("%w: the secret %s is controlled by %s %s", SecretControlledError, secretName, controller.Kind, controller.Name)
+	}
+
+	if _, err := h.ObjectMarker.MarkManaged(ctx, h.Target.GetTargetObjectKey(), secret); err != nil {
+		return string(ErrorReasonSecretUpdate), fmt.Errorf("failed to mark the secret %s as managed in the deployment target (%s): %w", secretName, h.Target.GetType(), err)
+	}
+
+	log.FromContext(ctx).Info("marking the existing secret as managed by the deployment target", "secret", client.ObjectKeyFromObject(secret), "adopted", deployed == "")
+	if err := h.Target.GetClient().Update(ctx, secret); err != nil {
+		return string(ErrorReasonSecretUpdate), fmt.Errorf("failed to mark the secret %s as managed in the deployment target (%s): %w", secretName, h.Target.GetType(), err)
+	}
+
+	return "", nil
+}
+
+// findExistingManaged returns the secret with the name of the secret of the target if it exists and is managed by the target.
+// Otherwise, nil is returned.
+func (h *secretHandler[K]) findExistingManaged(ctx context.Context) (*corev1.Secret, error) {
+	secretName := h.Target.GetActualSecretName()
+	if secretName == "" {
+		secretName = h.Target.GetSpec().Name
+	}
+	if secretName == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := h.Target.GetClient().Get(ctx, client.ObjectKey{Name: secretName, Namespace: h.Target.GetTargetNamespace()}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the secret %s in the deployment target (%s): %w", secretName, h.Target.GetType(), err)
+	}
+
+	managed, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine if the secret %s is managed by the deployment target (%s): %w", secretName, h.Target.GetType(), err)
+	}
+	if !managed {
+		return nil, nil
+	}
+
+	return secret, nil
+}
+
+// secretChanges describes the changes made to a secret. It only ever contains the names of the keys of the data, never
+// their values, so that it can be safely logged.
+type secretChanges struct {
+	AddedKeys   []string
+	ChangedKeys []string
+	RemovedKeys []string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// diffSecret compares the existing secret (which can be nil if the secret didn't exist) with the desired state of it.
+func diffSecret(existing *corev1.Secret, desired *corev1.Secret) secretChanges {
+	ret := secretChanges{
+		AddedKeys:   []string{},
+		ChangedKeys: []string{},
+		RemovedKeys: []string{},
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+	}
+
+	if existing == nil {
+		existing = &corev1.Secret{}
+	}
+
+	for k, v := range desired.Data {
+		if ev, ok := existing.Data[k]; !ok {
+			ret.AddedKeys = append(ret.AddedKeys, k)
+		} else if !bytes.Equal(ev, v) {
+			ret.ChangedKeys = append(ret.ChangedKeys, k)
+		}
+	}
+
+	for k := range existing.Data {
+		if _, ok := desired.Data[k]; !ok {
+			ret.RemovedKeys = append(ret.RemovedKeys, k)
+		}
+	}
+
+	for k, v := range desired.Labels {
+		if ev, ok := existing.Labels[k]; !ok || ev != v {
+			ret.Labels[k] = v
+		}
+	}
+
+	for k, v := range desired.Annotations {
+		if ev, ok := existing.Annotations[k]; !ok || ev != v {
+			ret.Annotations[k] = v
+		}
+	}
+
+	sort.Strings(ret.AddedKeys)
+	sort.Strings(ret.ChangedKeys)
+	sort.Strings(ret.RemovedKeys)
+
+	return ret
+}
+
 func (h *secretHandler[K]) List(ctx context.Context) ([]*corev1.Secret, error) {
-	sl := &corev1.SecretList{}
 	opts, err := h.ObjectMarker.ListManagedOptions(ctx, h.Target.GetTargetObjectKey())
 	if err != nil {
 		return nil, fmt.Errorf("failed to formulate the options to list the secrets in the deployment target (%s): %w", h.Target.GetType(), err)
 	}
 
 	opts = append(opts, client.InNamespace(h.Target.GetTargetNamespace()))
-
-	lg := log.FromContext(ctx).V(logs.DebugLevel)
-	if err := h.Target.GetClient().List(ctx, sl, opts...); err != nil {
-		return []*corev1.Secret{}, fmt.Errorf("failed to list the secrets associated with the deployment target (%s) %+v: %w", h.Target.GetType(), h.Target.GetTargetObjectKey(), err)
+	if h.ListPageSize > 0 {
+		opts = append(opts, client.Limit(h.ListPageSize))
 	}
 
-	lg.Info("listing secrets managed by target", "targetType", h.Target.GetType(), "targetKey", h.Target.GetTargetObjectKey(), "targetNamespace", h.Target.GetTargetNamespace(), "opts", opts, "secretCount", len(sl.Items))
+	lg := log.FromContext(ctx).V(logs.DebugLevel)
 
 	ret := []*corev1.Secret{}
-	for i := range sl.Items {
-		if ok, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), &sl.Items[i]); err != nil {
-			return []*corev1.Secret{}, fmt.Errorf("failed to determine if the secret %s is managed while processing the deployment target (%s) %s: %w",
-				client.ObjectKeyFromObject(&sl.Items[i]),
-				h.Target.GetType(),
-				h.Target.GetTargetObjectKey(),
-				err)
-		} else if ok {
-			ret = append(ret, &sl.Items[i])
+	continueToken := ""
+	for {
+		pageOpts := opts
+		if continueToken != "" {
+			pageOpts = append(opts[:len(opts):len(opts)], client.Continue(continueToken))
+		}
+
+		sl := &corev1.SecretList{}
+		if err := h.Target.GetClient().List(ctx, sl, pageOpts...); err != nil {
+			return []*corev1.Secret{}, fmt.Errorf("failed to list the secrets associated with the deployment target (%s) %+v: %w", h.Target.GetType(), h.Target.GetTargetObjectKey(), err)
+		}
+
+		lg.Info("listing secrets managed by target", "targetType", h.Target.GetType(), "targetKey", h.Target.GetTargetObjectKey(), "targetNamespace", h.Target.GetTargetNamespace(), "opts", pageOpts, "secretCount", len(sl.Items))
+
+		for i := range sl.Items {
+			if ok, err := h.ObjectMarker.IsManagedBy(ctx, h.Target.GetTargetObjectKey(), &sl.Items[i]); err != nil {
+				return []*corev1.Secret{}, fmt.Errorf("failed to determine if the secret %s is managed while processing the deployment target (%s) %s: %w",
+					client.ObjectKeyFromObject(&sl.Items[i]),
+					h.Target.GetType(),
+					h.Target.GetTargetObjectKey(),
+					err)
+			} else if ok {
+				ret = append(ret, &sl.Items[i])
+			}
+		}
+
+		continueToken = sl.Continue
+		if h.ListPageSize <= 0 || continueToken == "" {
+			break
 		}
 	}
 
 	return ret, nil
 }
+
+// checkSecretDataSize returns SecretTooLargeError if the total size of the values in the data exceeds MaxSecretDataSize.
+func checkSecretDataSize(data map[string][]byte) error {
+	size := 0
+	for _, v := range data {
+		size += len(v)
+	}
+
+	if size > MaxSecretDataSize {
+		return fmt.Errorf("%w: the data has approximately %d KiB, the limit is %d KiB", SecretTooLargeError, size/1024, MaxSecretDataSize/1024)
+	}
+
+	return nil
+}
+
+// DataHash returns a short hash of the provided secret data. The hash doesn't depend on the order of the keys in the map.
+func DataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, k := range keys {
+		// length-prefix the keys and values so that different data cannot produce the same input to the hash
+		_, _ = fmt.Fprintf(hash, "%d:%s%d:", len(k), k, len(data[k]))
+		_, _ = hash.Write(data[k])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -16,6 +16,7 @@ package bindings
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
@@ -23,6 +24,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -110,8 +113,9 @@ func TestSync(t *testing.T) {
 		t.Run("service-account-token secret type", func(t *testing.T) {
 			deploymentTarget.GetSpecImpl = func() api.LinkableSecretSpec {
 				return api.LinkableSecretSpec{
-					Name: "secret",
-					Type: corev1.SecretTypeServiceAccountToken,
+					Name:          "secret",
+					Type:          corev1.SecretTypeServiceAccountToken,
+					AdoptExisting: true,
 				}
 			}
 			deploymentTarget.GetClientImpl = func() client.Client {
@@ -150,8 +154,9 @@ func TestSync(t *testing.T) {
 		t.Run("other secret types", func(t *testing.T) {
 			deploymentTarget.GetSpecImpl = func() api.LinkableSecretSpec {
 				return api.LinkableSecretSpec{
-					Name: "secret",
-					Type: corev1.SecretTypeBasicAuth,
+					Name:          "secret",
+					Type:          corev1.SecretTypeBasicAuth,
+					AdoptExisting: true,
 				}
 			}
 			deploymentTarget.GetClientImpl = func() client.Client {
@@ -254,3 +259,673 @@ func TestList(t *testing.T) {
 	assert.Len(t, scs, 1)
 	assert.Equal(t, scs[0].Name, "shes-the-one")
 }
+
+// pagingClient emulates the server-side pagination of the secret lists that the fake client doesn't support.
+type pagingClient struct {
+	client.Client
+	listCalls int
+}
+
+func (c *pagingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.listCalls++
+
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err //nolint:wrapcheck // this is a test
+	}
+
+	lo := &client.ListOptions{}
+	lo.ApplyOptions(opts)
+
+	sl := list.(*corev1.SecretList)
+	start := 0
+	if lo.Continue != "" {
+		start, _ = strconv.Atoi(lo.Continue)
+	}
+	end := len(sl.Items)
+	if lo.Limit > 0 && start+int(lo.Limit) < end {
+		end = start + int(lo.Limit)
+		sl.Continue = strconv.Itoa(end)
+	}
+	sl.Items = sl.Items[start:end]
+
+	return nil
+}
+
+func TestListPaginated(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	objs := []client.Object{}
+	for i := 0; i < 7; i++ {
+		labels := map[string]string{}
+		if i%2 == 0 {
+			labels["managed"] = "true"
+		}
+		objs = append(objs, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret-" + strconv.Itoa(i),
+				Namespace: "default",
+				Labels:    labels,
+			},
+		})
+	}
+
+	cl := &pagingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetClientImpl:          func() client.Client { return cl },
+			GetTargetNamespaceImpl: func() string { return "default" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(ctx context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				return o.GetLabels()["managed"] == "true", nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{},
+		ListPageSize:     3,
+	}
+
+	scs, err := h.List(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, cl.listCalls)
+	assert.Len(t, scs, 4)
+	for _, s := range scs {
+		assert.Equal(t, "true", s.Labels["managed"])
+	}
+
+	t.Run("no pagination by default", func(t *testing.T) {
+		cl.listCalls = 0
+		h.ListPageSize = 0
+
+		scs, err := h.List(context.TODO())
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, cl.listCalls)
+		assert.Len(t, scs, 4)
+	})
+}
+
+func TestSyncMissingRequiredKeys(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{
+					Name: "secret",
+					Type: corev1.SecretTypeTLS,
+				}
+			},
+			GetClientImpl:          func() client.Client { return fake.NewClientBuilder().WithScheme(scheme).Build() },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"unrelated": []byte("value")}, "", nil
+			},
+		},
+	}
+
+	secret, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.Nil(t, secret)
+	assert.Equal(t, string(ErrorReasonMissingRequiredKeys), reason)
+	assert.ErrorIs(t, err, MissingRequiredKeysError)
+	assert.Contains(t, err.Error(), corev1.TLSCertKey)
+	assert.Contains(t, err.Error(), corev1.TLSPrivateKeyKey)
+}
+
+func TestSyncSecretTooLarge(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret"}
+			},
+			GetClientImpl:          func() client.Client { return fake.NewClientBuilder().WithScheme(scheme).Build() },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{
+					"a": make([]byte, MaxSecretDataSize/2),
+					"b": make([]byte, MaxSecretDataSize/2+1),
+				}, "", nil
+			},
+		},
+	}
+
+	secret, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.Nil(t, secret)
+	assert.Equal(t, string(ErrorReasonSecretTooLarge), reason)
+	assert.ErrorIs(t, err, SecretTooLargeError)
+	assert.Contains(t, err.Error(), "1024 KiB")
+}
+
+func TestSyncInvalidMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{
+					Name:        "secret",
+					Labels:      map[string]string{"bad_domain.com/label": "value", "ok": "value"},
+					Annotations: map[string]string{"ok": "value"},
+				}
+			},
+			GetClientImpl:          func() client.Client { return fake.NewClientBuilder().WithScheme(scheme).Build() },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"a": []byte("b")}, "", nil
+			},
+		},
+	}
+
+	secret, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.Nil(t, secret)
+	assert.Equal(t, string(ErrorReasonInvalidSecretMetadata), reason)
+	assert.ErrorIs(t, err, InvalidSecretMetadataError)
+	assert.Contains(t, err.Error(), "bad_domain.com/label")
+}
+
+func TestDiffSecret(t *testing.T) {
+	t.Run("new secret", func(t *testing.T) {
+		changes := diffSecret(nil, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"l": "v"},
+			},
+			Data: map[string][]byte{"b": []byte("secret"), "a": []byte("secret")},
+		})
+
+		assert.Equal(t, []string{"a", "b"}, changes.AddedKeys)
+		assert.Empty(t, changes.ChangedKeys)
+		assert.Empty(t, changes.RemovedKeys)
+		assert.Equal(t, map[string]string{"l": "v"}, changes.Labels)
+	})
+
+	t.Run("updated secret", func(t *testing.T) {
+		changes := diffSecret(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{"l": "v"},
+				Annotations: map[string]string{"a": "old"},
+			},
+			Data: map[string][]byte{"same": []byte("secret"), "changed": []byte("old"), "removed": []byte("secret")},
+		}, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{"l": "v"},
+				Annotations: map[string]string{"a": "new"},
+			},
+			Data: map[string][]byte{"same": []byte("secret"), "changed": []byte("new"), "added": []byte("secret")},
+		})
+
+		assert.Equal(t, []string{"added"}, changes.AddedKeys)
+		assert.Equal(t, []string{"changed"}, changes.ChangedKeys)
+		assert.Equal(t, []string{"removed"}, changes.RemovedKeys)
+		assert.Empty(t, changes.Labels)
+		assert.Equal(t, map[string]string{"a": "new"}, changes.Annotations)
+	})
+}
+
+func TestDataHash(t *testing.T) {
+	a := DataHash(map[string][]byte{"a": []byte("b"), "c": []byte("d")})
+	assert.Len(t, a, 16)
+	assert.Equal(t, a, DataHash(map[string][]byte{"c": []byte("d"), "a": []byte("b")}))
+	assert.NotEqual(t, a, DataHash(map[string][]byte{"a": []byte("b"), "c": []byte("e")}))
+	assert.NotEqual(t, DataHash(map[string][]byte{"ab": []byte("c")}), DataHash(map[string][]byte{"a": []byte("bc")}))
+}
+
+type writeCountingClient struct {
+	client.Client
+	writes int
+}
+
+func (c *writeCountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.writes++
+	return c.Client.Create(ctx, obj, opts...) //nolint:wrapcheck // test code
+}
+
+func (c *writeCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.writes++
+	return c.Client.Update(ctx, obj, opts...) //nolint:wrapcheck // test code
+}
+
+func TestSyncSkipsUnchangedData(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	data := map[string][]byte{"a": []byte("b")}
+	cl := &writeCountingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"a": []byte("b")},
+		Type:       corev1.SecretTypeOpaque,
+	}).Build()}
+
+	spec := api.LinkableSecretSpec{Name: "secret", Type: corev1.SecretTypeOpaque}
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl:            func() api.LinkableSecretSpec { return spec },
+			GetClientImpl:          func() client.Client { return cl },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(context.Context, client.ObjectKey, client.Object) (bool, error) {
+				return true, nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return data, "", nil
+			},
+		},
+		RecordedDataHash: DataHash(data),
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		secret, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", secret.Name)
+		assert.Equal(t, 0, cl.writes)
+		assert.Equal(t, DataHash(data), h.dataHash)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		data = map[string][]byte{"a": []byte("c")}
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cl.writes)
+		assert.Equal(t, DataHash(data), h.dataHash)
+	})
+
+	t.Run("immutability changed", func(t *testing.T) {
+		h.RecordedDataHash = DataHash(data)
+		spec.Immutable = pointer.Bool(true)
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Greater(t, cl.writes, 1)
+
+		secret := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+		assert.True(t, *secret.Immutable)
+		assert.Equal(t, data, secret.Data)
+	})
+
+	t.Run("label changed", func(t *testing.T) {
+		h.RecordedDataHash = DataHash(data)
+		spec.Immutable = nil
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+
+		// the labels removed by others are not restored
+		writes := cl.writes
+		spec.Labels = map[string]string{"team": "a"}
+		_, _, err = h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, writes, cl.writes)
+
+		// but the labels changed in the spec are applied
+		h.SpecChanged = true
+		_, _, err = h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Greater(t, cl.writes, writes)
+
+		secret := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+		assert.Equal(t, "a", secret.Labels["team"])
+		assert.Equal(t, data, secret.Data)
+
+		// the labels in place don't need any write
+		writes = cl.writes
+		_, _, err = h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, writes, cl.writes)
+	})
+}
+
+func TestSyncMirrorsData(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	// someone else added a key to the managed secret
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"a": []byte("b"), "foreign": []byte("value")},
+		Type:       corev1.SecretTypeOpaque,
+	}).Build()
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret"}
+			},
+			GetClientImpl:           func() client.Client { return cl },
+			GetTargetNamespaceImpl:  func() string { return "ns" },
+			GetActualSecretNameImpl: func() string { return "secret" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(context.Context, client.ObjectKey, client.Object) (bool, error) {
+				return true, nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"a": []byte("b")}, "", nil
+			},
+		},
+		RecordedDataHash: DataHash(map[string][]byte{"a": []byte("b")}),
+	}
+
+	_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+	assert.Equal(t, map[string][]byte{"a": []byte("b")}, secret.Data)
+}
+
+type patchRecordingClient struct {
+	client.Client
+	patchType  types.PatchType
+	fieldOwner string
+}
+
+func (c *patchRecordingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patchType = patch.Type()
+	po := &client.PatchOptions{}
+	po.ApplyOptions(opts)
+	c.fieldOwner = po.FieldManager
+	return c.Client.Patch(ctx, obj, patch, opts...) //nolint:wrapcheck // test code
+}
+
+func TestSyncServerSideApply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	// the foreign key is owned by another field manager
+	cl := &patchRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"a": []byte("b"), "foreign": []byte("value")},
+		Type:       corev1.SecretTypeOpaque,
+	}).Build()}
+
+	data := map[string][]byte{"a": []byte("c")}
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret"}
+			},
+			GetClientImpl:           func() client.Client { return cl },
+			GetTargetNamespaceImpl:  func() string { return "ns" },
+			GetActualSecretNameImpl: func() string { return "secret" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(context.Context, client.ObjectKey, client.Object) (bool, error) {
+				return true, nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return data, "", nil
+			},
+		},
+		ServerSideApply: true,
+	}
+
+	_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.NoError(t, err)
+	assert.Equal(t, types.ApplyPatchType, cl.patchType)
+	assert.Equal(t, SecretFieldManager, cl.fieldOwner)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+	assert.Equal(t, map[string][]byte{"a": []byte("c"), "foreign": []byte("value")}, secret.Data)
+
+	t.Run("foreign keys don't force the rewrite", func(t *testing.T) {
+		cl.patchType = ""
+		h.RecordedDataHash = DataHash(data)
+
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Empty(t, cl.patchType)
+	})
+}
+
+func TestSyncSecretTypeChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	test := func(t *testing.T, from, to corev1.SecretType, serverSideApply bool) {
+		cl := &patchRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+			Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+			Type:       from,
+		}).Build()}
+
+		h := secretHandler[*api.RemoteSecret]{
+			Target: &TestDeploymentTarget{
+				GetSpecImpl: func() api.LinkableSecretSpec {
+					return api.LinkableSecretSpec{Name: "secret", Type: to}
+				},
+				GetClientImpl:           func() client.Client { return cl },
+				GetTargetNamespaceImpl:  func() string { return "ns" },
+				GetActualSecretNameImpl: func() string { return "secret" },
+			},
+			ObjectMarker: &TestObjectMarker{
+				IsManagedByImpl: func(context.Context, client.ObjectKey, client.Object) (bool, error) {
+					return true, nil
+				},
+			},
+			SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+				GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+					return map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}, "", nil
+				},
+			},
+			ServerSideApply: serverSideApply,
+		}
+
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		// the type cannot be changed by applying the secret
+		assert.Empty(t, cl.patchType)
+
+		secret := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+		assert.Equal(t, to, secret.Type)
+	}
+
+	t.Run("opaque to tls", func(t *testing.T) {
+		test(t, corev1.SecretTypeOpaque, corev1.SecretTypeTLS, false)
+	})
+
+	t.Run("tls to opaque", func(t *testing.T) {
+		test(t, corev1.SecretTypeTLS, corev1.SecretTypeOpaque, false)
+	})
+
+	t.Run("opaque to tls with server-side apply", func(t *testing.T) {
+		test(t, corev1.SecretTypeOpaque, corev1.SecretTypeTLS, true)
+	})
+
+	t.Run("tls to opaque with server-side apply", func(t *testing.T) {
+		test(t, corev1.SecretTypeTLS, corev1.SecretTypeOpaque, true)
+	})
+}
+
+func TestSyncRepairsMarkers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	// the secret has been deployed before, but its markers have been removed since
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"a": []byte("b")},
+	}).Build()
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret"}
+			},
+			GetClientImpl:           func() client.Client { return cl },
+			GetTargetNamespaceImpl:  func() string { return "ns" },
+			GetActualSecretNameImpl: func() string { return "secret" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			MarkManagedImpl: func(_ context.Context, _ client.ObjectKey, o client.Object) (bool, error) {
+				if o.GetLabels()["managed"] == "true" {
+					return false, nil
+				}
+				o.SetLabels(map[string]string{"managed": "true"})
+				return true, nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"a": []byte("b")}, "", nil
+			},
+		},
+	}
+
+	_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+	assert.Equal(t, "true", secret.Labels["managed"])
+}
+
+func TestSyncRefusesUnmanagedSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"theirs": []byte("value")},
+	}).Build()
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret"}
+			},
+			GetClientImpl:          func() client.Client { return cl },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"a": []byte("b")}, "", nil
+			},
+		},
+	}
+
+	_, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.ErrorIs(t, err, SecretConflictError)
+	assert.Equal(t, string(ErrorReasonSecretConflict), reason)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+	assert.Equal(t, map[string][]byte{"theirs": []byte("value")}, secret.Data)
+}
+
+func TestSyncAdoptsControlledSecretOnlyWhenForced(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	spec := api.LinkableSecretSpec{Name: "secret", AdoptExisting: true}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "ns",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "example.com/v1", Kind: "Other", Name: "owner", UID: "owner-uid", Controller: pointer.Bool(true)},
+			},
+		},
+		Data: map[string][]byte{"theirs": []byte("value")},
+	}).Build()
+
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl:            func() api.LinkableSecretSpec { return spec },
+			GetClientImpl:          func() client.Client { return cl },
+			GetTargetNamespaceImpl: func() string { return "ns" },
+		},
+		ObjectMarker: &TestObjectMarker{},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return map[string][]byte{"a": []byte("b")}, "", nil
+			},
+		},
+	}
+
+	_, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.ErrorIs(t, err, SecretControlledError)
+	assert.Equal(t, string(ErrorReasonSecretConflict), reason)
+	assert.Contains(t, err.Error(), "Other owner")
+
+	spec.AdoptControlled = true
+	secret, reason, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+	assert.Equal(t, []byte("b"), secret.Data["a"])
+}
+
+func TestSyncRestoresRecreatedSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	// the secret has been deleted and re-created by someone else with the same data but without the labels
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns", UID: "new-uid"},
+		Data:       map[string][]byte{"a": []byte("b")},
+	}).Build()
+
+	data := map[string][]byte{"a": []byte("b")}
+	h := secretHandler[*api.RemoteSecret]{
+		Target: &TestDeploymentTarget{
+			GetSpecImpl: func() api.LinkableSecretSpec {
+				return api.LinkableSecretSpec{Name: "secret", Labels: map[string]string{"app": "test"}}
+			},
+			GetClientImpl:           func() client.Client { return cl },
+			GetTargetNamespaceImpl:  func() string { return "ns" },
+			GetActualSecretNameImpl: func() string { return "secret" },
+		},
+		ObjectMarker: &TestObjectMarker{
+			IsManagedByImpl: func(context.Context, client.ObjectKey, client.Object) (bool, error) {
+				return true, nil
+			},
+		},
+		SecretDataGetter: &TestSecretDataGetter[*api.RemoteSecret]{
+			GetDataImpl: func(ctx context.Context, _ *api.RemoteSecret) (map[string][]byte, string, error) {
+				return data, "", nil
+			},
+		},
+		RecordedDataHash:  DataHash(data),
+		RecordedSecretUID: "new-uid",
+	}
+
+	t.Run("same secret is not touched", func(t *testing.T) {
+		secret, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Empty(t, secret.Labels["app"])
+	})
+
+	t.Run("re-created secret is restored", func(t *testing.T) {
+		h.RecordedSecretUID = "old-uid"
+		_, _, err := h.Sync(context.TODO(), &api.RemoteSecret{})
+		assert.NoError(t, err)
+
+		secret := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "ns"}, secret))
+		assert.Equal(t, "test", secret.Labels["app"])
+	})
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SSHKnownHostsKey is the key of the optional known_hosts data in the kubernetes.io/ssh-auth secrets.
+const SSHKnownHostsKey = "known_hosts"
+
+// requiredKeys lists the keys that Kubernetes requires to be present in the secrets of given types.
+var requiredKeys = map[corev1.SecretType][]string{
+	corev1.SecretTypeDockercfg:        {corev1.DockerConfigKey},
+	corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+	corev1.SecretTypeSSHAuth:          {corev1.SSHAuthPrivateKey},
+	corev1.SecretTypeTLS:              {corev1.TLSCertKey, corev1.TLSPrivateKeyKey},
+}
+
+// RequiredKeys returns the keys that must be present in the data of a secret of the provided type.
+func RequiredKeys(secretType corev1.SecretType) []string {
+	return requiredKeys[secretType]
+}
+
+// missingRequiredKeys returns the keys required by the secret type that are not present in the provided data.
+func missingRequiredKeys(secretType corev1.SecretType, data map[string][]byte) []string {
+	var missing []string
+	for _, k := range requiredKeys[secretType] {
+		if _, ok := data[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
+
+// projectOptionalKeys returns the subset of the provided data containing only the keys required by the secret type, the provided
+// optional keys and the keys of the provided templates. The data is returned unchanged if there are no optional keys. It is an error
+// if the optional keys include a key required by the secret type.
+func projectOptionalKeys(secretType corev1.SecretType, optional []string, templates map[string]string, data map[string][]byte) (map[string][]byte, error) {
+	if len(optional) == 0 {
+		return data, nil
+	}
+
+	declared := make(map[string]bool, len(optional)+len(requiredKeys[secretType])+len(templates))
+	for _, k := range requiredKeys[secretType] {
+		declared[k] = true
+	}
+
+	var overlap []string
+	for _, k := range optional {
+		if declared[k] {
+			overlap = append(overlap, k)
+		}
+	}
+	if len(overlap) > 0 {
+		return nil, fmt.Errorf("%w: %s", OptionalKeysRequiredError, strings.Join(overlap, ", "))
+	}
+
+	for _, k := range optional {
+		declared[k] = true
+	}
+	for k := range templates {
+		declared[k] = true
+	}
+
+	ret := make(map[string][]byte, len(declared))
+	for k, v := range data {
+		if declared[k] {
+			ret[k] = v
+		}
+	}
+
+	return ret, nil
+}
+
+// validateContents checks that the values in the provided data can be parsed as required by the secret type.
+func validateContents(secretType corev1.SecretType, data map[string][]byte) error {
+	if secretType != corev1.SecretTypeSSHAuth {
+		return nil
+	}
+
+	if key, ok := data[corev1.SSHAuthPrivateKey]; ok {
+		if _, err := ssh.ParseRawPrivateKey(key); err != nil {
+			return fmt.Errorf("%w: the %s is not a valid private key: %s", InvalidSecretContentsError, corev1.SSHAuthPrivateKey, err.Error())
+		}
+	}
+
+	if knownHosts, ok := data[SSHKnownHostsKey]; ok {
+		rest := knownHosts
+		for len(rest) > 0 {
+			var err error
+			_, _, _, _, rest, err = ssh.ParseKnownHosts(rest)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%w: the %s is not in the known_hosts format: %s", InvalidSecretContentsError, SSHKnownHostsKey, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateContents(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	privDer, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	privKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDer})
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	assert.NoError(t, err)
+	knownHosts := append([]byte("github.com "), ssh.MarshalAuthorizedKey(sshPub)...)
+
+	t.Run("valid ssh-auth", func(t *testing.T) {
+		assert.NoError(t, validateContents(corev1.SecretTypeSSHAuth, map[string][]byte{
+			corev1.SSHAuthPrivateKey: privKey,
+			SSHKnownHostsKey:         knownHosts,
+		}))
+	})
+
+	t.Run("invalid private key", func(t *testing.T) {
+		err := validateContents(corev1.SecretTypeSSHAuth, map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("not a key"),
+		})
+		assert.ErrorIs(t, err, InvalidSecretContentsError)
+	})
+
+	t.Run("invalid known hosts", func(t *testing.T) {
+		err := validateContents(corev1.SecretTypeSSHAuth, map[string][]byte{
+			corev1.SSHAuthPrivateKey: privKey,
+			SSHKnownHostsKey:         []byte("github.com not-a-key"),
+		})
+		assert.ErrorIs(t, err, InvalidSecretContentsError)
+	})
+
+	t.Run("other types not validated", func(t *testing.T) {
+		assert.NoError(t, validateContents(corev1.SecretTypeOpaque, map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("not a key"),
+		}))
+	})
+}
+
+func TestProjectOptionalKeys(t *testing.T) {
+	data := map[string][]byte{
+		corev1.SSHAuthPrivateKey: []byte("key"),
+		SSHKnownHostsKey:         []byte("hosts"),
+		"url":                    []byte("url"),
+		"unrelated":              []byte("value"),
+	}
+
+	t.Run("no optional keys", func(t *testing.T) {
+		projected, err := projectOptionalKeys(corev1.SecretTypeSSHAuth, nil, nil, data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, projected)
+	})
+
+	t.Run("projects declared keys", func(t *testing.T) {
+		projected, err := projectOptionalKeys(corev1.SecretTypeSSHAuth, []string{SSHKnownHostsKey, "missing"}, map[string]string{"url": ""}, data)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("key"),
+			SSHKnownHostsKey:         []byte("hosts"),
+			"url":                    []byte("url"),
+		}, projected)
+	})
+
+	t.Run("overlap with required keys", func(t *testing.T) {
+		_, err := projectOptionalKeys(corev1.SecretTypeSSHAuth, []string{corev1.SSHAuthPrivateKey}, nil, data)
+		assert.ErrorIs(t, err, OptionalKeysRequiredError)
+	})
+}
@@ -43,6 +43,8 @@ type SecretDeploymentTarget interface {
 	// GetActualServiceAccountNames returns the names of the service accounts that the spec
 	// configures.
 	GetActualServiceAccountNames() []string
+	// GetActualConfigMapName returns the name of the companion config map deployed together with the secret, if any.
+	GetActualConfigMapName() string
 }
 
 // SecretDataGetter is an abstraction that, given the provided key, is able to obtain the secret data from some kind of backing
@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// checkKeyCollisions makes sure that each key of the final secret data is produced by a single mechanism. The templates must not
+// produce the keys already present in the obtained data nor the key the registry credentials are aggregated into. The aggregation
+// of the registry credentials into the key already present in the obtained data is intentional, so it is not a collision.
+func checkKeyCollisions(spec *api.LinkableSecretSpec, data map[string][]byte) error {
+	registryKey := ""
+	if len(spec.Registries) > 0 {
+		switch spec.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			registryKey = corev1.DockerConfigJsonKey
+		case corev1.SecretTypeDockercfg:
+			registryKey = corev1.DockerConfigKey
+		}
+	}
+
+	// check in a stable order so that the reported error doesn't change between the reconciliations
+	keys := make([]string, 0, len(spec.Templates))
+	for k := range spec.Templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ok := data[key]; ok {
+			return fmt.Errorf("%w: the key '%s' is both in the secret data and produced by a template", KeyCollisionError, key)
+		}
+		if key == registryKey {
+			return fmt.Errorf("%w: the key '%s' is both produced by a template and the aggregation of the registry credentials", KeyCollisionError, key)
+		}
+	}
+
+	return nil
+}
+
+// renderTemplates renders the provided templates using the provided data and returns a copy of the data with the rendered values
+// added under the keys of the templates. The templates can only reference the keys of the original data, not the results of the other
+// templates. Only the builtin functions of text/template are available to the templates, so they cannot access the filesystem or
+// the environment.
+func renderTemplates(templates map[string]string, data map[string][]byte) (map[string][]byte, string, error) {
+	values := make(map[string]string, len(data))
+	ret := make(map[string][]byte, len(data)+len(templates))
+	for k, v := range data {
+		values[k] = string(v)
+		ret[k] = v
+	}
+
+	// render in a stable order so that the reported error doesn't change between the reconciliations
+	keys := make([]string, 0, len(templates))
+	for k := range templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(templates[key])
+		if err != nil {
+			return nil, string(ErrorReasonInvalidTemplate), fmt.Errorf("%w %s: %s", InvalidTemplateError, key, err.Error())
+		}
+
+		buf := bytes.Buffer{}
+		if err = tmpl.Execute(&buf, values); err != nil {
+			// text/template doesn't have a dedicated error type for the missing keys
+			if strings.Contains(err.Error(), "map has no entry for key") {
+				return nil, string(ErrorReasonTemplateKeyMissing), fmt.Errorf("%w: %s", TemplateKeyMissingError, err.Error())
+			}
+			return nil, string(ErrorReasonInvalidTemplate), fmt.Errorf("%w %s: %s", InvalidTemplateError, key, err.Error())
+		}
+
+		ret[key] = buf.Bytes()
+	}
+
+	return ret, "", nil
+}
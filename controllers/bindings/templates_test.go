@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	data := map[string][]byte{
+		"user":     []byte("u"),
+		"password": []byte("p"),
+		"host":     []byte("h"),
+	}
+
+	t.Run("renders", func(t *testing.T) {
+		rendered, reason, err := renderTemplates(map[string]string{"url": "postgres://{{.user}}:{{.password}}@{{.host}}/db"}, data)
+		assert.NoError(t, err)
+		assert.Empty(t, reason)
+		assert.Equal(t, []byte("postgres://u:p@h/db"), rendered["url"])
+		assert.Equal(t, []byte("u"), rendered["user"])
+		assert.NotContains(t, data, "url")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, reason, err := renderTemplates(map[string]string{"url": "{{.db}}"}, data)
+		assert.ErrorIs(t, err, TemplateKeyMissingError)
+		assert.Equal(t, string(ErrorReasonTemplateKeyMissing), reason)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, reason, err := renderTemplates(map[string]string{"url": "{{.user"}, data)
+		assert.ErrorIs(t, err, InvalidTemplateError)
+		assert.Equal(t, string(ErrorReasonInvalidTemplate), reason)
+	})
+
+	t.Run("no custom functions", func(t *testing.T) {
+		_, reason, err := renderTemplates(map[string]string{"env": `{{env "HOME"}}`}, data)
+		assert.ErrorIs(t, err, InvalidTemplateError)
+		assert.Equal(t, string(ErrorReasonInvalidTemplate), reason)
+	})
+}
+
+func TestCheckKeyCollisions(t *testing.T) {
+	data := map[string][]byte{
+		"user":     []byte("u"),
+		"password": []byte("p"),
+	}
+
+	t.Run("no collisions", func(t *testing.T) {
+		assert.NoError(t, checkKeyCollisions(&api.LinkableSecretSpec{Templates: map[string]string{"url": "{{.user}}"}}, data))
+	})
+
+	t.Run("template overwrites data", func(t *testing.T) {
+		err := checkKeyCollisions(&api.LinkableSecretSpec{Templates: map[string]string{"url": "{{.user}}", "user": "admin"}}, data)
+		assert.ErrorIs(t, err, KeyCollisionError)
+		assert.Contains(t, err.Error(), "'user'")
+	})
+
+	t.Run("template overwrites registries", func(t *testing.T) {
+		spec := &api.LinkableSecretSpec{
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Templates:  map[string]string{corev1.DockerConfigJsonKey: "{}"},
+			Registries: []api.RegistryCredentials{{Registry: "quay.io", UsernameKey: "user", PasswordKey: "password"}},
+		}
+		err := checkKeyCollisions(spec, data)
+		assert.ErrorIs(t, err, KeyCollisionError)
+		assert.Contains(t, err.Error(), corev1.DockerConfigJsonKey)
+
+		// without the registries, the template is the only producer of the key
+		spec.Registries = nil
+		assert.NoError(t, checkKeyCollisions(spec, data))
+	})
+}
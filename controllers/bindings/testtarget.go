@@ -32,6 +32,7 @@ type TestDeploymentTarget struct {
 	GetSpecImpl                      func() api.LinkableSecretSpec
 	GetActualSecretNameImpl          func() string
 	GetActualServiceAccountNamesImpl func() []string
+	GetActualConfigMapNameImpl       func() string
 }
 
 var _ SecretDeploymentTarget = (*TestDeploymentTarget)(nil)
@@ -75,6 +76,15 @@ func (t *TestDeploymentTarget) GetActualServiceAccountNames() []string {
 	return []string{}
 }
 
+// GetActualConfigMapName implements SecretDeploymentTarget
+func (t *TestDeploymentTarget) GetActualConfigMapName() string {
+	if t.GetActualConfigMapNameImpl != nil {
+		return t.GetActualConfigMapNameImpl()
+	}
+
+	return ""
+}
+
 // GetClient implements SecretDeploymentTarget
 func (t *TestDeploymentTarget) GetClient() client.Client {
 	if t.GetClientImpl != nil {
@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretSpecProblems returns the problems of the provided secret spec that can be found without the secret data. The same problems
+// are otherwise only reported when the secret is deployed to the targets.
+func SecretSpecProblems(spec *api.LinkableSecretSpec) []string {
+	var problems []string
+
+	if err := validateSecretMetadata(spec); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := projectOptionalKeys(spec.Type, spec.OptionalKeyNames, spec.Templates, map[string][]byte{}); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(spec.Registries) > 0 && spec.Type != corev1.SecretTypeDockerConfigJson && spec.Type != corev1.SecretTypeDockercfg {
+		problems = append(problems, fmt.Sprintf("%s: the registries can only be used with the %s and %s secrets", InvalidRegistryCredentialsError,
+			corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg))
+	}
+
+	if spec.ConfigMap != nil {
+		if spec.ConfigMap.Name == "" {
+			problems = append(problems, "the name of the config map must be specified")
+		}
+		for _, k := range RequiredKeys(spec.Type) {
+			for _, ck := range spec.ConfigMap.Keys {
+				if k == ck {
+					problems = append(problems, fmt.Sprintf("the key %s required by the secret type cannot be moved to the config map", k))
+				}
+			}
+		}
+	}
+
+	// the collisions with the data keys can only be found once the data is obtained
+	if err := checkKeyCollisions(spec, map[string][]byte{}); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	keys := make([]string, 0, len(spec.Templates))
+	for k := range spec.Templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := template.New(key).Parse(spec.Templates[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", InvalidTemplateError, key, strings.TrimSpace(err.Error())))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,474 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	opconfig "github.com/redhat-appstudio/remote-secret/pkg/config"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/finalizer"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=clusterremotesecrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=clusterremotesecrets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=clusterremotesecrets/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// ClusterRemoteSecretReconciler reconciles the ClusterRemoteSecret objects. It shares the logic of deploying to the targets with
+// the RemoteSecretReconciler, the only difference being that the targets are determined using both the explicit list of the targets
+// and the namespace selector.
+type ClusterRemoteSecretReconciler struct {
+	client.Client
+	Scheme                     *runtime.Scheme
+	Configuration              *opconfig.OperatorConfiguration
+	ClusterRemoteSecretStorage remotesecretstorage.ClusterRemoteSecretStorage
+	// DataStores is the registry of the data stores to obtain the secret data from. If nil, the data stores created by
+	// remotesecrets.NewClusterRemoteSecretDataStores are used.
+	DataStores *remotesecrets.DataStoreRegistry[*api.ClusterRemoteSecret]
+	// DataCache is the optional cache of the secret data shared by the data stores created by the reconciler. It is not used
+	// if the DataStores are configured explicitly.
+	DataCache *remotesecrets.DataCache
+	// CircuitBreaker is the optional circuit breaker of the data stores created by the reconciler. It is not used if the DataStores
+	// are configured explicitly.
+	CircuitBreaker *remotesecrets.CircuitBreaker
+	// RemoteClients creates the clients of the targets in the remote clusters. If nil, the clients are created using the cluster
+	// credentials secrets referenced by the targets.
+	RemoteClients remotesecrets.RemoteClientFactory
+	// ClusterRegistry looks up the remote clusters referenced by the targets by name. If nil, the clusters are looked up using
+	// the remotesecrets.SecretClusterRegistry.
+	ClusterRegistry remotesecrets.ClusterRegistry
+	// Coalescer optionally batches the contributions of the remote secrets to the same secret in the local cluster.
+	Coalescer *bindings.ContributionCoalescer
+	// LocalApiUrl is the URL of the API server of the cluster the reconciler runs in. The targets with the API URL pointing
+	// at it are refused.
+	LocalApiUrl string
+	// ErrorLogSuppressor optionally suppresses logging the same deployment errors over and over again.
+	ErrorLogSuppressor *logs.RepeatSuppressor
+	finalizers         finalizer.Finalizers
+}
+
+var _ reconcile.Reconciler = (*ClusterRemoteSecretReconciler)(nil)
+
+func (r *ClusterRemoteSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.finalizers = finalizer.NewFinalizers()
+	if err := r.finalizers.Register(storageFinalizerName, &clusterRemoteSecretStorageFinalizer{storage: r.ClusterRemoteSecretStorage}); err != nil {
+		return fmt.Errorf("failed to register the cluster remote secret storage finalizer: %w", err)
+	}
+	if err := r.finalizers.Register(linkedObjectsFinalizerName, &clusterRemoteSecretLinksFinalizer{client: r.Client, dataStores: r.dataStores(), markerDomain: markerDomain(r.Configuration)}); err != nil {
+		return fmt.Errorf("failed to register the cluster remote secret links finalizer: %w", err)
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &api.ClusterRemoteSecret{}, remotesecrets.DataSourceIndexField, func(o client.Object) []string {
+		return remotesecrets.ClusterDataSourceIndexValues(o.(*api.ClusterRemoteSecret))
+	}); err != nil {
+		return fmt.Errorf("failed to configure the data source index of the cluster remote secrets: %w", err)
+	}
+
+	marker := &namespacetarget.NamespaceObjectMarker{Domain: markerDomain(r.Configuration)}
+	err := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: newJitteredRateLimiter(requeueJitter(r.Configuration))}).
+		For(&api.ClusterRemoteSecret{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.allClusterRemoteSecretsRequests(mgr.GetLogger())
+		}), builder.WithPredicates(selectableNamespacesPredicate)).
+		// the data sources are watched so that the changes of their data are propagated to the targets
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.dataSourceRequests(mgr.GetLogger(), o)
+		})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), marker, o, true)
+		}), builder.WithPredicates(linkedObjectsPredicate(marker))).
+		Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), marker, o, true)
+		}), builder.WithPredicates(linkedObjectsPredicate(marker))).
+		Complete(r)
+	if err != nil {
+		return fmt.Errorf("failed to configure the reconciler: %w", err)
+	}
+	return nil
+}
+
+// selectableNamespacesPredicate only lets through the creations and deletions of the namespaces and the changes of their labels and
+// annotations. The other changes, e.g. of the status of the namespaces, cannot change whether the namespaces are selected by the cluster
+// remote secrets nor the target namespaces derived from them using the target namespace templates.
+var selectableNamespacesPredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+			!equality.Semantic.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+	},
+	DeleteFunc: func(event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(event.GenericEvent) bool {
+		return false
+	},
+}
+
+// dataSourceRequests returns the reconcile requests for the cluster remote secrets copying the data from the provided secret.
+func (r *ClusterRemoteSecretReconciler) dataSourceRequests(lg logr.Logger, o client.Object) []reconcile.Request {
+	secret, ok := o.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
+
+	// the cluster remote secrets are about to be reconciled because of the change of the data, so they must not be served the stale data
+	for _, key := range remotesecrets.DataSourceCacheKeys(secret) {
+		r.dataStores().Cache.InvalidateKey(key)
+	}
+
+	keys, err := remotesecrets.ClusterRemoteSecretsCopyingFrom(context.Background(), r.Client, secret)
+	if err != nil {
+		lg.Error(err, "failed to find the cluster remote secrets copying the data from a secret", "secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(keys))
+	for _, key := range keys {
+		reqs = append(reqs, reconcile.Request{NamespacedName: key})
+	}
+	return reqs
+}
+
+// allClusterRemoteSecretsRequests returns the reconcile requests for all the cluster remote secrets. This is used when a namespace changes, because
+// any cluster remote secret might select it.
+func (r *ClusterRemoteSecretReconciler) allClusterRemoteSecretsRequests(lg logr.Logger) []reconcile.Request {
+	list := &api.ClusterRemoteSecretList{}
+	if err := r.Client.List(context.Background(), list); err != nil {
+		lg.Error(err, "failed to list the cluster remote secrets while processing a namespace change")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, len(list.Items))
+	for i := range list.Items {
+		reqs[i].NamespacedName = client.ObjectKeyFromObject(&list.Items[i])
+	}
+
+	return reqs
+}
+
+// Reconcile implements reconcile.Reconciler
+func (r *ClusterRemoteSecretReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	lg := log.FromContext(ctx)
+	lg.V(logs.DebugLevel).Info("starting reconciliation")
+	defer logs.TimeTrackWithLazyLogger(func() logr.Logger { return lg }, time.Now(), "Reconcile ClusterRemoteSecret")
+
+	remoteSecret := &api.ClusterRemoteSecret{}
+
+	if err := r.Get(ctx, req.NamespacedName, remoteSecret); err != nil {
+		if errors.IsNotFound(err) {
+			lg.V(logs.DebugLevel).Info("ClusterRemoteSecret already gone from the cluster. skipping reconciliation")
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("failed to get the ClusterRemoteSecret: %w", err)
+	}
+
+	finalizationResult, err := r.finalizers.Finalize(ctx, remoteSecret)
+	if err != nil {
+		// if the finalization fails, the finalizer stays in place, and so we don't want any repeated attempts until
+		// we get another reconciliation due to cluster state change
+		return ctrl.Result{Requeue: false}, fmt.Errorf("failed to finalize: %w", err)
+	}
+	if finalizationResult.Updated {
+		if err = r.Client.Update(ctx, remoteSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update based on finalization result: %w", err)
+		}
+	}
+	if finalizationResult.StatusUpdated {
+		if err = r.Client.Status().Update(ctx, remoteSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update the status based on finalization result: %w", err)
+		}
+	}
+
+	if remoteSecret.DeletionTimestamp != nil {
+		lg.V(logs.DebugLevel).Info("ClusterRemoteSecret is being deleted. skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	if result, suspended, err := handleSuspension(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, &remoteSecret.Spec.RemoteSecretSpec, requeueJitter(r.Configuration)); err != nil || suspended {
+		return result, err
+	}
+
+	if forceSyncRequested(remoteSecret, &remoteSecret.Status) {
+		// the forced re-sync must not be served stale data
+		r.dataStores().InvalidateCache(remoteSecret)
+	}
+
+	dataSources := remotesecrets.DataSourcesOf(&remoteSecret.Spec.RemoteSecretSpec)
+	dataResult, err := handleStage(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, obtainData(ctx, &remoteSecret.Status, dataSources.Ref(), func(ctx context.Context) (*remotesecretstorage.SecretData, error) {
+		return r.dataStores().GetMerged(ctx, dataSources, remoteSecret) //nolint:wrapcheck // the error is only stored in the condition
+	}))
+	if err != nil || dataResult.Cancellation.Cancel {
+		return dataResult.Cancellation.Result, err
+	}
+
+	if !meta.IsStatusConditionTrue(remoteSecret.Status.Conditions, string(api.RemoteSecretConditionTypeDataObtained)) {
+		lg.V(logs.DebugLevel).Info("the data of the cluster remote secret not obtained. skipping the deployment to targets")
+		return ctrl.Result{}, nil
+	}
+
+	targets, skipped, err := r.effectiveTargets(ctx, remoteSecret)
+	if err != nil {
+		_, err = handleStage(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, stageResult[any]{
+			Name: "target-selection",
+			Condition: metav1.Condition{
+				Type:    string(api.RemoteSecretConditionTypeDeployed),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(api.RemoteSecretReasonError),
+				Message: err.Error(),
+			},
+			Cancellation: cancellation{
+				Cancel:      true,
+				ReturnError: err,
+			},
+		})
+		return ctrl.Result{}, err
+	}
+
+	// the status is persisted by the deployment stage
+	if len(skipped) > 0 {
+		meta.SetStatusCondition(&remoteSecret.Status.Conditions, skippedNamespacesCondition(skipped))
+	} else {
+		meta.RemoveStatusCondition(&remoteSecret.Status.Conditions, string(api.RemoteSecretConditionTypeNamespacesSkipped))
+	}
+
+	// the target namespace policy is deliberately not applied. It restricts the tenants to the namespaces related to the namespace
+	// of their remote secret, but the cluster remote secrets have no namespace and can only be created by the cluster admins
+	// choosing the target namespaces explicitly or by the namespace selector.
+	processor := newClusterRemoteSecretTargetsProcessor(r.Client, r.dataStores(), remoteSecret, targets)
+	processor.Concurrency = targetDeploymentConcurrency(r.Configuration)
+	processor.ServerSideApply = serverSideApply(r.Configuration)
+	processor.MarkerDomain = markerDomain(r.Configuration)
+	processor.MaxTargets = maxTargets(r.Configuration, remoteSecret.Spec.MaxTargets)
+	processor.RemoteClients = r.RemoteClients
+	processor.ClusterRegistry = r.ClusterRegistry
+	processor.Coalescer = r.Coalescer
+	processor.LocalApiUrl = r.LocalApiUrl
+	processor.LogSuppressor = r.ErrorLogSuppressor
+	processor.RequeueJitter = requeueJitter(r.Configuration)
+	deployResult, err := handleStage(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, deploy(ctx, processor))
+	if err != nil || deployResult.Cancellation.Cancel {
+		return deployResult.Cancellation.Result, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// effectiveTargets returns the explicit targets from the spec of the cluster remote secret together with the targets for all the namespaces
+// matching the namespace selector. If the target namespace template is specified, the targets are in the namespaces derived from the matching
+// namespaces. The matching namespaces for which the target namespace cannot be derived are skipped and returned with the reasons.
+func (r *ClusterRemoteSecretReconciler) effectiveTargets(ctx context.Context, remoteSecret *api.ClusterRemoteSecret) ([]api.RemoteSecretTarget, map[string]string, error) {
+	targets := make([]api.RemoteSecretTarget, len(remoteSecret.Spec.Targets))
+	copy(targets, remoteSecret.Spec.Targets)
+
+	if remoteSecret.Spec.NamespaceSelector == nil {
+		return targets, nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(remoteSecret.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the namespace selector: %w", err)
+	}
+
+	var nsTemplate *remotesecrets.TargetNamespaceTemplate
+	if remoteSecret.Spec.TargetNamespaceTemplate != "" {
+		if nsTemplate, err = remotesecrets.ParseTargetNamespaceTemplate(remoteSecret.Spec.TargetNamespaceTemplate); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the target namespace template: %w", err)
+		}
+	}
+
+	nsl := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, nsl, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list the namespaces matching the namespace selector: %w", err)
+	}
+
+	// the explicit targets are never duplicated by the selected ones and neither are the namespaces derived from multiple
+	// selected namespaces.
+	known := map[remotesecrets.TargetKey]bool{}
+	for i := range remoteSecret.Spec.Targets {
+		known[remotesecrets.KeyOfTarget(&remoteSecret.Spec.Targets[i])] = true
+	}
+
+	skipped := map[string]string{}
+	selected := make([]api.RemoteSecretTarget, 0, len(nsl.Items))
+	for i := range nsl.Items {
+		ns := &nsl.Items[i]
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+
+		target := api.RemoteSecretTarget{Namespace: ns.Name}
+		if nsTemplate != nil {
+			if target.Namespace, err = nsTemplate.Render(ns); err != nil {
+				skipped[ns.Name] = err.Error()
+				continue
+			}
+		}
+
+		key := remotesecrets.KeyOfTarget(&target)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		selected = append(selected, target)
+	}
+
+	// make the order of the targets stable
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Namespace < selected[j].Namespace
+	})
+
+	return append(targets, selected...), skipped, nil
+}
+
+// skippedNamespacesCondition returns the condition reporting the namespaces matching the namespace selector that the cluster remote secret
+// could not deploy to.
+func skippedNamespacesCondition(skipped map[string]string) metav1.Condition {
+	names := make([]string, 0, len(skipped))
+	for ns := range skipped {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	problems := make([]string, len(names))
+	for i, ns := range names {
+		problems[i] = fmt.Sprintf("%s: %s", ns, skipped[ns])
+	}
+
+	return metav1.Condition{
+		Type:    string(api.RemoteSecretConditionTypeNamespacesSkipped),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(api.RemoteSecretReasonTemplateFailed),
+		Message: "The target namespace could not be derived from the selected namespaces: " + strings.Join(problems, "; "),
+	}
+}
+
+// dataStores returns the configured data stores or the default registry of the data stores of the cluster remote secrets if none
+// are configured.
+func (r *ClusterRemoteSecretReconciler) dataStores() *remotesecrets.DataStoreRegistry[*api.ClusterRemoteSecret] {
+	if r.DataStores != nil {
+		return r.DataStores
+	}
+	ret := remotesecrets.NewClusterRemoteSecretDataStores(r.ClusterRemoteSecretStorage, r.Client)
+	ret.Cache = r.DataCache
+	ret.Breaker = r.CircuitBreaker
+	return ret
+}
+
+func newClusterRemoteSecretTargetsProcessor(cl client.Client, dataStores *remotesecrets.DataStoreRegistry[*api.ClusterRemoteSecret], remoteSecret *api.ClusterRemoteSecret, targets []api.RemoteSecretTarget) *targetsProcessor[*api.ClusterRemoteSecret] {
+	return &targetsProcessor[*api.ClusterRemoteSecret]{
+		Client:         cl,
+		Object:         remoteSecret,
+		SecretSpec:     &remoteSecret.Spec.Secret,
+		Targets:        targets,
+		Status:         &remoteSecret.Status,
+		DeletionPolicy: remoteSecret.Spec.DeletionPolicy,
+		DataSources:    remotesecrets.DataSourcesOf(&remoteSecret.Spec.RemoteSecretSpec),
+		NewSecretDataGetter: func(keyFilter *api.KeyFilter, secretType corev1.SecretType) bindings.SecretDataGetter[*api.ClusterRemoteSecret] {
+			return &remotesecrets.ClusterSecretDataGetter{
+				DataStores:  dataStores,
+				DataSources: remotesecrets.DataSourcesOf(&remoteSecret.Spec.RemoteSecretSpec),
+				KeyFilter:   keyFilter,
+				SecretType:  secretType,
+			}
+		},
+	}
+}
+
+type clusterRemoteSecretStorageFinalizer struct {
+	storage remotesecretstorage.ClusterRemoteSecretStorage
+}
+
+var _ finalizer.Finalizer = (*clusterRemoteSecretStorageFinalizer)(nil)
+
+func (f *clusterRemoteSecretStorageFinalizer) Finalize(ctx context.Context, obj client.Object) (finalizer.Result, error) {
+	remoteSecret, ok := obj.(*api.ClusterRemoteSecret)
+	if !ok {
+		return finalizer.Result{}, unexpectedObjectTypeError
+	}
+
+	err := f.storage.Delete(ctx, remoteSecret)
+	if err != nil {
+		err = fmt.Errorf("failed to delete the linked token during finalization of %s: %w", obj.GetName(), err)
+	}
+	return finalizer.Result{}, err
+}
+
+type clusterRemoteSecretLinksFinalizer struct {
+	client       client.Client
+	dataStores   *remotesecrets.DataStoreRegistry[*api.ClusterRemoteSecret]
+	markerDomain string
+}
+
+var _ finalizer.Finalizer = (*clusterRemoteSecretLinksFinalizer)(nil)
+
+// Finalize removes the secrets and possibly also service accounts deployed to all the targets of the cluster remote secret being deleted
+func (f *clusterRemoteSecretLinksFinalizer) Finalize(ctx context.Context, obj client.Object) (finalizer.Result, error) {
+	res := finalizer.Result{}
+	remoteSecret, ok := obj.(*api.ClusterRemoteSecret)
+	if !ok {
+		return res, unexpectedObjectTypeError
+	}
+
+	lg := log.FromContext(ctx).V(logs.DebugLevel)
+
+	key := client.ObjectKeyFromObject(remoteSecret)
+
+	lg.Info("linked objects finalizer starting to clean up dependent objects", "clusterRemoteSecret", key)
+
+	processor := newClusterRemoteSecretTargetsProcessor(f.client, f.dataStores, remoteSecret, remoteSecret.Spec.Targets)
+	processor.MarkerDomain = f.markerDomain
+	if err := processor.cleanup(ctx); err != nil {
+		lg.Error(err, "failed to clean up the dependent objects in the finalizer", "clusterRemoteSecret", key)
+		return res, fmt.Errorf("failed to clean up dependent objects in the finalizer: %w", err)
+	}
+
+	lg.Info("linked objects finalizer completed without failure", "clusterRemoteSecret", key)
+
+	return res, nil
+}
@@ -0,0 +1,301 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	opconfig "github.com/redhat-appstudio/remote-secret/pkg/config"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/memorystorage"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/finalizer"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestClusterRemoteSecretReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	crs := &api.ClusterRemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "crs",
+			UID:  "crs-uid",
+		},
+		Spec: api.ClusterRemoteSecretSpec{
+			RemoteSecretSpec: api.RemoteSecretSpec{
+				Secret: api.LinkableSecretSpec{
+					Name: "target-secret",
+				},
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "explicit"},
+				},
+			},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"receive": "true"},
+			},
+		},
+	}
+
+	namespace := func(name string, selected bool) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if selected {
+			ns.Labels = map[string]string{"receive": "true"}
+		}
+		return ns
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		crs,
+		namespace("explicit", false),
+		namespace("selected-1", true),
+		namespace("selected-2", true),
+		namespace("unselected", false),
+	).Build()
+
+	storage := remotesecretstorage.NewJSONSerializingClusterRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), crs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &ClusterRemoteSecretReconciler{
+		Client:                     cl,
+		Scheme:                     scheme,
+		ClusterRemoteSecretStorage: storage,
+		finalizers:                 finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(crs)}
+	secretIn := func(ns string) error {
+		return cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: ns}, &corev1.Secret{})
+	}
+
+	t.Run("fans out to selected namespaces", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, secretIn("explicit"))
+		assert.NoError(t, secretIn("selected-1"))
+		assert.NoError(t, secretIn("selected-2"))
+		assert.True(t, errors.IsNotFound(secretIn("unselected")))
+
+		current := &api.ClusterRemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Len(t, current.Status.Targets, 3)
+	})
+
+	t.Run("removes from namespaces no longer selected", func(t *testing.T) {
+		ns := &corev1.Namespace{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "selected-2"}, ns))
+		ns.Labels = nil
+		assert.NoError(t, cl.Update(context.TODO(), ns))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, secretIn("explicit"))
+		assert.NoError(t, secretIn("selected-1"))
+		assert.True(t, errors.IsNotFound(secretIn("selected-2")))
+
+		current := &api.ClusterRemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Len(t, current.Status.Targets, 2)
+	})
+	t.Run("derives the target namespaces using the template", func(t *testing.T) {
+		for _, ns := range []*corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-a-app", Labels: map[string]string{"receive": "true", "team": "team-a"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-a-shared"}},
+		} {
+			assert.NoError(t, cl.Create(context.TODO(), ns))
+		}
+
+		current := &api.ClusterRemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		current.Spec.TargetNamespaceTemplate = "{{ .Labels.team }}-shared"
+		assert.NoError(t, cl.Update(context.TODO(), current))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, secretIn("explicit"))
+		assert.NoError(t, secretIn("team-a-shared"))
+		assert.True(t, errors.IsNotFound(secretIn("team-a-app")))
+		assert.True(t, errors.IsNotFound(secretIn("selected-1")))
+
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Len(t, current.Status.Targets, 2)
+		cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeNamespacesSkipped))
+		assert.NotNil(t, cond)
+		assert.Contains(t, cond.Message, "selected-1")
+		assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed)))
+	})
+}
+
+func TestClusterRemoteSecretReconcile_DataFrom(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	crs := &api.ClusterRemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "crs",
+			UID:  "crs-uid",
+		},
+		Spec: api.ClusterRemoteSecretSpec{
+			RemoteSecretSpec: api.RemoteSecretSpec{
+				DataFrom: &api.DataFrom{Name: "source", Namespace: "sources"},
+				Secret: api.LinkableSecretSpec{
+					Name: "target-secret",
+				},
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "target-ns"},
+				},
+			},
+		},
+	}
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "sources"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&api.ClusterRemoteSecret{}, remotesecrets.DataSourceIndexField, func(o client.Object) []string {
+			return remotesecrets.ClusterDataSourceIndexValues(o.(*api.ClusterRemoteSecret))
+		}).
+		WithObjects(crs, source).Build()
+	storage := remotesecretstorage.NewJSONSerializingClusterRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+
+	r := &ClusterRemoteSecretReconciler{
+		Client:                     cl,
+		Scheme:                     scheme,
+		ClusterRemoteSecretStorage: storage,
+		finalizers:                 finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(crs)}
+	targetData := func() []byte {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}, s))
+		return s.Data["key"]
+	}
+
+	t.Run("data copied from the source", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []byte("value"), targetData())
+	})
+
+	t.Run("source change is propagated", func(t *testing.T) {
+		source.Data["key"] = []byte("changed")
+		assert.NoError(t, cl.Update(context.TODO(), source))
+		assert.Equal(t, []reconcile.Request{req}, r.dataSourceRequests(logr.Discard(), source))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []byte("changed"), targetData())
+	})
+}
+
+func TestClusterRemoteSecretReconcile_NamespacePolicyNotApplied(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	crs := &api.ClusterRemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "crs",
+			UID:  "crs-uid",
+		},
+		Spec: api.ClusterRemoteSecretSpec{
+			RemoteSecretSpec: api.RemoteSecretSpec{
+				Secret: api.LinkableSecretSpec{
+					Name: "target-secret",
+				},
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "ns-1"},
+					{Namespace: "ns-2"},
+				},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crs).Build()
+	storage := remotesecretstorage.NewJSONSerializingClusterRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), crs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &ClusterRemoteSecretReconciler{
+		Client:                     cl,
+		Scheme:                     scheme,
+		ClusterRemoteSecretStorage: storage,
+		Configuration:              &opconfig.OperatorConfiguration{TargetNamespacePolicy: string(remotesecrets.TargetNamespacePolicyOwnNamespace)},
+		finalizers:                 finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(crs)})
+	assert.NoError(t, err)
+
+	for _, ns := range []string{"ns-1", "ns-2"} {
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: ns}, &corev1.Secret{}))
+	}
+
+	current := &api.ClusterRemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(crs), current))
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		assert.Empty(t, ts.Error)
+	}
+}
+
+func TestSelectableNamespacesPredicate(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", Labels: map[string]string{"a": "b"}}}
+
+	assert.True(t, selectableNamespacesPredicate.Create(event.CreateEvent{Object: ns}))
+	assert.True(t, selectableNamespacesPredicate.Delete(event.DeleteEvent{Object: ns}))
+
+	t.Run("status change ignored", func(t *testing.T) {
+		changed := ns.DeepCopy()
+		changed.Status.Phase = corev1.NamespaceTerminating
+		changed.ResourceVersion = "2"
+		assert.False(t, selectableNamespacesPredicate.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: changed}))
+	})
+
+	t.Run("label change", func(t *testing.T) {
+		changed := ns.DeepCopy()
+		changed.Labels["a"] = "c"
+		assert.True(t, selectableNamespacesPredicate.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: changed}))
+	})
+
+	t.Run("annotation change", func(t *testing.T) {
+		changed := ns.DeepCopy()
+		changed.Annotations = map[string]string{"team": "a"}
+		assert.True(t, selectableNamespacesPredicate.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: changed}))
+	})
+}
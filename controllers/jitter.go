@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"math/rand"
+	"time"
+
+	opconfig "github.com/redhat-appstudio/remote-secret/pkg/config"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Jitter adds a bounded random duration to the requeue intervals so that the objects that were created at the same time
+// do not get requeued in lockstep.
+type Jitter struct {
+	// MaxPercent is the upper bound of the added jitter expressed as the percentage of the base duration. Zero or negative
+	// value disables the jitter.
+	MaxPercent int
+	// Float64 returns a pseudo-random number in the half-open interval [0.0, 1.0). If nil, rand.Float64 is used.
+	// This is meant to be overridden in the tests.
+	Float64 func() float64
+}
+
+// Apply returns the provided duration prolonged by a random jitter. The result is never shorter than the provided duration
+// and never longer than the duration increased by MaxPercent.
+func (j Jitter) Apply(d time.Duration) time.Duration {
+	if j.MaxPercent <= 0 || d <= 0 {
+		return d
+	}
+
+	random := j.Float64
+	if random == nil {
+		random = rand.Float64
+	}
+
+	maxJitter := float64(d) * float64(j.MaxPercent) / 100
+	return d + time.Duration(random()*maxJitter)
+}
+
+// jitteredRateLimiter applies the jitter to the delays computed by the wrapped rate limiter. The controllers use the rate
+// limiter to compute the backoff of the failed reconciliations.
+type jitteredRateLimiter struct {
+	workqueue.RateLimiter
+	jitter Jitter
+}
+
+var _ workqueue.RateLimiter = (*jitteredRateLimiter)(nil)
+
+// newJitteredRateLimiter returns the default controller rate limiter with the jitter applied to its delays.
+func newJitteredRateLimiter(jitter Jitter) workqueue.RateLimiter {
+	return &jitteredRateLimiter{
+		RateLimiter: workqueue.DefaultControllerRateLimiter(),
+		jitter:      jitter,
+	}
+}
+
+func (l *jitteredRateLimiter) When(item interface{}) time.Duration {
+	return l.jitter.Apply(l.RateLimiter.When(item))
+}
+
+// requeueJitter returns the jitter configured in the provided operator configuration.
+func requeueJitter(cfg *opconfig.OperatorConfiguration) Jitter {
+	if cfg == nil {
+		return Jitter{}
+	}
+	return Jitter{MaxPercent: cfg.RequeueJitterPercent}
+}
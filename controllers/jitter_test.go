@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestJitter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		j := Jitter{Float64: func() float64 { return 0.5 }}
+		assert.Equal(t, 10*time.Second, j.Apply(10*time.Second))
+	})
+
+	t.Run("uses the random source", func(t *testing.T) {
+		j := Jitter{MaxPercent: 20, Float64: func() float64 { return 0.5 }}
+		assert.Equal(t, 11*time.Second, j.Apply(10*time.Second))
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		r := rand.New(rand.NewSource(42)) //#nosec G404 -- no need for a secure random in tests
+		j := Jitter{MaxPercent: 10, Float64: r.Float64}
+		for i := 0; i < 1000; i++ {
+			d := j.Apply(time.Minute)
+			assert.GreaterOrEqual(t, d, time.Minute)
+			assert.LessOrEqual(t, d, 66*time.Second)
+		}
+	})
+
+	t.Run("non-positive durations unchanged", func(t *testing.T) {
+		j := Jitter{MaxPercent: 10, Float64: func() float64 { return 0.9 }}
+		assert.Equal(t, time.Duration(0), j.Apply(0))
+	})
+}
+
+func TestJitteredRateLimiter(t *testing.T) {
+	rl := &jitteredRateLimiter{
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Second, time.Minute),
+		jitter:      Jitter{MaxPercent: 50, Float64: func() float64 { return 1 }},
+	}
+
+	assert.Equal(t, 1500*time.Millisecond, rl.When("a"))
+	assert.Equal(t, 3*time.Second, rl.When("a"))
+	assert.Equal(t, 2, rl.NumRequeues("a"))
+
+	rl.Forget("a")
+	assert.Equal(t, 0, rl.NumRequeues("a"))
+}
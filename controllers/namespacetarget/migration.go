@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespacetarget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-appstudio/remote-secret/pkg/commaseparated"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NormalizeLinkAnnotations sorts the values of the LinkedRemoteSecretsAnnotation on all the secrets and service accounts linked to
+// the remote secrets in the cluster. The marker only sorts the values when it updates the annotation for some other reason, so this
+// can be used to normalize all the annotations at once, e.g. before an upgrade. Returns the number of the updated objects.
+func NormalizeLinkAnnotations(ctx context.Context, cl client.Client) (int, error) {
+	objs, err := listLinkedObjects(ctx, cl, LinkedByRemoteSecretLabel)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, o := range objs {
+		annos := o.GetAnnotations()
+		val := commaseparated.Value(annos[LinkedRemoteSecretsAnnotation])
+		normalized := val.Sort().String()
+		if normalized == annos[LinkedRemoteSecretsAnnotation] {
+			continue
+		}
+
+		annos[LinkedRemoteSecretsAnnotation] = normalized
+		if err := cl.Update(ctx, o); err != nil {
+			return updated, fmt.Errorf("failed to normalize the annotations of %s: %w", client.ObjectKeyFromObject(o), err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// MigrateMarkerDomain moves the label and annotations linking the secrets and service accounts to the remote secrets from
+// the DefaultMarkerDomain to the provided domain in the whole cluster. This needs to be done when the domain is changed so that
+// the objects marked before are found by the markers using the new domain. Returns the number of the updated objects.
+func MigrateMarkerDomain(ctx context.Context, cl client.Client, domain string) (int, error) {
+	marker := &NamespaceObjectMarker{Domain: domain}
+	if len(marker.recognizedKeys()) == 1 {
+		return 0, nil
+	}
+
+	objs, err := listLinkedObjects(ctx, cl, LinkedByRemoteSecretLabel)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, o := range objs {
+		if !marker.moveLegacyMarks(o) {
+			continue
+		}
+
+		if err := cl.Update(ctx, o); err != nil {
+			return updated, fmt.Errorf("failed to migrate the marks of %s to the domain %s: %w", client.ObjectKeyFromObject(o), domain, err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// listLinkedObjects lists all the secrets and service accounts in the cluster labeled with the provided label.
+func listLinkedObjects(ctx context.Context, cl client.Client, label string) ([]client.Object, error) {
+	secrets := &corev1.SecretList{}
+	if err := cl.List(ctx, secrets, client.MatchingLabels{label: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list the linked secrets: %w", err)
+	}
+
+	sas := &corev1.ServiceAccountList{}
+	if err := cl.List(ctx, sas, client.MatchingLabels{label: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list the linked service accounts: %w", err)
+	}
+
+	objs := make([]client.Object, 0, len(secrets.Items)+len(sas.Items))
+	for i := range secrets.Items {
+		objs = append(objs, &secrets.Items[i])
+	}
+	for i := range sas.Items {
+		objs = append(objs, &sas.Items[i])
+	}
+
+	return objs, nil
+}
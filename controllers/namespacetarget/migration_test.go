@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespacetarget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNormalizeLinkAnnotations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	linked := func(value string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Labels:      map[string]string{LinkedByRemoteSecretLabel: "true"},
+			Annotations: map[string]string{LinkedRemoteSecretsAnnotation: value},
+		}
+	}
+
+	unsortedSecret := linked("ns/b,ns/a")
+	unsortedSecret.Name, unsortedSecret.Namespace = "unsorted", "default"
+	sortedSecret := linked("ns/a,ns/b")
+	sortedSecret.Name, sortedSecret.Namespace = "sorted", "default"
+	unsortedSA := linked("ns/d,ns/c")
+	unsortedSA.Name, unsortedSA.Namespace = "sa", "default"
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{ObjectMeta: unsortedSecret},
+		&corev1.Secret{ObjectMeta: sortedSecret},
+		&corev1.ServiceAccount{ObjectMeta: unsortedSA},
+	).Build()
+
+	updated, err := NormalizeLinkAnnotations(context.TODO(), cl)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	s := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "unsorted", Namespace: "default"}, s))
+	assert.Equal(t, "ns/a,ns/b", s.Annotations[LinkedRemoteSecretsAnnotation])
+
+	sa := &corev1.ServiceAccount{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "sa", Namespace: "default"}, sa))
+	assert.Equal(t, "ns/c,ns/d", sa.Annotations[LinkedRemoteSecretsAnnotation])
+
+	updated, err = NormalizeLinkAnnotations(context.TODO(), cl)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+}
+
+func TestMigrateMarkerDomain(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "s",
+			Namespace:   "default",
+			Labels:      map[string]string{LinkedByRemoteSecretLabel: "true"},
+			Annotations: map[string]string{LinkedRemoteSecretsAnnotation: "ns/a", ManagingRemoteSecretNameAnnotation: "ns/a"},
+		}},
+	).Build()
+
+	updated, err := MigrateMarkerDomain(context.TODO(), cl, DefaultMarkerDomain)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+
+	updated, err = MigrateMarkerDomain(context.TODO(), cl, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	s := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "s", Namespace: "default"}, s))
+	assert.Equal(t, map[string]string{"example.com/linked-by-remote-secret": "true"}, s.Labels)
+	assert.Equal(t, map[string]string{
+		"example.com/linked-remote-secrets":  "ns/a",
+		"example.com/managing-remote-secret": "ns/a",
+	}, s.Annotations)
+
+	updated, err = MigrateMarkerDomain(context.TODO(), cl, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+}
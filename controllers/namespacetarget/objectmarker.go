@@ -24,34 +24,106 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// DefaultMarkerDomain is the domain of the label and annotations the objects are marked with unless configured otherwise.
+const DefaultMarkerDomain = "appstudio.redhat.com"
+
+// The keys of the label and annotations in the DefaultMarkerDomain.
+const (
+	LinkedByRemoteSecretLabel          = DefaultMarkerDomain + "/" + linkedByRemoteSecretName //#nosec G101 -- false positive, this is just a label
+	ManagingRemoteSecretNameAnnotation = DefaultMarkerDomain + "/" + managingRemoteSecretName //#nosec G101 -- false positive, this is just a label
+	LinkedRemoteSecretsAnnotation      = DefaultMarkerDomain + "/" + linkedRemoteSecretsName  //#nosec G101 -- false positive, this is just a label
+)
+
+// The names of the label and annotations without the domain.
 const (
-	LinkedByRemoteSecretLabel          = "appstudio.redhat.com/linked-by-remote-secret" //#nosec G101 -- false positive, this is just a label
-	ManagingRemoteSecretNameAnnotation = "appstudio.redhat.com/managing-remote-secret"  //#nosec G101 -- false positive, this is just a label
-	LinkedRemoteSecretsAnnotation      = "appstudio.redhat.com/linked-remote-secrets"   //#nosec G101 -- false positive, this is just a label
+	linkedByRemoteSecretName = "linked-by-remote-secret" //#nosec G101 -- false positive, this is just a label
+	managingRemoteSecretName = "managing-remote-secret"  //#nosec G101 -- false positive, this is just a label
+	linkedRemoteSecretsName  = "linked-remote-secrets"   //#nosec G101 -- false positive, this is just a label
 )
 
+// MarkerKeys are the keys of the label and annotations the NamespaceObjectMarker marks the objects with.
+type MarkerKeys struct {
+	LinkedByRemoteSecretLabel          string
+	ManagingRemoteSecretNameAnnotation string
+	LinkedRemoteSecretsAnnotation      string
+}
+
+// MarkerKeysForDomain returns the marker keys in the provided domain. The DefaultMarkerDomain is used if the domain is empty.
+func MarkerKeysForDomain(domain string) MarkerKeys {
+	if domain == "" {
+		domain = DefaultMarkerDomain
+	}
+	return MarkerKeys{
+		LinkedByRemoteSecretLabel:          domain + "/" + linkedByRemoteSecretName,
+		ManagingRemoteSecretNameAnnotation: domain + "/" + managingRemoteSecretName,
+		LinkedRemoteSecretsAnnotation:      domain + "/" + linkedRemoteSecretsName,
+	}
+}
+
+// NamespaceObjectMarker marks the objects in the target namespaces using the labels and annotations. The annotation values identify
+// the remote secret and, for the targets in the remote clusters, also the API URL of the cluster, e.g.
+// "ns/name@https://api.cluster:6443". This makes the markings of the targets with the same namespace in different clusters distinct even
+// if the clusters are actually the same.
+//
+// The label and annotations are in the configured domain. The objects marked in the DefaultMarkerDomain are still recognized by
+// the markers configured with a different domain and their marks are moved to the configured domain whenever the marker updates
+// them. Note though that the objects marked in the DefaultMarkerDomain are not matched by the list options returned from the marker
+// and need to be migrated using the MigrateMarkerDomain.
 type NamespaceObjectMarker struct {
+	// ApiUrl is the API URL of the cluster of the target. It is empty for the local cluster.
+	ApiUrl string
+	// Domain is the domain of the label and annotations the objects are marked with. The DefaultMarkerDomain is used if empty.
+	Domain string
 }
 
+// apiUrlSeparator separates the key of the remote secret from the API URL of the cluster in the annotation values.
+const apiUrlSeparator = "@"
+
 var _ bindings.ObjectMarker = (*NamespaceObjectMarker)(nil)
 
 // IsManaged implements bindings.ObjectMarker
 func (m *NamespaceObjectMarker) IsManagedBy(ctx context.Context, rs client.ObjectKey, obj client.Object) (bool, error) {
 	annos := obj.GetAnnotations()
 	refed, _ := m.IsReferencedBy(ctx, rs, obj)
-	return refed && annos[ManagingRemoteSecretNameAnnotation] == rs.String(), nil
+	if !refed {
+		return false, nil
+	}
+	for _, keys := range m.recognizedKeys() {
+		if m.isLink(rs, annos[keys.ManagingRemoteSecretNameAnnotation]) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // IsReferenced implements bindings.ObjectMarker
 func (m *NamespaceObjectMarker) IsReferencedBy(ctx context.Context, rs client.ObjectKey, obj client.Object) (bool, error) {
 	annos := obj.GetAnnotations()
 	labels := obj.GetLabels()
+	legacy := m.legacyLink(rs)
 
-	if labels[LinkedByRemoteSecretLabel] != "true" {
-		return false, nil
+	for _, keys := range m.recognizedKeys() {
+		if labels[keys.LinkedByRemoteSecretLabel] != "true" {
+			continue
+		}
+
+		val := commaseparated.Value(annos[keys.LinkedRemoteSecretsAnnotation])
+		if val.Contains(m.link(rs)) || (legacy != "" && val.Contains(legacy)) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	return commaseparated.Value(annos[LinkedRemoteSecretsAnnotation]).Contains(rs.String()), nil
+// IsMarked returns true if the provided object is labeled as linked to some remote secret.
+func (m *NamespaceObjectMarker) IsMarked(obj client.Object) bool {
+	labels := obj.GetLabels()
+	for _, keys := range m.recognizedKeys() {
+		if labels[keys.LinkedByRemoteSecretLabel] == "true" {
+			return true
+		}
+	}
+	return false
 }
 
 // ListManagedOptions implements bindings.ObjectMarker
@@ -63,7 +135,7 @@ func (m *NamespaceObjectMarker) ListManagedOptions(ctx context.Context, rs clien
 func (m *NamespaceObjectMarker) ListReferencedOptions(ctx context.Context, rs client.ObjectKey) ([]client.ListOption, error) {
 	return []client.ListOption{
 		client.MatchingLabels{
-			LinkedByRemoteSecretLabel: "true",
+			m.keys().LinkedByRemoteSecretLabel: "true",
 		},
 	}, nil
 }
@@ -72,7 +144,8 @@ func (m *NamespaceObjectMarker) ListReferencedOptions(ctx context.Context, rs cl
 func (m *NamespaceObjectMarker) MarkManaged(ctx context.Context, rs client.ObjectKey, obj client.Object) (bool, error) {
 	refChanged, _ := m.MarkReferenced(ctx, rs, obj)
 
-	value := rs.String()
+	keys := m.keys()
+	value := m.link(rs)
 	shouldChange := false
 	annos := obj.GetAnnotations()
 
@@ -81,11 +154,11 @@ func (m *NamespaceObjectMarker) MarkManaged(ctx context.Context, rs client.Objec
 		obj.SetAnnotations(annos)
 		shouldChange = true
 	} else {
-		shouldChange = annos[ManagingRemoteSecretNameAnnotation] != value
+		shouldChange = annos[keys.ManagingRemoteSecretNameAnnotation] != value
 	}
 
 	if shouldChange {
-		annos[ManagingRemoteSecretNameAnnotation] = value
+		annos[keys.ManagingRemoteSecretNameAnnotation] = value
 	}
 
 	return refChanged || shouldChange, nil
@@ -93,6 +166,9 @@ func (m *NamespaceObjectMarker) MarkManaged(ctx context.Context, rs client.Objec
 
 // MarkReferenced implements bindings.ObjectMarker
 func (m *NamespaceObjectMarker) MarkReferenced(ctx context.Context, rs client.ObjectKey, obj client.Object) (bool, error) {
+	keys := m.keys()
+	moved := m.moveLegacyMarks(obj)
+
 	shouldChange := false
 	labels := obj.GetLabels()
 	if labels == nil {
@@ -100,10 +176,10 @@ func (m *NamespaceObjectMarker) MarkReferenced(ctx context.Context, rs client.Ob
 		obj.SetLabels(labels)
 		shouldChange = true
 	} else {
-		shouldChange = labels[LinkedByRemoteSecretLabel] != "true"
+		shouldChange = labels[keys.LinkedByRemoteSecretLabel] != "true"
 	}
 
-	labels[LinkedByRemoteSecretLabel] = "true"
+	labels[keys.LinkedByRemoteSecretLabel] = "true"
 
 	annos := obj.GetAnnotations()
 	if annos == nil {
@@ -112,34 +188,45 @@ func (m *NamespaceObjectMarker) MarkReferenced(ctx context.Context, rs client.Ob
 		shouldChange = true
 	}
 
-	link := rs.String()
+	link := m.link(rs)
 
-	val := commaseparated.Value(annos[LinkedRemoteSecretsAnnotation])
+	val := commaseparated.Value(annos[keys.LinkedRemoteSecretsAnnotation])
 	shouldChange = !val.Contains(link) || shouldChange
 
+	// upgrade the values written before the API URL was part of them
+	if legacy := m.legacyLink(rs); legacy != "" && val.Contains(legacy) {
+		val.Remove(legacy)
+		shouldChange = true
+	}
+
 	if shouldChange {
+		// the values are only sorted when the annotation is written anyway, so that the values written by the older versions
+		// don't cause all the objects to be updated at once.
 		val.Add(link)
-		annos[LinkedRemoteSecretsAnnotation] = val.String()
+		annos[keys.LinkedRemoteSecretsAnnotation] = val.Sort().String()
 	}
 
-	return shouldChange, nil
+	return shouldChange || moved, nil
 }
 
 // UnmarkManaged implements bindings.ObjectMarker
 func (m *NamespaceObjectMarker) UnmarkManaged(ctx context.Context, rs client.ObjectKey, obj client.Object) (bool, error) {
+	moved := m.moveLegacyMarks(obj)
+
 	annos := obj.GetAnnotations()
 	if annos == nil {
-		return false, nil
+		return moved, nil
 	}
 
-	val := annos[ManagingRemoteSecretNameAnnotation]
+	keys := m.keys()
+	val := annos[keys.ManagingRemoteSecretNameAnnotation]
 
-	if val == rs.String() {
-		delete(annos, ManagingRemoteSecretNameAnnotation)
+	if m.isLink(rs, val) {
+		delete(annos, keys.ManagingRemoteSecretNameAnnotation)
 		return true, nil
 	}
 
-	return false, nil
+	return moved, nil
 }
 
 // UnmarkReferenced implements bindings.ObjectMarker
@@ -151,37 +238,47 @@ func (m *NamespaceObjectMarker) UnmarkReferenced(ctx context.Context, rs client.
 		return wasManaged, nil
 	}
 
-	link := rs.String()
+	keys := m.keys()
+	link := m.link(rs)
+	legacy := m.legacyLink(rs)
 
-	val := commaseparated.Value(annos[LinkedRemoteSecretsAnnotation])
-	containsLink := val.Contains(link)
+	val := commaseparated.Value(annos[keys.LinkedRemoteSecretsAnnotation])
+	containsLink := val.Contains(link) || (legacy != "" && val.Contains(legacy))
 
 	if containsLink {
 		val.Remove(link)
+		if legacy != "" {
+			val.Remove(legacy)
+		}
 	}
 
 	unlabeled := false
 	if val.Len() == 0 {
 		labels := obj.GetLabels()
-		if labels != nil && labels[LinkedByRemoteSecretLabel] == "true" {
-			delete(labels, LinkedByRemoteSecretLabel)
+		if labels != nil && labels[keys.LinkedByRemoteSecretLabel] == "true" {
+			delete(labels, keys.LinkedByRemoteSecretLabel)
 			unlabeled = true
 		}
-		delete(annos, LinkedRemoteSecretsAnnotation)
-	} else {
-		annos[LinkedRemoteSecretsAnnotation] = val.String()
+		delete(annos, keys.LinkedRemoteSecretsAnnotation)
+	} else if containsLink {
+		annos[keys.LinkedRemoteSecretsAnnotation] = val.Sort().String()
 	}
 
 	return unlabeled || wasManaged || containsLink, nil
 }
 
-// GetReferencingTargets implements bindings.ObjectMarker
-func (*NamespaceObjectMarker) GetReferencingTargets(ctx context.Context, obj client.Object) ([]types.NamespacedName, error) {
-	val := commaseparated.Value(obj.GetAnnotations()[LinkedRemoteSecretsAnnotation])
+// GetReferencingTargets implements bindings.ObjectMarker. The returned keys are the keys of the remote secrets referencing the object
+// regardless of the API URLs of the targets the object has been marked by.
+func (m *NamespaceObjectMarker) GetReferencingTargets(ctx context.Context, obj client.Object) ([]types.NamespacedName, error) {
+	val := commaseparated.Empty()
+	for _, keys := range m.recognizedKeys() {
+		val.Add(obj.GetAnnotations()[keys.LinkedRemoteSecretsAnnotation])
+	}
 
 	ret := make([]types.NamespacedName, val.Len())
 
 	for i, v := range val.Values() {
+		v, _, _ = strings.Cut(v, apiUrlSeparator)
 		names := strings.Split(v, string(types.Separator))
 		ret[i].Name = names[1]
 		ret[i].Namespace = names[0]
@@ -189,3 +286,78 @@ func (*NamespaceObjectMarker) GetReferencingTargets(ctx context.Context, obj cli
 
 	return ret, nil
 }
+
+// link returns the value identifying the remote secret with the provided key in the annotations written by this marker.
+func (m *NamespaceObjectMarker) link(rs client.ObjectKey) string {
+	if m.ApiUrl == "" {
+		return rs.String()
+	}
+	return rs.String() + apiUrlSeparator + m.ApiUrl
+}
+
+// legacyLink returns the value identifying the remote secret with the provided key in the annotations written before the API URL
+// became part of the values. These are only recognized by the markers of the remote targets, because the local ones still use them.
+// An empty string is returned for the local targets.
+func (m *NamespaceObjectMarker) legacyLink(rs client.ObjectKey) string {
+	if m.ApiUrl == "" {
+		return ""
+	}
+	return rs.String()
+}
+
+// isLink checks whether the provided value identifies the remote secret with the provided key, either in the current or the legacy format.
+func (m *NamespaceObjectMarker) isLink(rs client.ObjectKey, value string) bool {
+	return value == m.link(rs) || (m.ApiUrl != "" && value == m.legacyLink(rs))
+}
+
+// keys returns the keys of the label and annotations in the configured domain.
+func (m *NamespaceObjectMarker) keys() MarkerKeys {
+	return MarkerKeysForDomain(m.Domain)
+}
+
+// recognizedKeys returns the keys in the configured domain followed by the keys in the DefaultMarkerDomain, if different.
+func (m *NamespaceObjectMarker) recognizedKeys() []MarkerKeys {
+	keys := m.keys()
+	if keys.LinkedByRemoteSecretLabel == LinkedByRemoteSecretLabel {
+		return []MarkerKeys{keys}
+	}
+	return []MarkerKeys{keys, MarkerKeysForDomain(DefaultMarkerDomain)}
+}
+
+// moveLegacyMarks moves the label and annotations in the DefaultMarkerDomain to the configured domain. Returns true if the object
+// was modified.
+func (m *NamespaceObjectMarker) moveLegacyMarks(obj client.Object) bool {
+	keys := m.recognizedKeys()
+	if len(keys) == 1 {
+		return false
+	}
+	current, legacy := keys[0], keys[1]
+
+	changed := false
+	labels := obj.GetLabels()
+	if v, ok := labels[legacy.LinkedByRemoteSecretLabel]; ok {
+		if v == "true" {
+			labels[current.LinkedByRemoteSecretLabel] = v
+		}
+		delete(labels, legacy.LinkedByRemoteSecretLabel)
+		changed = true
+	}
+
+	annos := obj.GetAnnotations()
+	if v, ok := annos[legacy.LinkedRemoteSecretsAnnotation]; ok {
+		val := commaseparated.Value(annos[current.LinkedRemoteSecretsAnnotation])
+		val.Add(v)
+		annos[current.LinkedRemoteSecretsAnnotation] = val.Sort().String()
+		delete(annos, legacy.LinkedRemoteSecretsAnnotation)
+		changed = true
+	}
+	if v, ok := annos[legacy.ManagingRemoteSecretNameAnnotation]; ok {
+		if annos[current.ManagingRemoteSecretNameAnnotation] == "" {
+			annos[current.ManagingRemoteSecretNameAnnotation] = v
+		}
+		delete(annos, legacy.ManagingRemoteSecretNameAnnotation)
+		changed = true
+	}
+
+	return changed
+}
@@ -22,6 +22,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -256,7 +257,7 @@ func TestMarkManaged(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "true", obj.Labels[LinkedByRemoteSecretLabel])
 		assert.Equal(t, "ns/k", obj.Annotations[ManagingRemoteSecretNameAnnotation])
-		assert.Equal(t, "ns/l,ns/k", obj.Annotations[LinkedRemoteSecretsAnnotation])
+		assert.Equal(t, "ns/k,ns/l", obj.Annotations[LinkedRemoteSecretsAnnotation])
 		assert.Len(t, obj.Labels, 1)
 		assert.Len(t, obj.Annotations, 2)
 		assert.True(t, changed)
@@ -314,7 +315,7 @@ func TestNamespaceObjectMarker_MarkReferenced(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, "true", obj.Labels[LinkedByRemoteSecretLabel])
-		assert.Equal(t, "ns/l,ns/k", obj.Annotations[LinkedRemoteSecretsAnnotation])
+		assert.Equal(t, "ns/k,ns/l", obj.Annotations[LinkedRemoteSecretsAnnotation])
 		assert.Len(t, obj.Labels, 1)
 		assert.Len(t, obj.Annotations, 1)
 		assert.True(t, res)
@@ -454,3 +455,149 @@ func TestNamespaceObjectMarker_GetReferencingTargets(t *testing.T) {
 	assert.Equal(t, "m", res[2].Name)
 	assert.Equal(t, "ns", res[2].Namespace)
 }
+
+func TestNamespaceObjectMarker_RemoteCluster(t *testing.T) {
+	remote := NamespaceObjectMarker{ApiUrl: "https://remote.cluster:6443"}
+	local := NamespaceObjectMarker{}
+	rs := client.ObjectKey{Name: "rs", Namespace: "ns"}
+
+	t.Run("marks with the api url", func(t *testing.T) {
+		obj := &corev1.ConfigMap{}
+		changed, err := remote.MarkManaged(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "ns/rs@https://remote.cluster:6443", obj.Annotations[LinkedRemoteSecretsAnnotation])
+		assert.Equal(t, "ns/rs@https://remote.cluster:6443", obj.Annotations[ManagingRemoteSecretNameAnnotation])
+
+		managed, _ := remote.IsManagedBy(context.TODO(), rs, obj)
+		assert.True(t, managed)
+
+		// the local target with the same namespace is distinct
+		refed, _ := local.IsReferencedBy(context.TODO(), rs, obj)
+		assert.False(t, refed)
+
+		targets, err := remote.GetReferencingTargets(context.TODO(), obj)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.NamespacedName{rs}, targets)
+	})
+
+	t.Run("upgrades legacy values", func(t *testing.T) {
+		obj := &corev1.ConfigMap{}
+		_, err := local.MarkManaged(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+
+		managed, _ := remote.IsManagedBy(context.TODO(), rs, obj)
+		assert.True(t, managed)
+
+		changed, err := remote.MarkManaged(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "ns/rs@https://remote.cluster:6443", obj.Annotations[LinkedRemoteSecretsAnnotation])
+		assert.Equal(t, "ns/rs@https://remote.cluster:6443", obj.Annotations[ManagingRemoteSecretNameAnnotation])
+	})
+
+	t.Run("unmarks legacy values", func(t *testing.T) {
+		obj := &corev1.ConfigMap{}
+		_, err := local.MarkManaged(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+
+		changed, err := remote.UnmarkReferenced(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.NotContains(t, obj.Annotations, LinkedRemoteSecretsAnnotation)
+		assert.NotContains(t, obj.Annotations, ManagingRemoteSecretNameAnnotation)
+		assert.NotContains(t, obj.Labels, LinkedByRemoteSecretLabel)
+	})
+}
+
+func TestNamespaceObjectMarker_SortsLinksOnWrite(t *testing.T) {
+	m := NamespaceObjectMarker{}
+	newObj := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{LinkedByRemoteSecretLabel: "true"},
+				Annotations: map[string]string{LinkedRemoteSecretsAnnotation: "ns/z,ns/y"},
+			},
+		}
+	}
+
+	t.Run("unchanged values are kept", func(t *testing.T) {
+		o := newObj()
+		changed, err := m.MarkReferenced(context.TODO(), client.ObjectKey{Name: "z", Namespace: "ns"}, o)
+		assert.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, "ns/z,ns/y", o.Annotations[LinkedRemoteSecretsAnnotation])
+	})
+
+	t.Run("sorted when marking", func(t *testing.T) {
+		o := newObj()
+		changed, err := m.MarkReferenced(context.TODO(), client.ObjectKey{Name: "x", Namespace: "ns"}, o)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "ns/x,ns/y,ns/z", o.Annotations[LinkedRemoteSecretsAnnotation])
+	})
+
+	t.Run("sorted when unmarking", func(t *testing.T) {
+		o := newObj()
+		o.Annotations[LinkedRemoteSecretsAnnotation] = "ns/z,ns/x,ns/y"
+		changed, err := m.UnmarkReferenced(context.TODO(), client.ObjectKey{Name: "x", Namespace: "ns"}, o)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "ns/y,ns/z", o.Annotations[LinkedRemoteSecretsAnnotation])
+	})
+}
+
+func TestNamespaceObjectMarker_Domain(t *testing.T) {
+	m := NamespaceObjectMarker{Domain: "example.com"}
+	rs := client.ObjectKey{Name: "k", Namespace: "ns"}
+	legacy := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{LinkedByRemoteSecretLabel: "true"},
+				Annotations: map[string]string{LinkedRemoteSecretsAnnotation: "ns/k,ns/l", ManagingRemoteSecretNameAnnotation: "ns/k"},
+			},
+		}
+	}
+
+	t.Run("marks in the domain", func(t *testing.T) {
+		obj := &corev1.Secret{}
+		changed, err := m.MarkManaged(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, map[string]string{"example.com/linked-by-remote-secret": "true"}, obj.Labels)
+		assert.Equal(t, map[string]string{
+			"example.com/linked-remote-secrets":  "ns/k",
+			"example.com/managing-remote-secret": "ns/k",
+		}, obj.Annotations)
+
+		opts, err := m.ListReferencedOptions(context.TODO(), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, []client.ListOption{client.MatchingLabels{"example.com/linked-by-remote-secret": "true"}}, opts)
+	})
+
+	t.Run("recognizes the default domain", func(t *testing.T) {
+		obj := legacy()
+		assert.True(t, m.IsMarked(obj))
+		refed, _ := m.IsReferencedBy(context.TODO(), rs, obj)
+		assert.True(t, refed)
+		managed, _ := m.IsManagedBy(context.TODO(), rs, obj)
+		assert.True(t, managed)
+		refs, _ := m.GetReferencingTargets(context.TODO(), obj)
+		assert.Equal(t, []types.NamespacedName{{Name: "k", Namespace: "ns"}, {Name: "l", Namespace: "ns"}}, refs)
+	})
+
+	t.Run("moves the default domain marks on write", func(t *testing.T) {
+		obj := legacy()
+		changed, err := m.UnmarkReferenced(context.TODO(), rs, obj)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, map[string]string{"example.com/linked-by-remote-secret": "true"}, obj.Labels)
+		assert.Equal(t, map[string]string{"example.com/linked-remote-secrets": "ns/l"}, obj.Annotations)
+	})
+
+	t.Run("default marker ignores other domains", func(t *testing.T) {
+		obj := &corev1.Secret{}
+		_, _ = m.MarkManaged(context.TODO(), rs, obj)
+		assert.False(t, (&NamespaceObjectMarker{}).IsMarked(obj))
+	})
+}
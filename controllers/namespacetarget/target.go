@@ -31,8 +31,18 @@ type NamespaceTarget struct {
 
 var _ bindings.SecretDeploymentTarget = (*NamespaceTarget)(nil)
 
+// GetSpec returns the secret spec with the type and immutability overridden by the target spec, if any.
 func (t *NamespaceTarget) GetSpec() api.LinkableSecretSpec {
-	return *t.SecretSpec
+	spec := *t.SecretSpec
+	if t.TargetSpec != nil {
+		if t.TargetSpec.Type != "" {
+			spec.Type = t.TargetSpec.Type
+		}
+		if t.TargetSpec.Immutable != nil {
+			spec.Immutable = t.TargetSpec.Immutable
+		}
+	}
+	return spec
 }
 
 func (t *NamespaceTarget) GetClient() client.Client {
@@ -75,6 +85,14 @@ func (t *NamespaceTarget) GetActualServiceAccountNames() []string {
 	}
 }
 
+func (t *NamespaceTarget) GetActualConfigMapName() string {
+	if t.TargetStatus == nil {
+		return ""
+	} else {
+		return t.TargetStatus.ConfigMapName
+	}
+}
+
 func (t *NamespaceTarget) GetType() string {
 	return "Namespace"
 }
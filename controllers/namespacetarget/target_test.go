@@ -19,7 +19,9 @@ import (
 
 	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -56,6 +58,20 @@ func TestNamespaceTarget_GetSpec(t *testing.T) {
 	}, bt.GetSpec())
 }
 
+func TestNamespaceTarget_GetSpecOverrides(t *testing.T) {
+	bt := getTestNamespaceTarget()
+	bt.TargetSpec.Type = corev1.SecretTypeTLS
+	bt.TargetSpec.Immutable = pointer.Bool(true)
+
+	assert.Equal(t, api.LinkableSecretSpec{
+		GenerateName: "kachny-",
+		Type:         corev1.SecretTypeTLS,
+		Immutable:    pointer.Bool(true),
+	}, bt.GetSpec())
+	// the overrides don't leak into the shared secret spec
+	assert.Empty(t, bt.SecretSpec.Type)
+}
+
 func TestNamespaceTarget_GetTargetNamespace(t *testing.T) {
 	bt := getTestNamespaceTarget()
 	assert.Equal(t, "target-ns", bt.GetTargetNamespace())
@@ -19,6 +19,7 @@ import (
 	stdErrors "errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/redhat-appstudio/remote-secret/pkg/rerror"
@@ -39,10 +40,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/finalizer"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -51,19 +56,53 @@ var (
 	unexpectedObjectTypeError = stdErrors.New("unexpected object type")
 )
 
-const linkedObjectsFinalizerName = "appstudio.redhat.com/linked-objects"
+const (
+	linkedObjectsFinalizerName = "appstudio.redhat.com/linked-objects"
+
+	// forbiddenTargetsRequeueDelay is the delay after which the deployment is retried if it failed only because the operator is
+	// not allowed to write to the targets. Retrying sooner doesn't help until the RBAC is fixed.
+	forbiddenTargetsRequeueDelay = 10 * time.Minute
+
+	// optionalTargetsRequeueDelay is the delay after which the deployment is retried if it failed only for the optional targets.
+	// The optional targets are expected to be unavailable for long periods of time, so they are not retried aggressively.
+	optionalTargetsRequeueDelay = 10 * time.Minute
+)
 
 type RemoteSecretReconciler struct {
 	client.Client
 	Scheme              *runtime.Scheme
 	Configuration       *opconfig.OperatorConfiguration
 	RemoteSecretStorage remotesecretstorage.RemoteSecretStorage
-	finalizers          finalizer.Finalizers
+	// DataStores is the registry of the data stores to obtain the secret data from. If nil, only the local data store using
+	// the RemoteSecretStorage is available.
+	DataStores *remotesecrets.DataStoreRegistry[*api.RemoteSecret]
+	// DataCache is the optional cache of the secret data shared by the data stores created by the reconciler. It is not used
+	// if the DataStores are configured explicitly.
+	DataCache *remotesecrets.DataCache
+	// CircuitBreaker is the optional circuit breaker of the data stores created by the reconciler. It is not used if the DataStores
+	// are configured explicitly.
+	CircuitBreaker *remotesecrets.CircuitBreaker
+	// RemoteClients creates the clients of the targets in the remote clusters. If nil, the clients are created using the cluster
+	// credentials secrets referenced by the targets.
+	RemoteClients remotesecrets.RemoteClientFactory
+	// ClusterRegistry looks up the remote clusters referenced by the targets by name. If nil, the clusters are looked up using
+	// the remotesecrets.SecretClusterRegistry.
+	ClusterRegistry remotesecrets.ClusterRegistry
+	// Coalescer optionally batches the contributions of the remote secrets to the same secret in the local cluster.
+	Coalescer *bindings.ContributionCoalescer
+	// LocalApiUrl is the URL of the API server of the cluster the reconciler runs in. The targets with the API URL pointing
+	// at it are refused.
+	LocalApiUrl string
+	// ErrorLogSuppressor optionally suppresses logging the same deployment errors over and over again.
+	ErrorLogSuppressor *logs.RepeatSuppressor
+	finalizers         finalizer.Finalizers
 }
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=remotesecrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=remotesecrets/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=remotesecrets/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets,verbs=get;list;watch
 
 var _ reconcile.Reconciler = (*RemoteSecretReconciler)(nil)
 
@@ -74,17 +113,33 @@ func (r *RemoteSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := r.finalizers.Register(storageFinalizerName, &remoteSecretStorageFinalizer{storage: r.RemoteSecretStorage}); err != nil {
 		return fmt.Errorf("failed to register the remote secret storage finalizer: %w", err)
 	}
-	if err := r.finalizers.Register(linkedObjectsFinalizerName, &remoteSecretLinksFinalizer{client: r.Client, storage: r.RemoteSecretStorage}); err != nil {
+	if err := r.finalizers.Register(linkedObjectsFinalizerName, &remoteSecretLinksFinalizer{client: r.Client, dataStores: r.dataStores(), markerDomain: markerDomain(r.Configuration)}); err != nil {
 		return fmt.Errorf("failed to register the remote secret links finalizer: %w", err)
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &api.RemoteSecret{}, remotesecrets.DataSourceIndexField, func(o client.Object) []string {
+		return remotesecrets.DataSourceIndexValues(o.(*api.RemoteSecret))
+	}); err != nil {
+		return fmt.Errorf("failed to configure the data source index of the remote secrets: %w", err)
+	}
+
+	marker := &namespacetarget.NamespaceObjectMarker{Domain: markerDomain(r.Configuration)}
 	err := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{RateLimiter: newJitteredRateLimiter(requeueJitter(r.Configuration))}).
 		For(&api.RemoteSecret{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.failedTargetsInNamespaceRequests(mgr.GetLogger(), o.GetName())
+		}), builder.WithPredicates(createdObjectsPredicate)).
 		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
-			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), o)
-		})).
+			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), marker, o, false)
+		}), builder.WithPredicates(linkedObjectsPredicate(marker))).
 		Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
-			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), o)
+			return linksToReconcileRequests(mgr.GetLogger(), mgr.GetScheme(), marker, o, false)
+		}), builder.WithPredicates(linkedObjectsPredicate(marker))).
+		// the data sources are watched so that the changes of their data, e.g. the secrets refreshed by the External Secrets
+		// Operator, are propagated to the targets
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.dataSourceRequests(mgr.GetLogger(), o)
 		})).
 		Complete(r)
 	if err != nil {
@@ -93,10 +148,97 @@ func (r *RemoteSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
-func linksToReconcileRequests(lg logr.Logger, scheme *runtime.Scheme, o client.Object) []reconcile.Request {
-	nsMarker := namespacetarget.NamespaceObjectMarker{}
+// dataSourceRequests returns the reconcile requests for the remote secrets copying the data from the provided secret.
+func (r *RemoteSecretReconciler) dataSourceRequests(lg logr.Logger, o client.Object) []reconcile.Request {
+	secret, ok := o.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
 
-	refs, err := nsMarker.GetReferencingTargets(context.Background(), o)
+	// the remote secrets are about to be reconciled because of the change of the data, so they must not be served the stale data
+	for _, key := range remotesecrets.DataSourceCacheKeys(secret) {
+		r.dataStores().Cache.InvalidateKey(key)
+	}
+
+	keys, err := remotesecrets.RemoteSecretsCopyingFrom(context.Background(), r.Client, secret)
+	if err != nil {
+		lg.Error(err, "failed to find the remote secrets copying the data from a secret", "secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(keys))
+	for _, key := range keys {
+		reqs = append(reqs, reconcile.Request{NamespacedName: key})
+	}
+	return reqs
+}
+
+// createdObjectsPredicate only lets through the create events.
+var createdObjectsPredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(event.UpdateEvent) bool {
+		return false
+	},
+	DeleteFunc: func(event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(event.GenericEvent) bool {
+		return false
+	},
+}
+
+// failedTargetsInNamespaceRequests returns the reconcile requests for the remote secrets that failed to deploy to the provided namespace
+// in the local cluster. This is used when a namespace is created so that we don't need to wait for the backoff to deploy to it.
+func (r *RemoteSecretReconciler) failedTargetsInNamespaceRequests(lg logr.Logger, namespace string) []reconcile.Request {
+	list := &api.RemoteSecretList{}
+	if err := r.Client.List(context.Background(), list); err != nil {
+		lg.Error(err, "failed to list the remote secrets while processing a namespace creation", "namespace", namespace)
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for i := range list.Items {
+		for _, ts := range list.Items[i].Status.Targets {
+			if ts.ApiUrl == "" && ts.Namespace == namespace && ts.Error != "" {
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+				break
+			}
+		}
+	}
+
+	return reqs
+}
+
+// linkedObjectsPredicate only lets through the events on the objects that are labeled as linked to some remote secret. The update
+// events are let through if either the old or the new object is labeled so that we notice when the label is removed from the object.
+// The labels are recognized using the provided marker.
+func linkedObjectsPredicate(marker *namespacetarget.NamespaceObjectMarker) predicate.Funcs {
+	isLinkedToRemoteSecret := func(o client.Object) bool {
+		return o != nil && marker.IsMarked(o)
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isLinkedToRemoteSecret(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isLinkedToRemoteSecret(e.ObjectOld) || isLinkedToRemoteSecret(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isLinkedToRemoteSecret(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isLinkedToRemoteSecret(e.Object)
+		},
+	}
+}
+
+// linksToReconcileRequests converts the remote secrets referenced by the provided object to reconcile requests. If clusterScoped is true, only the references
+// to the cluster-scoped objects (i.e. ClusterRemoteSecrets) are returned, otherwise only the references to the namespaced objects are returned.
+func linksToReconcileRequests(lg logr.Logger, scheme *runtime.Scheme, marker *namespacetarget.NamespaceObjectMarker, o client.Object, clusterScoped bool) []reconcile.Request {
+	refs, err := marker.GetReferencingTargets(context.Background(), o)
 	if err != nil {
 		var gvk schema.GroupVersionKind
 		gvks, _, _ := scheme.ObjectKinds(o)
@@ -106,9 +248,11 @@ func linksToReconcileRequests(lg logr.Logger, scheme *runtime.Scheme, o client.O
 		lg.Error(err, "failed to list the referencing targets of the object", "objectKey", client.ObjectKeyFromObject(o), "gvk", gvk)
 	}
 
-	reqs := make([]reconcile.Request, len(refs))
-	for i, r := range refs {
-		reqs[i].NamespacedName = r
+	reqs := make([]reconcile.Request, 0, len(refs))
+	for _, r := range refs {
+		if (r.Namespace == "") == clusterScoped {
+			reqs = append(reqs, reconcile.Request{NamespacedName: r})
+		}
 	}
 
 	return reqs
@@ -154,14 +298,33 @@ func (r *RemoteSecretReconciler) Reconcile(ctx context.Context, req reconcile.Re
 		return ctrl.Result{}, nil
 	}
 
+	if result, suspended, err := handleSuspension(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, &remoteSecret.Spec, requeueJitter(r.Configuration)); err != nil || suspended {
+		return result, err
+	}
+
 	// the reconciliation happens in stages, results of which are described in the status conditions.
 
-	dataResult, err := handleStage(ctx, r.Client, remoteSecret, r.obtainData(ctx, remoteSecret))
+	if forceSyncRequested(remoteSecret, &remoteSecret.Status) {
+		// the forced re-sync must not be served stale data
+		r.dataStores().InvalidateCache(remoteSecret)
+	}
+
+	dataSources := remotesecrets.DataSourcesOf(&remoteSecret.Spec)
+	dataResult, err := handleStage(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, obtainData(ctx, &remoteSecret.Status, dataSources.Ref(), func(ctx context.Context) (*remotesecretstorage.SecretData, error) {
+		return r.dataStores().GetMerged(ctx, dataSources, remoteSecret) //nolint:wrapcheck // the error is only stored in the condition
+	}))
 	if err != nil || dataResult.Cancellation.Cancel {
 		return dataResult.Cancellation.Result, err
 	}
 
-	deployResult, err := handleStage(ctx, r.Client, remoteSecret, r.deploy(ctx, remoteSecret, dataResult.ReturnValue))
+	// never deploy to the targets without the data. If the data disappeared from the storage, we leave the existing
+	// target secrets intact rather than overwriting them with empty data.
+	if !meta.IsStatusConditionTrue(remoteSecret.Status.Conditions, string(api.RemoteSecretConditionTypeDataObtained)) {
+		lg.V(logs.DebugLevel).Info("the data of the remote secret not obtained. skipping the deployment to targets")
+		return ctrl.Result{}, nil
+	}
+
+	deployResult, err := handleStage(ctx, r.Client, remoteSecret, &remoteSecret.Status.Conditions, deploy(ctx, r.newTargetsProcessor(remoteSecret)))
 	if err != nil || deployResult.Cancellation.Cancel {
 		return deployResult.Cancellation.Result, err
 	}
@@ -191,10 +354,11 @@ type cancellation struct {
 }
 
 // handleStage tries to update the status with the condition from the provided result and returns error if the update failed or the stage itself failed before.
-func handleStage[T any](ctx context.Context, cl client.Client, remoteSecret *api.RemoteSecret, result stageResult[T]) (stageResult[T], error) {
-	meta.SetStatusCondition(&remoteSecret.Status.Conditions, result.Condition)
+// The conditions are expected to be the conditions in the status of the provided object.
+func handleStage[T any](ctx context.Context, cl client.Client, obj client.Object, conditions *[]metav1.Condition, result stageResult[T]) (stageResult[T], error) {
+	meta.SetStatusCondition(conditions, result.Condition)
 
-	if serr := cl.Status().Update(ctx, remoteSecret); serr != nil {
+	if serr := cl.Status().Update(ctx, obj); serr != nil {
 		return result, fmt.Errorf("failed to persist the stage result condition in the status after the stage %v: %w", result, serr)
 	}
 
@@ -205,13 +369,57 @@ func handleStage[T any](ctx context.Context, cl client.Client, remoteSecret *api
 	}
 }
 
-// obtainData tries to find the data of the remote secret in the backing storage.
-func (r *RemoteSecretReconciler) obtainData(ctx context.Context, remoteSecret *api.RemoteSecret) stageResult[*remotesecretstorage.SecretData] {
+// handleSuspension records the suspension of the provided object in its status conditions. The object is suspended either explicitly
+// or until the NotBefore time of its spec. It returns true if the object is suspended and therefore should not be reconciled any
+// further, together with the result scheduling the reconciliation at the NotBefore time prolonged by the provided jitter. The conditions
+// are expected to be the conditions in the status of the provided object.
+func handleSuspension(ctx context.Context, cl client.Client, obj client.Object, conditions *[]metav1.Condition, spec *api.RemoteSecretSpec, jitter Jitter) (ctrl.Result, bool, error) {
+	result := ctrl.Result{}
+	condition := metav1.Condition{
+		Type:   string(api.RemoteSecretConditionTypeSuspended),
+		Status: metav1.ConditionTrue,
+	}
+
+	if spec.Suspend {
+		condition.Reason = string(api.RemoteSecretReasonSuspended)
+		condition.Message = "The reconciliation is suspended. The secrets in the targets are left intact."
+	} else if spec.NotBefore != nil && time.Now().Before(spec.NotBefore.Time) {
+		condition.Reason = string(api.RemoteSecretReasonNotBefore)
+		condition.Message = fmt.Sprintf("The deployment to the targets is delayed until %s. The secrets in the targets are left intact.",
+			spec.NotBefore.UTC().Format(time.RFC3339))
+		// the jitter spreads the deployments of the remote secrets delayed until the same time
+		result.RequeueAfter = jitter.Apply(time.Until(spec.NotBefore.Time))
+	} else {
+		// the status is persisted by the following stages of the reconciliation
+		meta.RemoveStatusCondition(conditions, string(api.RemoteSecretConditionTypeSuspended))
+		return result, false, nil
+	}
+
+	log.FromContext(ctx).V(logs.DebugLevel).Info("the reconciliation is suspended", "reason", condition.Reason)
+
+	if existing := meta.FindStatusCondition(*conditions, condition.Type); existing != nil && existing.Status == condition.Status &&
+		existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return result, true, nil
+	}
+
+	meta.SetStatusCondition(conditions, condition)
+
+	if err := cl.Status().Update(ctx, obj); err != nil {
+		return result, true, fmt.Errorf("failed to persist the suspension in the status: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// obtainData tries to find the data of the remote secret in the backing storage using the provided function. The time the data
+// was obtained, the provided reference to its source and the keys of the data are recorded in the provided status if the data
+// is found.
+func obtainData(ctx context.Context, status *api.RemoteSecretStatus, sourceRef string, getData func(context.Context) (*remotesecretstorage.SecretData, error)) stageResult[*remotesecretstorage.SecretData] {
 	result := stageResult[*remotesecretstorage.SecretData]{
 		Name: "data-fetch",
 	}
 
-	secretData, err := r.RemoteSecretStorage.Get(ctx, remoteSecret)
+	secretData, err := getData(ctx)
 	if err != nil {
 		if stdErrors.Is(err, secretstorage.NotFoundError) {
 			result.Condition = metav1.Condition{
@@ -222,6 +430,28 @@ func (r *RemoteSecretReconciler) obtainData(ctx context.Context, remoteSecret *a
 			}
 			// we don't want to retry the reconciliation in this case, because the data is simply not present in the storage.
 			// we will get notified once it appears there.
+		} else if stdErrors.Is(err, remotesecrets.CircuitOpenError) {
+			// the backend is failing for all the remote secrets, so we don't even try it until the cooldown passes.
+			result.Condition = metav1.Condition{
+				Type:    string(api.RemoteSecretConditionTypeDataObtained),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(api.RemoteSecretReasonCircuitOpen),
+				Message: err.Error(),
+			}
+			result.Cancellation.ReturnError = err
+		} else if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			// the object to copy the data from is missing or inaccessible. We're not watching it, so we need to retry.
+			reason := api.RemoteSecretReasonDataSourceMissing
+			if errors.IsForbidden(err) {
+				reason = api.RemoteSecretReasonDataSourceDenied
+			}
+			result.Condition = metav1.Condition{
+				Type:    string(api.RemoteSecretConditionTypeDataObtained),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(reason),
+				Message: err.Error(),
+			}
+			result.Cancellation.ReturnError = err
 		} else {
 			result.Condition = metav1.Condition{
 				Type:    string(api.RemoteSecretConditionTypeDataObtained),
@@ -238,12 +468,33 @@ func (r *RemoteSecretReconciler) obtainData(ctx context.Context, remoteSecret *a
 		return result
 	}
 
+	if secretData == nil || len(*secretData) == 0 {
+		result.Condition = metav1.Condition{
+			Type:    string(api.RemoteSecretConditionTypeDataObtained),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(api.RemoteSecretReasonAwaitingTokenData),
+			Message: "The data of the remote secret is empty. Please provide it.",
+		}
+		// same as with the data not being found at all - we don't want to create empty secrets in the targets.
+		result.Cancellation.Cancel = true
+		return result
+	}
+
 	result.Condition = metav1.Condition{
 		Type:   string(api.RemoteSecretConditionTypeDataObtained),
 		Status: metav1.ConditionTrue,
 		Reason: string(api.RemoteSecretReasonDataFound),
 	}
 
+	now := metav1.Now()
+	status.LastDataObtainedTime = &now
+	status.DataSourceRef = sourceRef
+	status.DataKeys = make([]string, 0, len(*secretData))
+	for k := range *secretData {
+		status.DataKeys = append(status.DataKeys, k)
+	}
+	sort.Strings(status.DataKeys)
+
 	result.ReturnValue = secretData
 
 	return result
@@ -251,30 +502,79 @@ func (r *RemoteSecretReconciler) obtainData(ctx context.Context, remoteSecret *a
 
 // deploy tries to deploy the secret to all the specified targets. It accumulates all errors, rather than stopping on the first one, so that we deploy
 // to as many targets as possible.
-func (r *RemoteSecretReconciler) deploy(ctx context.Context, remoteSecret *api.RemoteSecret, data *remotesecretstorage.SecretData) stageResult[any] {
+func deploy[K client.Object](ctx context.Context, processor *targetsProcessor[K]) stageResult[any] {
 	result := stageResult[any]{
 		Name: "secret-deployment",
 	}
 
+	// let the world know that the deployment is in progress. The status is updated after the deployment to each target,
+	// so this is observable until the deployment finishes.
+	meta.SetStatusCondition(&processor.Status.Conditions, metav1.Condition{
+		Type:    string(api.RemoteSecretConditionTypeDeployed),
+		Status:  metav1.ConditionUnknown,
+		Reason:  string(api.RemoteSecretReasonInjecting),
+		Message: "The secret is being deployed to the targets.",
+	})
+
+	if processor.MaxTargets > 0 && len(processor.Targets) > processor.MaxTargets {
+		// this is most probably a mistake, so we don't touch any target until the spec or the configuration is fixed.
+		log.FromContext(ctx).Info("refusing to deploy the secret to too many targets", "targets", len(processor.Targets), "maxTargets", processor.MaxTargets)
+		result.Condition = metav1.Condition{
+			Type:   string(api.RemoteSecretConditionTypeDeployed),
+			Status: metav1.ConditionFalse,
+			Reason: string(api.RemoteSecretReasonTooManyTargets),
+			Message: fmt.Sprintf("The secret should be deployed to %d targets but at most %d targets are allowed. The targets are left intact.",
+				len(processor.Targets), processor.MaxTargets),
+		}
+		result.Cancellation.Cancel = true
+		return result
+	}
+
 	aerr := &rerror.AggregatedError{}
-	r.processTargets(ctx, remoteSecret, data, aerr)
+	processor.processTargets(ctx, aerr)
+
+	syncedMessage := fmt.Sprintf("%d/%d targets synced", processor.Status.SyncedTargets, processor.Status.TotalTargets)
+	if processor.failedOptionalTargets > 0 {
+		syncedMessage = fmt.Sprintf("%s, %d optional targets failed", syncedMessage, processor.failedOptionalTargets)
+	}
+
+	if !aerr.HasErrors() {
+		processor.LogSuppressor.Forget(processor.logKey())
+	}
 
 	var deploymentStatus metav1.ConditionStatus
 	var deploymentReason api.RemoteSecretReason
 	var deploymentMessage string
 
-	if aerr.HasErrors() {
-		log.FromContext(ctx).Error(aerr, "failed to deploy the secret to some targets")
+	if aerr.HasErrors() && allForbidden(aerr) {
+		log.FromContext(ctx).Error(aerr, "not allowed to deploy the secret to some targets", "retryAfter", forbiddenTargetsRequeueDelay)
+
+		deploymentReason = api.RemoteSecretReasonTargetsForbidden
+		deploymentStatus = metav1.ConditionFalse
+		deploymentMessage = fmt.Sprintf("%s: not allowed to deploy to the namespaces %s: %s", syncedMessage,
+			strings.Join(forbiddenNamespaces(processor.Status.Targets), ", "), aerr.Error())
+		// retrying only helps once the permissions are fixed, so we don't want to flood the work queue with the failures.
+		result.Cancellation.Cancel = true
+		result.Cancellation.Result = ctrl.Result{RequeueAfter: processor.RequeueJitter.Apply(forbiddenTargetsRequeueDelay)}
+	} else if aerr.HasErrors() {
+		// the error is also in the status, so there is no need to flood the logs with it when the targets keep failing the same way.
+		processor.LogSuppressor.Error(log.FromContext(ctx), processor.logKey(), aerr, "failed to deploy the secret to some targets")
 
 		deploymentReason = api.RemoteSecretReasonPartiallyInjected
 		deploymentStatus = metav1.ConditionFalse
-		deploymentMessage = aerr.Error()
+		deploymentMessage = fmt.Sprintf("%s: %s", syncedMessage, aerr.Error())
 		// we want to retry the reconciliation because we failed to deploy to some targets
 		result.Cancellation.Cancel = true
 		result.Cancellation.ReturnError = aerr
+	} else if processor.Status.SyncedTargets+processor.failedOptionalTargets < processor.Status.TotalTargets {
+		// some targets are invalid (e.g. duplicates). Retrying doesn't help with those, so we don't cancel the reconciliation.
+		deploymentReason = api.RemoteSecretReasonPartiallyInjected
+		deploymentStatus = metav1.ConditionFalse
+		deploymentMessage = syncedMessage
 	} else {
 		deploymentReason = api.RemoteSecretReasonInjected
 		deploymentStatus = metav1.ConditionTrue
+		deploymentMessage = syncedMessage
 	}
 
 	result.Condition = metav1.Condition{
@@ -284,180 +584,82 @@ func (r *RemoteSecretReconciler) deploy(ctx context.Context, remoteSecret *api.R
 		Message: deploymentMessage,
 	}
 
-	return result
-}
-
-// processTargets uses remotesecrets.ClassifyTargetNamespaces to find out what to do with targets in the remote secret spec and status
-// and does what the classification tells it to.
-func (r *RemoteSecretReconciler) processTargets(ctx context.Context, remoteSecret *api.RemoteSecret, secretData *remotesecretstorage.SecretData, errorAggregate *rerror.AggregatedError) {
-	namespaceClassification := remotesecrets.ClassifyTargetNamespaces(remoteSecret)
-	for specIdx, statusIdx := range namespaceClassification.Sync {
-		spec := &remoteSecret.Spec.Targets[specIdx]
-		var status *api.TargetStatus
-		if statusIdx == -1 {
-			// as per docs, ClassifyTargetNamespaces uses -1 to indicate that the target is not in the status.
-			// So we just add a new empty entry to status and use that to deploy to the namespace.
-			// deployToNamespace will fill it in.
-			remoteSecret.Status.Targets = append(remoteSecret.Status.Targets, api.TargetStatus{})
-			status = &remoteSecret.Status.Targets[len(remoteSecret.Status.Targets)-1]
-		} else {
-			status = &remoteSecret.Status.Targets[statusIdx]
-		}
-		err := r.deployToNamespace(ctx, remoteSecret, spec, status, secretData)
-		if err != nil {
-			errorAggregate.Add(err)
-		}
-	}
-
-	for statusIndex := range namespaceClassification.Remove {
-		err := r.deleteFromNamespace(ctx, remoteSecret, statusIndex)
-		if err != nil {
-			errorAggregate.Add(err)
-		}
-	}
-
-	// mark the duplicates...
-	for originalIdx, duplicates := range namespaceClassification.DuplicateTargetSpecs {
-		for specIdx, statusIdx := range duplicates {
-			var status *api.TargetStatus
-			if statusIdx == -1 {
-				remoteSecret.Status.Targets = append(remoteSecret.Status.Targets, api.TargetStatus{})
-				status = &remoteSecret.Status.Targets[len(remoteSecret.Status.Targets)-1]
-			} else {
-				status = &remoteSecret.Status.Targets[statusIdx]
-			}
-			// clear out the status and just set the key and error
-			*status = api.TargetStatus{
-				ApiUrl:    remoteSecret.Spec.Targets[specIdx].ApiUrl,
-				Namespace: remoteSecret.Spec.Targets[specIdx].Namespace,
-				Error:     fmt.Sprintf("the target at the index %d is a duplicate of the target at the index %d", specIdx, originalIdx),
-			}
-		}
+	if !result.Cancellation.Cancel && processor.failedOptionalTargets > 0 {
+		log.FromContext(ctx).Info("failed to deploy the secret to some optional targets", "retryAfter", optionalTargetsRequeueDelay)
+		result.Cancellation.Cancel = true
+		result.Cancellation.Result = ctrl.Result{RequeueAfter: processor.RequeueJitter.Apply(optionalTargetsRequeueDelay)}
 	}
 
-	// and finally, remove the orphaned and deleted targets from the status
-	toRemove := make([]remotesecrets.StatusTargetIndex, 0, len(namespaceClassification.Remove)+len(namespaceClassification.OrphanDuplicateStatuses))
-	toRemove = append(toRemove, namespaceClassification.Remove...)
-	toRemove = append(toRemove, namespaceClassification.OrphanDuplicateStatuses...)
-	// sort the array in reverse order so that we can remove from the status without reindexing
-	sort.Slice(toRemove, func(i, j int) bool {
-		return toRemove[i] > toRemove[j]
-	})
-
-	for _, stIdx := range toRemove {
-		remoteSecret.Status.Targets = append(remoteSecret.Status.Targets[:stIdx], remoteSecret.Status.Targets[stIdx+1:]...)
-	}
+	return result
 }
 
-// deployToNamespace deploys the secret to the provided tartet and fills in the provided status with the result of the deployment. The status will also contain the error
-// if the deployment failed. This returns an error if the deployment fails (this is recorded in the target status) OR if the update of the status in k8s fails (this is,
-// obviously, not recorded in the target status).
-func (r *RemoteSecretReconciler) deployToNamespace(ctx context.Context, remoteSecret *api.RemoteSecret, targetSpec *api.RemoteSecretTarget, targetStatus *api.TargetStatus, data *remotesecretstorage.SecretData) error {
-	debugLog := log.FromContext(ctx).V(logs.DebugLevel)
-
-	depHandler := r.newDependentsHandler(remoteSecret, targetSpec, targetStatus)
-
-	checkPoint, syncErr := depHandler.CheckPoint(ctx)
-	if syncErr != nil {
-		return fmt.Errorf("failed to construct a checkpoint before dependent objects deployment: %w", syncErr)
-	}
-
-	deps, _, syncErr := depHandler.Sync(ctx, remoteSecret)
-
-	targetStatus.ApiUrl = targetSpec.ApiUrl
-
-	if syncErr == nil {
-		targetStatus.Namespace = deps.Secret.Namespace
-		targetStatus.SecretName = deps.Secret.Name
-
-		targetStatus.ServiceAccountNames = make([]string, len(deps.ServiceAccounts))
-		for i, sa := range deps.ServiceAccounts {
-			targetStatus.ServiceAccountNames[i] = sa.Name
-		}
-		targetStatus.Error = ""
-	} else {
-		targetStatus.Namespace = targetSpec.Namespace
-		targetStatus.SecretName = ""
-		targetStatus.ServiceAccountNames = []string{}
-		targetStatus.Error = syncErr.Error()
-	}
-
-	updateErr := r.Client.Status().Update(ctx, remoteSecret)
-	if syncErr != nil || updateErr != nil {
-		if syncErr != nil {
-			debugLog.Error(syncErr, "failed to sync the dependent objects")
-		}
-
-		if updateErr != nil {
-			debugLog.Error(updateErr, "failed to update the status with the info about dependent objects")
-		}
-
-		if rerr := depHandler.RevertTo(ctx, checkPoint); rerr != nil {
-			debugLog.Error(rerr, "failed to revert the sync of the dependent objects of the remote secret after a failure", "statusUpdateError", updateErr, "syncError", syncErr)
+// allForbidden returns true if all the aggregated errors are caused by the operator not being allowed to write to the targets.
+func allForbidden(aerr *rerror.AggregatedError) bool {
+	for _, err := range aerr.Errors() {
+		if bindings.ClassifyTargetError(err) != bindings.ErrorReasonForbidden {
+			return false
 		}
-	} else if debugLog.Enabled() {
-		saks := make([]client.ObjectKey, len(deps.ServiceAccounts))
-		for i, sa := range deps.ServiceAccounts {
-			saks[i] = client.ObjectKeyFromObject(sa)
-		}
-		debugLog.Info("successfully synced dependent objects of remote secret", "remoteSecret", client.ObjectKeyFromObject(remoteSecret), "syncedSecret", client.ObjectKeyFromObject(deps.Secret))
 	}
-	//TODO Think about proper fix. this fix is not working.
-	//return fmt.Errorf("aggregate error: %w", rerror.AggregateNonNilErrors(syncErr, updateErr))
-	//nolint:wrapcheck
-	return rerror.AggregateNonNilErrors(syncErr, updateErr)
+	return true
 }
 
-func (r *RemoteSecretReconciler) deleteFromNamespace(ctx context.Context, remoteSecret *api.RemoteSecret, targetStatusIndex int) error {
-	dep := r.newDependentsHandler(remoteSecret, nil, &remoteSecret.Status.Targets[targetStatusIndex])
-
-	if err := dep.Cleanup(ctx); err != nil {
-		return fmt.Errorf("failed to clean up dependent objects in the finalizer: %w", err)
-	}
-
-	// leave out the index from the status targets and save right now, so that the status reflects the state of the cluster as closely as possible.
-	newTargets := make([]api.TargetStatus, 0, len(remoteSecret.Status.Targets)-1)
-	for i, t := range remoteSecret.Status.Targets {
-		if i != targetStatusIndex {
-			newTargets = append(newTargets, t)
+// forbiddenNamespaces returns the namespaces of the targets the operator was not allowed to deploy to.
+func forbiddenNamespaces(targets []api.TargetStatus) []string {
+	ret := []string{}
+	for _, t := range targets {
+		if t.ErrorReason == string(bindings.ErrorReasonForbidden) {
+			ret = append(ret, t.Namespace)
 		}
 	}
-	remoteSecret.Status.Targets = newTargets
-
-	if err := r.Client.Status().Update(ctx, remoteSecret); err != nil {
-		return fmt.Errorf("failed to update the status with a modified set of target statuses: %w", err)
-	}
+	return ret
+}
 
-	return nil
+// newTargetsProcessor creates the processor of the targets of the provided remote secret.
+func (r *RemoteSecretReconciler) newTargetsProcessor(remoteSecret *api.RemoteSecret) *targetsProcessor[*api.RemoteSecret] {
+	p := newRemoteSecretTargetsProcessor(r.Client, r.dataStores(), remoteSecret)
+	p.Concurrency = targetDeploymentConcurrency(r.Configuration)
+	p.ServerSideApply = serverSideApply(r.Configuration)
+	p.NamespacePolicy = targetNamespacePolicy(r.Configuration)
+	p.MarkerDomain = markerDomain(r.Configuration)
+	p.MaxTargets = maxTargets(r.Configuration, remoteSecret.Spec.MaxTargets)
+	p.RemoteClients = r.RemoteClients
+	p.ClusterRegistry = r.ClusterRegistry
+	p.Coalescer = r.Coalescer
+	p.LocalApiUrl = r.LocalApiUrl
+	p.LogSuppressor = r.ErrorLogSuppressor
+	p.RequeueJitter = requeueJitter(r.Configuration)
+	return p
 }
 
-func (r *RemoteSecretReconciler) newDependentsHandler(remoteSecret *api.RemoteSecret, targetSpec *api.RemoteSecretTarget, targetStatus *api.TargetStatus) bindings.DependentsHandler[*api.RemoteSecret] {
-	return bindings.DependentsHandler[*api.RemoteSecret]{
-		Target: &namespacetarget.NamespaceTarget{
-			Client:       r.clientForTarget(targetSpec),
-			TargetKey:    client.ObjectKeyFromObject(remoteSecret),
-			SecretSpec:   &remoteSecret.Spec.Secret,
-			TargetSpec:   targetSpec,
-			TargetStatus: targetStatus,
-		},
-		SecretDataGetter: &remotesecrets.SecretDataGetter{
-			Storage: r.RemoteSecretStorage,
-		},
-		ObjectMarker: &namespacetarget.NamespaceObjectMarker{},
+// dataStores returns the configured data stores or the registry with just the local data store if none are configured.
+func (r *RemoteSecretReconciler) dataStores() *remotesecrets.DataStoreRegistry[*api.RemoteSecret] {
+	if r.DataStores != nil {
+		return r.DataStores
 	}
+	ret := remotesecrets.NewRemoteSecretDataStores(r.RemoteSecretStorage, r.Client)
+	ret.Cache = r.DataCache
+	ret.Breaker = r.CircuitBreaker
+	return ret
 }
 
-func (r *RemoteSecretReconciler) clientForTarget(targetSpec *api.RemoteSecretTarget) client.Client {
-	if targetSpec.ApiUrl == "" {
-		return r.Client
+func newRemoteSecretTargetsProcessor(cl client.Client, dataStores *remotesecrets.DataStoreRegistry[*api.RemoteSecret], remoteSecret *api.RemoteSecret) *targetsProcessor[*api.RemoteSecret] {
+	return &targetsProcessor[*api.RemoteSecret]{
+		Client:         cl,
+		Object:         remoteSecret,
+		SecretSpec:     &remoteSecret.Spec.Secret,
+		Targets:        remoteSecret.Spec.Targets,
+		Status:         &remoteSecret.Status,
+		DeletionPolicy: remoteSecret.Spec.DeletionPolicy,
+		DataSources:    remotesecrets.DataSourcesOf(&remoteSecret.Spec),
+		NewSecretDataGetter: func(keyFilter *api.KeyFilter, secretType corev1.SecretType) bindings.SecretDataGetter[*api.RemoteSecret] {
+			return &remotesecrets.SecretDataGetter{
+				DataStores:  dataStores,
+				DataSources: remotesecrets.DataSourcesOf(&remoteSecret.Spec),
+				KeyFilter:   keyFilter,
+				SecretType:  secretType,
+			}
+		},
 	}
-
-	// TODO: this should construct a client that connects to the ApiUrl of the target using the token stored in the secret.
-	// This client only needs to store secrets and serviceaccounts so we should construct a minimal client without API discovery
-	// as we do in the oauth client to limit the time it takes to create the client and also to limit the memory consumption.
-	// We could also think about caching the clients.
-
-	return r.Client
 }
 
 type remoteSecretStorageFinalizer struct {
@@ -475,8 +677,9 @@ func (f *remoteSecretStorageFinalizer) Finalize(ctx context.Context, obj client.
 }
 
 type remoteSecretLinksFinalizer struct {
-	client  client.Client
-	storage remotesecretstorage.RemoteSecretStorage
+	client       client.Client
+	dataStores   *remotesecrets.DataStoreRegistry[*api.RemoteSecret]
+	markerDomain string
 }
 
 //var _ finalizer.Finalizer = (*linkedObjectsFinalizer)(nil)
@@ -495,25 +698,11 @@ func (f *remoteSecretLinksFinalizer) Finalize(ctx context.Context, obj client.Ob
 
 	lg.Info("linked objects finalizer starting to clean up dependent objects", "remoteSecret", key)
 
-	for i := range remoteSecret.Status.Targets {
-		ts := remoteSecret.Status.Targets[i]
-		dep := bindings.DependentsHandler[*api.RemoteSecret]{
-			Target: &namespacetarget.NamespaceTarget{
-				Client:       f.client,
-				TargetKey:    key,
-				SecretSpec:   &remoteSecret.Spec.Secret,
-				TargetStatus: &ts,
-			},
-			SecretDataGetter: &remotesecrets.SecretDataGetter{
-				Storage: f.storage,
-			},
-			ObjectMarker: &namespacetarget.NamespaceObjectMarker{},
-		}
-
-		if err := dep.Cleanup(ctx); err != nil {
-			lg.Error(err, "failed to clean up the dependent objects in the finalizer", "binding", client.ObjectKeyFromObject(remoteSecret))
-			return res, fmt.Errorf("failed to clean up dependent objects in the finalizer: %w", err)
-		}
+	processor := newRemoteSecretTargetsProcessor(f.client, f.dataStores, remoteSecret)
+	processor.MarkerDomain = f.markerDomain
+	if err := processor.cleanup(ctx); err != nil {
+		lg.Error(err, "failed to clean up the dependent objects in the finalizer", "binding", client.ObjectKeyFromObject(remoteSecret))
+		return res, fmt.Errorf("failed to clean up dependent objects in the finalizer: %w", err)
 	}
 
 	lg.Info("linked objects finalizer completed without failure", "binding", key)
@@ -0,0 +1,1633 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	opconfig "github.com/redhat-appstudio/remote-secret/pkg/config"
+	"github.com/redhat-appstudio/remote-secret/pkg/rerror"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/memorystorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/finalizer"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcile_DataSourceDisappeared(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	t.Run("no data, no secret", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), targetSecretKey, &corev1.Secret{})))
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Nil(t, current.Status.LastDataObtainedTime)
+		assert.Empty(t, current.Status.DataSourceRef)
+	})
+
+	t.Run("data deployed", func(t *testing.T) {
+		assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("value"), s.Data["key"])
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.NotNil(t, current.Status.LastDataObtainedTime)
+		assert.Equal(t, "local://", current.Status.DataSourceRef)
+		assert.Equal(t, []string{"key"}, current.Status.DataKeys)
+	})
+
+	t.Run("data disappeared, target preserved", func(t *testing.T) {
+		assert.NoError(t, storage.Delete(context.TODO(), rs))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("value"), s.Data["key"])
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.True(t, meta.IsStatusConditionFalse(current.Status.Conditions, string(api.RemoteSecretConditionTypeDataObtained)))
+		// the last successful retrieval is still recorded
+		assert.NotNil(t, current.Status.LastDataObtainedTime)
+	})
+}
+
+func TestLinkedObjectsPredicate(t *testing.T) {
+	linked := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				namespacetarget.LinkedByRemoteSecretLabel: "true",
+			},
+		},
+	}
+	unrelated := &corev1.Secret{}
+	linkedObjectsPredicate := linkedObjectsPredicate(&namespacetarget.NamespaceObjectMarker{})
+
+	t.Run("create", func(t *testing.T) {
+		assert.True(t, linkedObjectsPredicate.Create(event.CreateEvent{Object: linked}))
+		assert.False(t, linkedObjectsPredicate.Create(event.CreateEvent{Object: unrelated}))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		assert.True(t, linkedObjectsPredicate.Update(event.UpdateEvent{ObjectOld: linked, ObjectNew: linked}))
+		assert.True(t, linkedObjectsPredicate.Update(event.UpdateEvent{ObjectOld: linked, ObjectNew: unrelated}))
+		assert.False(t, linkedObjectsPredicate.Update(event.UpdateEvent{ObjectOld: unrelated, ObjectNew: unrelated}))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.True(t, linkedObjectsPredicate.Delete(event.DeleteEvent{Object: linked}))
+		assert.False(t, linkedObjectsPredicate.Delete(event.DeleteEvent{Object: unrelated}))
+	})
+}
+
+func TestReconcile_TargetRemoved(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "ns-1"},
+				{Namespace: "ns-2"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-1"}, &corev1.Secret{}))
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-2"}, &corev1.Secret{}))
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, []api.ManagedSecretReference{
+		{Namespace: "ns-1", Name: "target-secret"},
+		{Namespace: "ns-2", Name: "target-secret"},
+	}, current.Status.ManagedSecrets)
+	current.Spec.Targets = current.Spec.Targets[1:]
+	assert.NoError(t, cl.Update(context.TODO(), current))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-1"}, &corev1.Secret{})))
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-2"}, &corev1.Secret{}))
+
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Len(t, current.Status.Targets, 1)
+	assert.Equal(t, "ns-2", current.Status.Targets[0].Namespace)
+	assert.Equal(t, []api.ManagedSecretReference{{Namespace: "ns-2", Name: "target-secret"}}, current.Status.ManagedSecrets)
+}
+
+func TestReconcile_TargetCounts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "ns-1"},
+				{Namespace: "ns-2"},
+				// the duplicate is never synced
+				{Namespace: "ns-2"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	current := &api.RemoteSecret{}
+
+	t.Run("partially synced", func(t *testing.T) {
+		_, _ = r.Reconcile(context.TODO(), req)
+
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Equal(t, 3, current.Status.TotalTargets)
+		assert.Equal(t, 2, current.Status.SyncedTargets)
+
+		cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed))
+		assert.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, string(api.RemoteSecretReasonPartiallyInjected), cond.Reason)
+		assert.Contains(t, cond.Message, "2/3 targets synced")
+	})
+
+	t.Run("all synced", func(t *testing.T) {
+		current.Spec.Targets = current.Spec.Targets[:2]
+		assert.NoError(t, cl.Update(context.TODO(), current))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Equal(t, 2, current.Status.TotalTargets)
+		assert.Equal(t, 2, current.Status.SyncedTargets)
+
+		cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed))
+		assert.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+		assert.Equal(t, "2/2 targets synced", cond.Message)
+	})
+}
+
+func TestReconcile_ForceSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name:   "target-secret",
+				Labels: map[string]string{"a": "b"},
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	// remove the label from the secret. Metadata changes are not considered when looking for the differences, so normal
+	// reconciliation doesn't restore it.
+	s := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+	delete(s.Labels, "a")
+	assert.NoError(t, cl.Update(context.TODO(), s))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+	assert.NotContains(t, s.Labels, "a")
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	current.Annotations = map[string]string{api.ForceSyncAnnotation: "2023-01-01T00:00:00Z"}
+	assert.NoError(t, cl.Update(context.TODO(), current))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+	assert.Equal(t, "b", s.Labels["a"])
+
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, "2023-01-01T00:00:00Z", current.Status.ObservedForceSync)
+
+	// the labels changed in the spec are applied even without the forced sync
+	current.Spec.Secret.Labels = map[string]string{"a": "c"}
+	current.Generation++
+	assert.NoError(t, cl.Update(context.TODO(), current))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+	assert.Equal(t, "c", s.Labels["a"])
+}
+
+func TestFailedTargetsInNamespaceRequests(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "default"},
+			Status: api.RemoteSecretStatus{
+				Targets: []api.TargetStatus{
+					{Namespace: "ok-ns", SecretName: "s"},
+					{Namespace: "new-ns", Error: "namespace not found"},
+				},
+			},
+		},
+		&api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "synced", Namespace: "default"},
+			Status: api.RemoteSecretStatus{
+				Targets: []api.TargetStatus{
+					{Namespace: "new-ns", SecretName: "s"},
+				},
+			},
+		},
+		&api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "default"},
+			Status: api.RemoteSecretStatus{
+				Targets: []api.TargetStatus{
+					{Namespace: "new-ns", ApiUrl: "https://remote", Error: "namespace not found"},
+				},
+			},
+		},
+	).Build()
+
+	r := &RemoteSecretReconciler{Client: cl}
+
+	reqs := r.failedTargetsInNamespaceRequests(logr.Discard(), "new-ns")
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKey{Name: "failed", Namespace: "default"}}}, reqs)
+
+	assert.Empty(t, r.failedTargetsInNamespaceRequests(logr.Discard(), "ok-ns"))
+}
+
+func TestReconcile_Waves(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+				Type: corev1.SecretTypeSSHAuth,
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "ns-2", Wave: 2},
+				// filtering out the required key makes the deployment to this target fail
+				{Namespace: "ns-1", Wave: 1, KeyFilter: &api.KeyFilter{Exclude: []string{corev1.SSHAuthPrivateKey}}},
+				{Namespace: "ns-0"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{corev1.SSHAuthPrivateKey: []byte("key")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.Error(t, err)
+
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-0"}, &corev1.Secret{}))
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-2"}, &corev1.Secret{})))
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, 1, current.Status.SyncedTargets)
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "ns-2" {
+			assert.Contains(t, ts.Error, "wave 1")
+		}
+	}
+
+	// fixing the failing target unblocks the next wave
+	current.Spec.Targets[1].KeyFilter = nil
+	assert.NoError(t, cl.Update(context.TODO(), current))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-2"}, &corev1.Secret{}))
+}
+
+func TestReconcile_DataFrom(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			DataFrom: &api.DataFrom{Name: "source"},
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	t.Run("source missing", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.Error(t, err)
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDataObtained))
+		assert.NotNil(t, cond)
+		assert.Equal(t, string(api.RemoteSecretReasonDataSourceMissing), cond.Reason)
+	})
+
+	t.Run("data copied from the source", func(t *testing.T) {
+		assert.NoError(t, cl.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+			Data:       map[string][]byte{"key": []byte("value")},
+		}))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("value"), s.Data["key"])
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Len(t, current.Status.Targets, 1)
+		assert.Equal(t, bindings.DataHash(map[string][]byte{"key": []byte("value")}), current.Status.Targets[0].DataHash)
+		assert.NotNil(t, current.Status.Targets[0].DataSyncTime)
+		assert.False(t, current.Status.Targets[0].DefaultSecretName)
+	})
+}
+
+// sourceCountingClient counts the reads of the secret with the source name.
+type sourceCountingClient struct {
+	client.Client
+	source string
+	reads  int
+}
+
+func (c *sourceCountingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && key.Name == c.source {
+		c.reads++
+	}
+	return c.Client.Get(ctx, key, obj, opts...) //nolint:wrapcheck // test code
+}
+
+func TestReconcile_SharedDataSourceCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	newRs := func(name, targetNs string) *api.RemoteSecret {
+		return &api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec: api.RemoteSecretSpec{
+				DataFrom: &api.DataFrom{Name: "source"},
+				Secret:   api.LinkableSecretSpec{Name: "target-secret"},
+				Targets:  []api.RemoteSecretTarget{{Namespace: targetNs}},
+			},
+		}
+	}
+	rs1 := newRs("rs1", "ns-1")
+	rs2 := newRs("rs2", "ns-2")
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+
+	cl := &sourceCountingClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithIndex(&api.RemoteSecret{}, remotesecrets.DataSourceIndexField, func(o client.Object) []string {
+				return remotesecrets.DataSourceIndexValues(o.(*api.RemoteSecret))
+			}).
+			WithObjects(rs1, rs2, source).Build(),
+		source: "source",
+	}
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		DataCache:           remotesecrets.NewDataCache(time.Minute, 10),
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	reconcileAll := func() {
+		for _, rs := range []*api.RemoteSecret{rs1, rs2} {
+			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+			assert.NoError(t, err)
+		}
+	}
+
+	targetData := func(ns string) []byte {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: ns}, s))
+		return s.Data["key"]
+	}
+
+	t.Run("single read of the shared source", func(t *testing.T) {
+		reconcileAll()
+
+		assert.Equal(t, 1, cl.reads)
+		assert.Equal(t, []byte("value"), targetData("ns-1"))
+		assert.Equal(t, []byte("value"), targetData("ns-2"))
+	})
+
+	t.Run("source change invalidates the shared data", func(t *testing.T) {
+		source.Data["key"] = []byte("changed")
+		assert.NoError(t, cl.Update(context.TODO(), source))
+		assert.Len(t, r.dataSourceRequests(logr.Discard(), source), 2)
+
+		cl.reads = 0
+		reconcileAll()
+
+		assert.Equal(t, 1, cl.reads)
+		assert.Equal(t, []byte("changed"), targetData("ns-1"))
+		assert.Equal(t, []byte("changed"), targetData("ns-2"))
+	})
+}
+
+func TestReconcile_DefaultSecretName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			DataFrom: &api.DataFrom{Name: "source"},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}).Build()
+
+	r := &RemoteSecretReconciler{
+		Client:     cl,
+		Scheme:     scheme,
+		finalizers: finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.NoError(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 1)
+	assert.True(t, current.Status.Targets[0].DefaultSecretName)
+	assert.True(t, strings.HasPrefix(current.Status.Targets[0].SecretName, "rs-secret-"))
+}
+
+func TestReconcile_GenerateNamePrefix(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret:   api.LinkableSecretSpec{GenerateNamePrefix: "team-a-"},
+			DataFrom: &api.DataFrom{Name: "source"},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}).Build()
+
+	r := &RemoteSecretReconciler{
+		Client:     cl,
+		Scheme:     scheme,
+		finalizers: finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.NoError(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 1)
+	assert.False(t, current.Status.Targets[0].DefaultSecretName)
+	assert.True(t, strings.HasPrefix(current.Status.Targets[0].SecretName, "team-a-"))
+}
+
+func TestReconcile_TargetSecretOverrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret:   api.LinkableSecretSpec{Name: "secret"},
+			DataFrom: &api.DataFrom{Name: "source"},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "opaque"},
+				{Namespace: "tls", Type: corev1.SecretTypeTLS, Immutable: pointer.Bool(true)},
+				{Namespace: "ssh-auth", Type: corev1.SecretTypeSSHAuth},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}).Build()
+
+	r := &RemoteSecretReconciler{
+		Client:     cl,
+		Scheme:     scheme,
+		finalizers: finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.Error(t, err)
+
+	opaque := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "opaque"}, opaque))
+	assert.Equal(t, corev1.SecretTypeOpaque, sync.SecretType(opaque))
+	assert.Nil(t, opaque.Immutable)
+
+	tls := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: "tls"}, tls))
+	assert.Equal(t, corev1.SecretTypeTLS, tls.Type)
+	assert.True(t, *tls.Immutable)
+
+	// the data doesn't contain the keys required by the overridden type
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "ssh-auth" {
+			assert.Equal(t, string(bindings.ErrorReasonMissingRequiredKeys), ts.ErrorReason)
+		} else {
+			assert.Empty(t, ts.Error)
+		}
+	}
+}
+
+func TestReconcile_ObservedGeneration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "rs",
+			Namespace:  "default",
+			UID:        "rs-uid",
+			Generation: 3,
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "remote-creds"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	// the "remote" cluster is simulated by the local fake client
+	remoteClients := func(_ context.Context, apiUrl string, credentials client.ObjectKey) (client.Client, error) {
+		assert.Equal(t, "https://remote.cluster", apiUrl)
+		assert.Equal(t, client.ObjectKey{Name: "remote-creds", Namespace: "default"}, credentials)
+		return cl, nil
+	}
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		RemoteClients:       remoteClients,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	t.Run("recorded after deployment", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Equal(t, int64(3), current.Status.ObservedGeneration)
+	})
+
+	t.Run("superseded generation not deployed to remote targets", func(t *testing.T) {
+		stale := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, stale))
+
+		current := stale.DeepCopy()
+		current.Generation = 4
+		current.Spec.Secret.Labels = map[string]string{"new": "label"}
+		assert.NoError(t, cl.Update(context.TODO(), current))
+
+		p := newRemoteSecretTargetsProcessor(cl, remotesecrets.NewRemoteSecretDataStores(storage, cl), stale)
+		p.RemoteClients = remoteClients
+		aerr := &rerror.AggregatedError{}
+		p.processTargets(context.TODO(), aerr)
+
+		assert.True(t, aerr.HasErrors())
+		assert.Contains(t, stale.Status.Targets[0].Error, objectSupersededError.Error())
+		assert.Equal(t, int64(3), stale.Status.ObservedGeneration)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}, s))
+		assert.NotContains(t, s.Labels, "new")
+	})
+}
+
+func TestReconcile_ClusterCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "no-creds", ApiUrl: "https://remote.cluster"},
+				{Namespace: "missing-creds", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "missing"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.Error(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		switch ts.Namespace {
+		case "no-creds":
+			assert.Equal(t, string(bindings.ErrorReasonInvalidClusterCredentials), ts.ErrorReason)
+		case "missing-creds":
+			assert.Equal(t, string(bindings.ErrorReasonClusterCredentialsNotFound), ts.ErrorReason)
+			assert.Equal(t, "missing", ts.ClusterCredentialsSecret)
+		}
+		assert.Empty(t, ts.SecretName)
+	}
+
+	// nothing was deployed to the local cluster instead
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "no-creds"}, &corev1.Secret{})))
+}
+
+func TestReconcile_ClusterRegistry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "registered", Cluster: "prod"},
+				{Namespace: "unregistered", Cluster: "staging"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod-cluster",
+			Namespace: "default",
+			Labels:    map[string]string{remotesecrets.ClusterNameLabel: "prod"},
+		},
+		Data: map[string][]byte{remotesecrets.ClusterApiUrlKey: []byte("https://prod.cluster")},
+	}).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	// the "remote" cluster is simulated by the local fake client
+	remoteClients := func(_ context.Context, apiUrl string, credentials client.ObjectKey) (client.Client, error) {
+		assert.Equal(t, "https://prod.cluster", apiUrl)
+		assert.Equal(t, client.ObjectKey{Name: "prod-cluster", Namespace: "default"}, credentials)
+		return cl, nil
+	}
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		RemoteClients:       remoteClients,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.Error(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		switch ts.Namespace {
+		case "registered":
+			assert.Empty(t, ts.Error)
+			assert.Equal(t, "https://prod.cluster", ts.ApiUrl)
+			assert.Equal(t, "prod-cluster", ts.ClusterCredentialsSecret)
+			assert.Equal(t, "target-secret", ts.SecretName)
+		case "unregistered":
+			assert.Equal(t, string(bindings.ErrorReasonClusterNotFound), ts.ErrorReason)
+			assert.Empty(t, ts.SecretName)
+		}
+	}
+
+	// the spec is left intact
+	assert.Empty(t, current.Spec.Targets[0].ApiUrl)
+
+	// nothing was deployed to the local cluster instead of the unregistered one
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "unregistered"}, &corev1.Secret{})))
+}
+
+func TestReconcile_RedundantApiUrl(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "local"},
+				{Namespace: "self", ApiUrl: "https://api.local.cluster:6443/", ClusterCredentialsSecret: "creds"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		LocalApiUrl:         "https://api.local.cluster:6443",
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	// retrying doesn't help with the redundant API URLs, so the reconciliation doesn't fail
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.NoError(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "self" {
+			assert.Equal(t, string(bindings.ErrorReasonRedundantApiUrl), ts.ErrorReason)
+			assert.Contains(t, ts.Error, "remove the apiUrl")
+		} else {
+			assert.Empty(t, ts.Error)
+		}
+	}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "local"}, &corev1.Secret{}))
+}
+
+func TestReconcile_SelfReferencingTarget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			DataFrom: &api.DataFrom{Name: "source"},
+			Secret: api.LinkableSecretSpec{
+				Name: "source",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "default"},
+				{Namespace: "other"},
+			},
+		},
+	}
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, source).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	// retrying doesn't help with the self-referencing targets, so the reconciliation doesn't fail
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)})
+	assert.NoError(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(rs), current))
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "default" {
+			assert.Equal(t, string(bindings.ErrorReasonSelfReferencingTarget), ts.ErrorReason)
+			assert.Contains(t, ts.Error, "trigger another deployment")
+		} else {
+			assert.Empty(t, ts.Error)
+		}
+	}
+
+	// the source is left alone
+	s := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(source), s))
+	assert.Equal(t, source.Data, s.Data)
+	assert.Empty(t, s.Labels)
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "source", Namespace: "other"}, s))
+}
+
+func TestReconcile_CreateOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name:           "target-secret",
+				DeploymentMode: api.SecretDeploymentModeCreateOnly,
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	t.Run("creates the secret", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("value"), s.Data["key"])
+	})
+
+	t.Run("leaves the existing secret untouched", func(t *testing.T) {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		s.Data["key"] = []byte("user-value")
+		assert.NoError(t, cl.Update(context.TODO(), s))
+
+		assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("new-value")}))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("user-value"), s.Data["key"])
+	})
+
+	t.Run("recreates the deleted secret", func(t *testing.T) {
+		s := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.NoError(t, cl.Delete(context.TODO(), s))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, s))
+		assert.Equal(t, []byte("new-value"), s.Data["key"])
+	})
+}
+
+func TestReconcile_ConcurrentTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+				Type: corev1.SecretTypeSSHAuth,
+			},
+		},
+	}
+	for i := 0; i < 10; i++ {
+		target := api.RemoteSecretTarget{Namespace: fmt.Sprintf("ns-%d", i)}
+		if i == 5 {
+			// filtering out the required key makes the deployment to this target fail
+			target.KeyFilter = &api.KeyFilter{Exclude: []string{corev1.SSHAuthPrivateKey}}
+		}
+		rs.Spec.Targets = append(rs.Spec.Targets, target)
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{corev1.SSHAuthPrivateKey: []byte("key")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		Configuration:       &opconfig.OperatorConfiguration{TargetDeploymentConcurrency: 4},
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.Error(t, err)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, 9, current.Status.SyncedTargets)
+	assert.Len(t, current.Status.Targets, 10)
+
+	for i := 0; i < 10; i++ {
+		ns := fmt.Sprintf("ns-%d", i)
+		err := cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: ns}, &corev1.Secret{})
+		if i == 5 {
+			assert.True(t, errors.IsNotFound(err))
+		} else {
+			assert.NoError(t, err, ns)
+		}
+	}
+}
+
+func TestReconcile_Suspend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Suspend: true,
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	t.Run("suspended", func(t *testing.T) {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), targetSecretKey, &corev1.Secret{})))
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, string(api.RemoteSecretConditionTypeSuspended)))
+		assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed)))
+	})
+
+	t.Run("resumed", func(t *testing.T) {
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		current.Spec.Suspend = false
+		assert.NoError(t, cl.Update(context.TODO(), current))
+
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, &corev1.Secret{}))
+
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeSuspended)))
+	})
+}
+
+func TestReconcile_NotBefore(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	notBefore := metav1.NewTime(time.Now().Add(time.Hour))
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			NotBefore: &notBefore,
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "target-ns"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+	targetSecretKey := client.ObjectKey{Name: "target-secret", Namespace: "target-ns"}
+
+	t.Run("waiting", func(t *testing.T) {
+		result, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Greater(t, result.RequeueAfter, 59*time.Minute)
+		assert.LessOrEqual(t, result.RequeueAfter, time.Hour)
+
+		assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), targetSecretKey, &corev1.Secret{})))
+
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeSuspended))
+		assert.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+		assert.Equal(t, string(api.RemoteSecretReasonNotBefore), cond.Reason)
+	})
+
+	t.Run("waiting with jitter", func(t *testing.T) {
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+
+		jitter := Jitter{MaxPercent: 10, Float64: func() float64 { return 1 }}
+		result, suspended, err := handleSuspension(context.TODO(), cl, current, &current.Status.Conditions, &current.Spec, jitter)
+		assert.NoError(t, err)
+		assert.True(t, suspended)
+		assert.Greater(t, result.RequeueAfter, 65*time.Minute)
+		assert.LessOrEqual(t, result.RequeueAfter, 66*time.Minute)
+	})
+
+	t.Run("passed", func(t *testing.T) {
+		current := &api.RemoteSecret{}
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		past := metav1.NewTime(time.Now().Add(-time.Minute))
+		current.Spec.NotBefore = &past
+		assert.NoError(t, cl.Update(context.TODO(), current))
+
+		result, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+
+		assert.NoError(t, cl.Get(context.TODO(), targetSecretKey, &corev1.Secret{}))
+
+		assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+		assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeSuspended)))
+	})
+}
+
+func TestReconcile_MaxTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			MaxTargets: 1,
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "ns-1"},
+				{Namespace: "ns-2"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"key": []byte("value")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-1"}, &corev1.Secret{})))
+	assert.True(t, errors.IsNotFound(cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "ns-2"}, &corev1.Secret{})))
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed))
+	assert.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, string(api.RemoteSecretReasonTooManyTargets), cond.Reason)
+	assert.Empty(t, current.Status.Targets)
+
+	t.Run("operator limit", func(t *testing.T) {
+		assert.Equal(t, 0, maxTargets(nil, 0))
+		assert.Equal(t, 5, maxTargets(nil, 5))
+		assert.Equal(t, 3, maxTargets(&opconfig.OperatorConfiguration{MaxTargets: 3}, 0))
+		assert.Equal(t, 3, maxTargets(&opconfig.OperatorConfiguration{MaxTargets: 3}, 5))
+		assert.Equal(t, 2, maxTargets(&opconfig.OperatorConfiguration{MaxTargets: 3}, 2))
+	})
+}
+
+// forbiddingClient refuses to create the secrets in the forbidden namespace as if the operator lacked the permissions.
+type forbiddingClient struct {
+	client.Client
+	forbiddenNamespace string
+}
+
+func (c *forbiddingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && obj.GetNamespace() == c.forbiddenNamespace {
+		return errors.NewForbidden(schema.GroupResource{Resource: "secrets"}, obj.GetName(), stdErrors.New("cannot create"))
+	}
+	return c.Client.Create(ctx, obj, opts...) //nolint:wrapcheck // test code
+}
+
+func TestReconcile_ForbiddenTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "ok-ns"},
+				{Namespace: "forbidden-ns"},
+			},
+		},
+	}
+
+	cl := &forbiddingClient{
+		Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build(),
+		forbiddenNamespace: "forbidden-ns",
+	}
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"a": []byte("b")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	result, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, forbiddenTargetsRequeueDelay, result.RequeueAfter)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, 1, current.Status.SyncedTargets)
+	cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed))
+	assert.NotNil(t, cond)
+	assert.Equal(t, string(api.RemoteSecretReasonTargetsForbidden), cond.Reason)
+	assert.Contains(t, cond.Message, "forbidden-ns")
+
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "forbidden-ns" {
+			assert.Equal(t, string(bindings.ErrorReasonForbidden), ts.ErrorReason)
+		}
+	}
+
+	// the retries of the remote secrets failing the same way are spread by the jitter
+	processor := r.newTargetsProcessor(current)
+	processor.RequeueJitter = Jitter{MaxPercent: 20, Float64: func() float64 { return 0.5 }}
+	assert.Equal(t, 11*time.Minute, deploy(context.TODO(), processor).Cancellation.Result.RequeueAfter)
+}
+
+func TestReconcile_OptionalTargets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "failing-ns", Optional: true},
+				{Namespace: "later-ns", Wave: 1},
+			},
+		},
+	}
+
+	cl := &forbiddingClient{
+		Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build(),
+		forbiddenNamespace: "failing-ns",
+	}
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"a": []byte("b")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	result, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, optionalTargetsRequeueDelay, result.RequeueAfter)
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	// the failed optional target doesn't block the later wave
+	assert.Equal(t, 1, current.Status.SyncedTargets)
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "later-ns"}, &corev1.Secret{}))
+
+	cond := meta.FindStatusCondition(current.Status.Conditions, string(api.RemoteSecretConditionTypeDeployed))
+	assert.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, string(api.RemoteSecretReasonInjected), cond.Reason)
+	assert.Contains(t, cond.Message, "1 optional targets failed")
+
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "failing-ns" {
+			assert.Equal(t, string(bindings.ErrorReasonForbidden), ts.ErrorReason)
+		}
+	}
+
+	processor := r.newTargetsProcessor(current)
+	processor.RequeueJitter = Jitter{MaxPercent: 20, Float64: func() float64 { return 0.5 }}
+	assert.Equal(t, 11*time.Minute, deploy(context.TODO(), processor).Cancellation.Result.RequeueAfter)
+}
+
+func TestReconcile_TargetNamespacePolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+			UID:       "rs-uid",
+		},
+		Spec: api.RemoteSecretSpec{
+			Secret: api.LinkableSecretSpec{
+				Name: "target-secret",
+			},
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "default"},
+				{Namespace: "other"},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"a": []byte("b")}))
+
+	r := &RemoteSecretReconciler{
+		Client:              cl,
+		Scheme:              scheme,
+		RemoteSecretStorage: storage,
+		Configuration:       &opconfig.OperatorConfiguration{TargetNamespacePolicy: string(remotesecrets.TargetNamespacePolicyOwnNamespace)},
+		finalizers:          finalizer.NewFinalizers(),
+	}
+
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rs)}
+
+	// the disallowed target is not retried
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "default"}, &corev1.Secret{}))
+	err = cl.Get(context.TODO(), client.ObjectKey{Name: "target-secret", Namespace: "other"}, &corev1.Secret{})
+	assert.True(t, errors.IsNotFound(err))
+
+	current := &api.RemoteSecret{}
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, current))
+	assert.Equal(t, 1, current.Status.SyncedTargets)
+	assert.Len(t, current.Status.Targets, 2)
+	for _, ts := range current.Status.Targets {
+		if ts.Namespace == "other" {
+			assert.Equal(t, string(bindings.ErrorReasonTargetNotAllowed), ts.ErrorReason)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxCircuitCooldownFactor limits the growth of the cooldown of a circuit that keeps failing the probes. The cooldown never gets
+// longer than this multiple of the configured cooldown.
+const maxCircuitCooldownFactor = 16
+
+var CircuitOpenError = errors.New("the data store is failing, not trying it until the cooldown period passes")
+
+// CircuitBreaker stops calling the data stores that keep failing. There is a circuit for each backend (the scheme of the data
+// store URI). After the configured number of consecutive failures, the circuit opens and the calls to the backend fail fast with
+// CircuitOpenError for the cooldown period. After that, a single call is let through to probe the backend (the circuit is
+// half-open). If it succeeds, the circuit closes. If it fails, the circuit opens again with the cooldown doubled, up to
+// the maxCircuitCooldownFactor multiple of the configured cooldown.
+//
+// Only the failures of the backend itself are counted. The errors caused by the individual objects, like a missing data source,
+// are not.
+//
+// The nil circuit breaker is valid and never opens any circuit.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	// failures is the number of the consecutive failures.
+	failures int
+	// cooldown is the cooldown of the circuit when it was last opened.
+	cooldown time.Duration
+	// openUntil is the time until which the circuit is open. Zero if the circuit is closed.
+	openUntil time.Time
+	// probing is true while the single call probing the backend in the half-open circuit is in progress.
+	probing bool
+}
+
+// NewCircuitBreaker creates a circuit breaker opening the circuits after the provided number of consecutive failures for
+// the provided cooldown period. If either the threshold or the cooldown is not positive, no circuits are ever opened and nil is
+// returned.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 || cooldown <= 0 {
+		return nil
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+// Allow returns CircuitOpenError if the backend should not be called. Otherwise, the caller must call the backend and report
+// the result using Record.
+func (b *CircuitBreaker) Allow(backend string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	c, ok := b.circuits[backend]
+	if !ok || c.openUntil.IsZero() {
+		return nil
+	}
+
+	now := b.now()
+	if now.Before(c.openUntil) || c.probing {
+		return fmt.Errorf("%w: backend %s", CircuitOpenError, backend)
+	}
+
+	// half-open, let a single call through
+	c.probing = true
+	return nil
+}
+
+// Record records the result of a call to the backend allowed by Allow.
+func (b *CircuitBreaker) Record(backend string, err error) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	c, ok := b.circuits[backend]
+	if errors.Is(err, context.Canceled) {
+		// the cancelled call says nothing about the backend. If it was the probe, the circuit stays half-open so that the next
+		// call probes the backend instead.
+		if ok {
+			c.probing = false
+		}
+		return
+	}
+
+	if !ok {
+		c = &circuit{}
+		b.circuits[backend] = c
+	}
+
+	if !isBackendFailure(err) {
+		*c = circuit{}
+		return
+	}
+
+	c.failures++
+	switch {
+	case c.probing:
+		c.probing = false
+		c.cooldown *= 2
+		if limit := b.cooldown * maxCircuitCooldownFactor; c.cooldown > limit {
+			c.cooldown = limit
+		}
+		c.openUntil = b.now().Add(c.cooldown)
+	case c.openUntil.IsZero() && c.failures >= b.threshold:
+		c.cooldown = b.cooldown
+		c.openUntil = b.now().Add(c.cooldown)
+	}
+}
+
+// isBackendFailure returns true if the provided error means that the backend is failing as opposed to the error being caused
+// by the individual object the data was requested for.
+func isBackendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return !errors.Is(err, secretstorage.NotFoundError) && !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err)
+}
@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := errors.New("backend down")
+	now := time.Now()
+	newBreaker := func() *CircuitBreaker {
+		b := NewCircuitBreaker(2, time.Minute)
+		b.now = func() time.Time { return now }
+		return b
+	}
+	at := func(b *CircuitBreaker, d time.Duration) {
+		b.now = func() time.Time { return now.Add(d) }
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, NewCircuitBreaker(0, time.Minute))
+		assert.Nil(t, NewCircuitBreaker(1, 0))
+
+		var b *CircuitBreaker
+		b.Record("vault", failure)
+		assert.NoError(t, b.Allow("vault"))
+	})
+
+	t.Run("opens after the threshold", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", failure)
+		assert.NoError(t, b.Allow("vault"))
+		b.Record("vault", failure)
+		assert.ErrorIs(t, b.Allow("vault"), CircuitOpenError)
+
+		// the circuits are per backend
+		assert.NoError(t, b.Allow("secret"))
+	})
+
+	t.Run("successes reset the failures", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", failure)
+		b.Record("vault", nil)
+		b.Record("vault", failure)
+		assert.NoError(t, b.Allow("vault"))
+	})
+
+	t.Run("object errors are not failures", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", secretstorage.NotFoundError)
+		b.Record("secret", apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "s"))
+		b.Record("secret", apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "s", errors.New("no")))
+		b.Record("vault", secretstorage.NotFoundError)
+		b.Record("secret", apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "s"))
+		assert.NoError(t, b.Allow("vault"))
+		assert.NoError(t, b.Allow("secret"))
+	})
+
+	t.Run("half-open probe", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", failure)
+		b.Record("vault", failure)
+
+		at(b, time.Minute)
+		assert.NoError(t, b.Allow("vault"))
+		// only a single probe is let through
+		assert.ErrorIs(t, b.Allow("vault"), CircuitOpenError)
+
+		t.Run("failed probe doubles the cooldown", func(t *testing.T) {
+			b.Record("vault", failure)
+			at(b, 2*time.Minute)
+			assert.ErrorIs(t, b.Allow("vault"), CircuitOpenError)
+			at(b, 3*time.Minute)
+			assert.NoError(t, b.Allow("vault"))
+		})
+
+		t.Run("successful probe closes the circuit", func(t *testing.T) {
+			b.Record("vault", nil)
+			assert.NoError(t, b.Allow("vault"))
+			assert.NoError(t, b.Allow("vault"))
+		})
+	})
+
+	t.Run("cancelled probe doesn't block the circuit", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", failure)
+		b.Record("vault", failure)
+
+		at(b, time.Minute)
+		assert.NoError(t, b.Allow("vault"))
+		b.Record("vault", fmt.Errorf("failed to get the data: %w", context.Canceled))
+
+		// the next call probes the backend again
+		assert.NoError(t, b.Allow("vault"))
+		b.Record("vault", nil)
+		assert.NoError(t, b.Allow("vault"))
+	})
+
+	t.Run("cancelled calls are not failures", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", context.Canceled)
+		b.Record("vault", context.Canceled)
+		assert.NoError(t, b.Allow("vault"))
+	})
+
+	t.Run("cooldown is limited", func(t *testing.T) {
+		b := newBreaker()
+		b.Record("vault", failure)
+		b.Record("vault", failure)
+		elapsed := time.Duration(0)
+		for i := 0; i < 10; i++ {
+			elapsed += 100 * time.Hour
+			at(b, elapsed)
+			assert.NoError(t, b.Allow("vault"))
+			b.Record("vault", failure)
+		}
+		at(b, elapsed+maxCircuitCooldownFactor*time.Minute)
+		assert.NoError(t, b.Allow("vault"))
+	})
+}
+
+func TestDataStoreRegistryCircuitBreaking(t *testing.T) {
+	calls := 0
+	ss := &secretstorage.TestSecretStorage{
+		GetImpl: func(ctx context.Context, id secretstorage.SecretID) ([]byte, error) {
+			calls++
+			return nil, errors.New("backend down")
+		},
+	}
+	r := NewRemoteSecretDataStores(remotesecretstorage.NewJSONSerializingRemoteSecretStorage(ss), nil)
+	r.Breaker = NewCircuitBreaker(2, time.Minute)
+	rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default", UID: "kachny"}}
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Get(context.TODO(), "", rs)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, CircuitOpenError)
+	}
+
+	_, err := r.Get(context.TODO(), "", rs)
+	assert.ErrorIs(t, err, CircuitOpenError)
+	assert.Equal(t, 2, calls)
+
+	_, reason, err := (&SecretDataGetter{DataStores: r}).GetData(context.TODO(), rs)
+	assert.ErrorIs(t, err, CircuitOpenError)
+	assert.Equal(t, string(api.RemoteSecretErrorReasonCircuitOpen), reason)
+}
@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The keys of the data of the cluster credentials secrets. The secret either contains the kubeconfig or the token, optionally
+// accompanied by the CA certificate of the cluster. The keys of the token and the CA certificate are the same as in
+// the service account token secrets, so those can be used directly.
+const (
+	ClusterCredentialsKubeconfigKey = "kubeconfig"
+	ClusterCredentialsTokenKey      = corev1.ServiceAccountTokenKey
+	ClusterCredentialsCAKey         = corev1.ServiceAccountRootCAKey
+)
+
+// RemoteClientFactory creates the client of the remote cluster with the provided API URL using the credentials from the secret with
+// the provided key.
+type RemoteClientFactory func(ctx context.Context, apiUrl string, credentials client.ObjectKey) (client.Client, error)
+
+// CredentialsClientFactory returns the factory of the remote cluster clients that reads the cluster credentials secrets using the provided
+// client of the local cluster. The secret is read each time a client is created so that the rotated credentials are always used.
+func CredentialsClientFactory(cl client.Client) RemoteClientFactory {
+	return func(ctx context.Context, apiUrl string, credentials client.ObjectKey) (client.Client, error) {
+		cfg, err := ClusterRESTConfig(ctx, cl, credentials, apiUrl)
+		if err != nil {
+			return nil, err
+		}
+		return NewRemoteClusterClient(cfg, cl.Scheme())
+	}
+}
+
+// ClusterRESTConfig reads the configuration of the connection to the cluster with the provided API URL from the cluster credentials
+// secret with the provided key. The API URL takes precedence over the server configured in the kubeconfig, if any.
+func ClusterRESTConfig(ctx context.Context, cl client.Client, credentials client.ObjectKey, apiUrl string) (*rest.Config, error) {
+	secret := &corev1.Secret{}
+	if err := cl.Get(ctx, credentials, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", bindings.ClusterCredentialsNotFoundError, credentials)
+		}
+		return nil, fmt.Errorf("failed to get the cluster credentials secret %s: %w", credentials, err)
+	}
+
+	var cfg *rest.Config
+	if kubeconfig := secret.Data[ClusterCredentialsKubeconfigKey]; len(kubeconfig) > 0 {
+		var err error
+		if cfg, err = clientcmd.RESTConfigFromKubeConfig(kubeconfig); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse the kubeconfig in the secret %s: %s", bindings.InvalidClusterCredentialsError, credentials, err.Error())
+		}
+	} else if token := secret.Data[ClusterCredentialsTokenKey]; len(token) > 0 {
+		cfg = &rest.Config{
+			BearerToken: string(token),
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: secret.Data[ClusterCredentialsCAKey],
+			},
+		}
+	} else {
+		return nil, fmt.Errorf("%w: the secret %s contains neither the %s nor the %s key", bindings.InvalidClusterCredentialsError, credentials,
+			ClusterCredentialsKubeconfigKey, ClusterCredentialsTokenKey)
+	}
+
+	cfg.Host = apiUrl
+	return cfg, nil
+}
+
+// NewRemoteClusterClient creates a client of the remote cluster using the provided configuration. The client only knows about
+// the objects deployed to the targets so that it doesn't need to discover the API of the cluster when created.
+func NewRemoteClusterClient(cfg *rest.Config, scheme *runtime.Scheme) (client.Client, error) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	cl, err := client.New(cfg, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to create the client of the cluster %s: %s", bindings.InvalidClusterCredentialsError, cfg.Host, err.Error())
+	}
+	return cl, nil
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://kubeconfig.server
+contexts:
+- name: ctx
+  context:
+    cluster: cluster
+    user: user
+current-context: ctx
+users:
+- name: user
+  user:
+    token: kubeconfig-token
+`
+
+func TestClusterRESTConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("tkn"), "ca.crt": []byte("ca")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{"kubeconfig": []byte(testKubeconfig)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "garbage", Namespace: "default"},
+			Data:       map[string][]byte{"kubeconfig": []byte("{")},
+		},
+	).Build()
+
+	t.Run("token", func(t *testing.T) {
+		cfg, err := ClusterRESTConfig(context.TODO(), cl, client.ObjectKey{Name: "token", Namespace: "default"}, "https://api.url")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://api.url", cfg.Host)
+		assert.Equal(t, "tkn", cfg.BearerToken)
+		assert.Equal(t, []byte("ca"), cfg.CAData)
+	})
+
+	t.Run("kubeconfig", func(t *testing.T) {
+		cfg, err := ClusterRESTConfig(context.TODO(), cl, client.ObjectKey{Name: "kubeconfig", Namespace: "default"}, "https://api.url")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://api.url", cfg.Host)
+		assert.Equal(t, "kubeconfig-token", cfg.BearerToken)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := ClusterRESTConfig(context.TODO(), cl, client.ObjectKey{Name: "missing", Namespace: "default"}, "https://api.url")
+		assert.ErrorIs(t, err, bindings.ClusterCredentialsNotFoundError)
+		assert.Equal(t, bindings.ErrorReasonClusterCredentialsNotFound, bindings.ClassifyTargetError(err))
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		_, err := ClusterRESTConfig(context.TODO(), cl, client.ObjectKey{Name: "empty", Namespace: "default"}, "https://api.url")
+		assert.ErrorIs(t, err, bindings.InvalidClusterCredentialsError)
+		assert.Equal(t, bindings.ErrorReasonInvalidClusterCredentials, bindings.ClassifyTargetError(err))
+	})
+
+	t.Run("invalid kubeconfig", func(t *testing.T) {
+		_, err := ClusterRESTConfig(context.TODO(), cl, client.ObjectKey{Name: "garbage", Namespace: "default"}, "https://api.url")
+		assert.ErrorIs(t, err, bindings.InvalidClusterCredentialsError)
+	})
+}
+
+func TestCredentialsClientFactory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("tkn")},
+		},
+	).Build()
+
+	remote, err := CredentialsClientFactory(cl)(context.TODO(), "https://api.url", client.ObjectKey{Name: "token", Namespace: "default"})
+	assert.NoError(t, err)
+	assert.NotNil(t, remote)
+}
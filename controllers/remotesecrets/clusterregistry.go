@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterNameLabel is the label of the secrets in the SecretClusterRegistry. Its value is the name of the cluster described
+	// by the secret.
+	ClusterNameLabel = "appstudio.redhat.com/cluster-name"
+	// ClusterApiUrlKey is the key of the data of the secrets in the SecretClusterRegistry containing the API URL of the cluster.
+	ClusterApiUrlKey = "apiUrl"
+)
+
+// RegisteredCluster is the cluster as found in the cluster registry.
+type RegisteredCluster struct {
+	// ApiUrl is the URL of the API server of the cluster.
+	ApiUrl string
+	// CredentialsSecret is the name of the cluster credentials secret in the namespace the cluster was looked up in.
+	CredentialsSecret string
+}
+
+// ClusterRegistry looks up the remote clusters referenced by the targets by their names. The clusters are looked up in the
+// namespace of the object the targets belong to, because the cluster credentials secrets are read from there.
+type ClusterRegistry interface {
+	// Resolve returns the cluster with the provided name registered in the provided namespace. An error wrapping
+	// bindings.ClusterNotFoundError is returned if there is no such cluster.
+	Resolve(ctx context.Context, namespace string, name string) (RegisteredCluster, error)
+}
+
+// SecretClusterRegistry is the cluster registry consisting of the secrets labeled with the ClusterNameLabel. Each secret contains
+// the API URL of the cluster under the ClusterApiUrlKey and is also used as the cluster credentials secret of it.
+type SecretClusterRegistry struct {
+	Client client.Client
+}
+
+var _ ClusterRegistry = (*SecretClusterRegistry)(nil)
+
+// Resolve implements ClusterRegistry
+func (r *SecretClusterRegistry) Resolve(ctx context.Context, namespace string, name string) (RegisteredCluster, error) {
+	if namespace == "" {
+		return RegisteredCluster{}, fmt.Errorf("%w: the clusters are only registered for the namespaced objects", bindings.InvalidClusterCredentialsError)
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets, client.InNamespace(namespace), client.MatchingLabels{ClusterNameLabel: name}); err != nil {
+		return RegisteredCluster{}, fmt.Errorf("failed to list the secrets of the cluster %s: %w", name, err)
+	}
+
+	switch len(secrets.Items) {
+	case 0:
+		return RegisteredCluster{}, fmt.Errorf("%w: %s", bindings.ClusterNotFoundError, name)
+	case 1:
+	default:
+		names := make([]string, len(secrets.Items))
+		for i := range secrets.Items {
+			names[i] = secrets.Items[i].Name
+		}
+		return RegisteredCluster{}, fmt.Errorf("%w: the cluster %s is described by more than one secret: %s", bindings.InvalidClusterCredentialsError,
+			name, strings.Join(names, ", "))
+	}
+
+	secret := &secrets.Items[0]
+	apiUrl := string(secret.Data[ClusterApiUrlKey])
+	if apiUrl == "" {
+		return RegisteredCluster{}, fmt.Errorf("%w: the secret %s of the cluster %s doesn't contain the %s key", bindings.InvalidClusterCredentialsError,
+			secret.Name, name, ClusterApiUrlKey)
+	}
+
+	return RegisteredCluster{ApiUrl: apiUrl, CredentialsSecret: secret.Name}, nil
+}
+
+// ResolveClusterTargets returns the copy of the provided targets with the API URLs and the cluster credentials secrets of
+// the targets referencing the clusters filled in from the registry. The targets the clusters of which failed to resolve are
+// left unchanged and the errors are returned in the map keyed by their indices.
+func ResolveClusterTargets(ctx context.Context, registry ClusterRegistry, namespace string, targets []api.RemoteSecretTarget) ([]api.RemoteSecretTarget, map[SpecTargetIndex]error) {
+	var errs map[SpecTargetIndex]error
+	ret := make([]api.RemoteSecretTarget, len(targets))
+	for i := range targets {
+		ret[i] = targets[i]
+		if targets[i].Cluster == "" {
+			continue
+		}
+
+		cluster, err := registry.Resolve(ctx, namespace, targets[i].Cluster)
+		if err != nil {
+			if errs == nil {
+				errs = map[SpecTargetIndex]error{}
+			}
+			errs[SpecTargetIndex(i)] = err
+			continue
+		}
+
+		ret[i].ApiUrl = cluster.ApiUrl
+		ret[i].ClusterCredentialsSecret = cluster.CredentialsSecret
+	}
+
+	return ret, errs
+}
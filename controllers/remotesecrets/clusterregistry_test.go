@@ -0,0 +1,89 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretClusterRegistry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	clusterSecret := func(name string, cluster string, apiUrl string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{ClusterNameLabel: cluster}},
+			Data:       map[string][]byte{ClusterApiUrlKey: []byte(apiUrl)},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		clusterSecret("prod", "prod", "https://prod.cluster"),
+		clusterSecret("no-url", "no-url", ""),
+		clusterSecret("dup-1", "dup", "https://dup.cluster"),
+		clusterSecret("dup-2", "dup", "https://dup.cluster"),
+	).Build()
+	r := &SecretClusterRegistry{Client: cl}
+
+	t.Run("found", func(t *testing.T) {
+		cluster, err := r.Resolve(context.TODO(), "default", "prod")
+		assert.NoError(t, err)
+		assert.Equal(t, RegisteredCluster{ApiUrl: "https://prod.cluster", CredentialsSecret: "prod"}, cluster)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := r.Resolve(context.TODO(), "default", "staging")
+		assert.ErrorIs(t, err, bindings.ClusterNotFoundError)
+
+		// the clusters are only looked up in the provided namespace
+		_, err = r.Resolve(context.TODO(), "other", "prod")
+		assert.ErrorIs(t, err, bindings.ClusterNotFoundError)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := r.Resolve(context.TODO(), "default", "no-url")
+		assert.ErrorIs(t, err, bindings.InvalidClusterCredentialsError)
+
+		_, err = r.Resolve(context.TODO(), "default", "dup")
+		assert.ErrorIs(t, err, bindings.InvalidClusterCredentialsError)
+
+		_, err = r.Resolve(context.TODO(), "", "prod")
+		assert.ErrorIs(t, err, bindings.InvalidClusterCredentialsError)
+	})
+
+	t.Run("resolve targets", func(t *testing.T) {
+		targets := []api.RemoteSecretTarget{{Namespace: "a"}, {Namespace: "b", Cluster: "prod"}, {Namespace: "c", Cluster: "staging"}}
+		resolved, errs := ResolveClusterTargets(context.TODO(), r, "default", targets)
+
+		assert.Equal(t, targets[0], resolved[0])
+		assert.Equal(t, "https://prod.cluster", resolved[1].ApiUrl)
+		assert.Equal(t, "prod", resolved[1].ClusterCredentialsSecret)
+		assert.Empty(t, resolved[2].ApiUrl)
+		assert.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[2], bindings.ClusterNotFoundError)
+
+		// the provided targets are not modified
+		assert.Empty(t, targets[1].ApiUrl)
+	})
+}
@@ -0,0 +1,183 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CacheableDataStore is implemented by the data stores the data of which can be cached in the DataCache.
+type CacheableDataStore[K any] interface {
+	DataStore[K]
+	// CacheKey returns the identity of the data at the provided location for the provided object. The data obtained for the same
+	// cache key must be the same. An empty key means that the data cannot be cached.
+	CacheKey(location *url.URL, obj K) string
+}
+
+// DataCache is an in-memory cache of the secret data obtained from the data stores. The entries expire after the configured TTL
+// and the number of entries is limited by the configured maximum size. The data is never written anywhere else than to memory.
+//
+// The nil cache is valid and doesn't cache anything.
+type DataCache struct {
+	ttl     time.Duration
+	maxSize int
+	now     func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]dataCacheEntry
+}
+
+type dataCacheEntry struct {
+	owners  map[types.UID]bool
+	data    remotesecretstorage.SecretData
+	expires time.Time
+}
+
+// NewDataCache creates a new data cache with the provided TTL of the entries and the maximum number of entries. If either the TTL
+// or the maximum size is not positive, the caching is disabled and nil is returned.
+func NewDataCache(ttl time.Duration, maxSize int) *DataCache {
+	if ttl <= 0 || maxSize <= 0 {
+		return nil
+	}
+
+	return &DataCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+		entries: map[string]dataCacheEntry{},
+	}
+}
+
+// Get returns a copy of the cached data for the provided key if it has not expired yet.
+func (c *DataCache) Get(key string) (*remotesecretstorage.SecretData, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	data := copySecretData(entry.data)
+	return &data, true
+}
+
+// Put caches a copy of the provided data under the provided key. The owner is the UID of the object the data was obtained for and
+// can be used to invalidate all the data cached for the object. The other objects reading the same data are recorded using Share.
+// If the cache is full, the expired entries and then the entries closest to their expiration are evicted.
+func (c *DataCache) Put(key string, owner types.UID, data *remotesecretstorage.SecretData) {
+	if c == nil || data == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxSize {
+		c.evict(now)
+	}
+
+	c.entries[key] = dataCacheEntry{
+		owners:  map[types.UID]bool{owner: true},
+		data:    copySecretData(*data),
+		expires: now.Add(c.ttl),
+	}
+}
+
+// Share records the object with the provided UID as another owner of the data cached under the provided key, so that the data
+// shared by multiple objects is invalidated together with the data of any of them.
+func (c *DataCache) Share(key string, owner types.UID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		entry.owners[owner] = true
+	}
+}
+
+// Invalidate removes all the data cached for the object with the provided UID.
+func (c *DataCache) Invalidate(owner types.UID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for k, e := range c.entries {
+		if e.owners[owner] {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// InvalidateKey removes the data cached under the provided key, e.g. when the source of the data changes.
+func (c *DataCache) InvalidateKey(key string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+}
+
+// evict makes room for a new entry. It must be called with the lock held.
+func (c *DataCache) evict(now time.Time) {
+	oldestKey := ""
+	var oldestExpiry time.Time
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.expires.Before(oldestExpiry) {
+			oldestKey = k
+			oldestExpiry = e.expires
+		}
+	}
+
+	if len(c.entries) >= c.maxSize {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func copySecretData(data remotesecretstorage.SecretData) remotesecretstorage.SecretData {
+	ret := make(remotesecretstorage.SecretData, len(data))
+	for k, v := range data {
+		ret[k] = append([]byte(nil), v...)
+	}
+	return ret
+}
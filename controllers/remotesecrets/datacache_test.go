@@ -0,0 +1,193 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/memorystorage"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDataCache(t *testing.T) {
+	now := time.Now()
+	newCache := func(maxSize int) *DataCache {
+		c := NewDataCache(time.Minute, maxSize)
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, NewDataCache(0, 10))
+		assert.Nil(t, NewDataCache(time.Minute, 0))
+
+		var c *DataCache
+		c.Put("k", "uid", &remotesecretstorage.SecretData{"a": []byte("b")})
+		_, ok := c.Get("k")
+		assert.False(t, ok)
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		c := newCache(10)
+		c.Put("k", "uid", &remotesecretstorage.SecretData{"a": []byte("b")})
+
+		data, ok := c.Get("k")
+		assert.True(t, ok)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("b")}, *data)
+
+		c.now = func() time.Time { return now.Add(time.Minute) }
+		_, ok = c.Get("k")
+		assert.False(t, ok)
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		c := newCache(10)
+		original := remotesecretstorage.SecretData{"a": []byte("b")}
+		c.Put("k", "uid", &original)
+		original["a"][0] = 'x'
+
+		data, _ := c.Get("k")
+		(*data)["a"] = []byte("y")
+
+		data, _ = c.Get("k")
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("b")}, *data)
+	})
+
+	t.Run("evicts the oldest entries", func(t *testing.T) {
+		c := newCache(2)
+		c.Put("1", "uid", &remotesecretstorage.SecretData{})
+		c.now = func() time.Time { return now.Add(time.Second) }
+		c.Put("2", "uid", &remotesecretstorage.SecretData{})
+		c.Put("3", "uid", &remotesecretstorage.SecretData{})
+
+		_, ok := c.Get("1")
+		assert.False(t, ok)
+		_, ok = c.Get("2")
+		assert.True(t, ok)
+		_, ok = c.Get("3")
+		assert.True(t, ok)
+	})
+
+	t.Run("invalidates by owner", func(t *testing.T) {
+		c := newCache(10)
+		c.Put("1", "a", &remotesecretstorage.SecretData{})
+		c.Put("2", "b", &remotesecretstorage.SecretData{})
+
+		c.Invalidate("a")
+
+		_, ok := c.Get("1")
+		assert.False(t, ok)
+		_, ok = c.Get("2")
+		assert.True(t, ok)
+	})
+
+	t.Run("invalidates shared entries by any owner", func(t *testing.T) {
+		c := newCache(10)
+		c.Put("1", "a", &remotesecretstorage.SecretData{})
+		c.Share("1", "b")
+
+		c.Invalidate("b")
+
+		_, ok := c.Get("1")
+		assert.False(t, ok)
+	})
+
+	t.Run("invalidates by key", func(t *testing.T) {
+		c := newCache(10)
+		c.Put("1", "a", &remotesecretstorage.SecretData{})
+		c.Put("2", "a", &remotesecretstorage.SecretData{})
+
+		c.InvalidateKey("1")
+
+		_, ok := c.Get("1")
+		assert.False(t, ok)
+		_, ok = c.Get("2")
+		assert.True(t, ok)
+	})
+}
+
+func TestDataStoreRegistryCaching(t *testing.T) {
+	calls := 0
+	ss := &secretstorage.TestSecretStorage{
+		GetImpl: func(ctx context.Context, id secretstorage.SecretID) ([]byte, error) {
+			calls++
+			return []byte(`{"a": "Yg=="}`), nil
+		},
+	}
+	r := NewRemoteSecretDataStores(remotesecretstorage.NewJSONSerializingRemoteSecretStorage(ss), nil)
+	r.Cache = NewDataCache(time.Minute, 10)
+	rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{UID: "kachny", ResourceVersion: "1"}}
+
+	for i := 0; i < 3; i++ {
+		data, err := r.Get(context.TODO(), "", rs)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("b"), (*data)["a"])
+	}
+	assert.Equal(t, 1, calls)
+
+	// the updates of the status of the object don't change the data
+	rs.ResourceVersion = "2"
+	_, err := r.Get(context.TODO(), "", rs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	r.InvalidateCache(rs)
+	_, err = r.Get(context.TODO(), "", rs)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// the re-created object has its own data
+	rs.UID = "other"
+	_, err = r.Get(context.TODO(), "", rs)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDataStoreRegistryCachingConsumedData(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+	assert.NoError(t, storage.Initialize(context.TODO()))
+	r := NewRemoteSecretDataStores(storage, cl)
+	r.Cache = NewDataCache(time.Minute, 10)
+	rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default", UID: "rs-uid"}}
+
+	assert.NoError(t, storage.Store(context.TODO(), rs, &remotesecretstorage.SecretData{"a": []byte("old")}))
+	data, err := r.Get(context.TODO(), "", rs)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("old"), (*data)["a"])
+
+	assert.NoError(t, cl.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "upload", Namespace: "default"},
+		Data:       map[string][]byte{"a": []byte("new")},
+	}))
+	_, err = r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "upload", ConsumeUploadData: true}), rs)
+	assert.NoError(t, err)
+
+	// the consumed data replaced the cached data in the storage
+	data, err = r.Get(context.TODO(), "", rs)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("new"), (*data)["a"])
+}
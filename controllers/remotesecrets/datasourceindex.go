@@ -0,0 +1,141 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DataSourceIndexField is the name of the field index of the remote secrets by the secrets and external secrets they copy the data
+// from. See DataSourceIndexValues.
+const DataSourceIndexField = "dataSources"
+
+// DataSourceIndexValues returns the values of the DataSourceIndexField of the provided remote secret, i.e. the identities of the secrets
+// and the external secrets in its namespace that it copies the data from.
+func DataSourceIndexValues(rs *api.RemoteSecret) []string {
+	return dataSourceIndexValues(&rs.Spec, false)
+}
+
+// ClusterDataSourceIndexValues returns the values of the DataSourceIndexField of the provided cluster remote secret, i.e. the identities
+// of the secrets and the external secrets it copies the data from prefixed with their namespaces.
+func ClusterDataSourceIndexValues(crs *api.ClusterRemoteSecret) []string {
+	return dataSourceIndexValues(&crs.Spec.RemoteSecretSpec, true)
+}
+
+func dataSourceIndexValues(spec *api.RemoteSecretSpec, withNamespace bool) []string {
+	var ret []string
+	for _, uri := range DataSourcesOf(spec).URIs {
+		location, err := ParseDataStoreURI(uri)
+		if err != nil {
+			continue
+		}
+		prefix := ""
+		if withNamespace {
+			namespace := location.Query().Get(dataSourceNamespaceParam)
+			if namespace == "" {
+				continue
+			}
+			prefix = namespace + "/"
+		}
+		switch location.Scheme {
+		case SecretDataStoreScheme, ConsumedSecretDataStoreScheme:
+			ret = append(ret, prefix+"Secret/"+location.Host)
+		case ExternalSecretDataStoreScheme:
+			ret = append(ret, prefix+string(api.DataFromKindExternalSecret)+"/"+location.Host)
+		}
+	}
+	return ret
+}
+
+// secretDataSourceIndexValues returns the values of the DataSourceIndexField of the remote secrets that copy the data from the provided
+// secret, either directly or through the external secret owning it.
+func secretDataSourceIndexValues(secret *corev1.Secret) []string {
+	ret := []string{"Secret/" + secret.Name}
+	for _, ref := range secret.OwnerReferences {
+		if ref.Kind == ExternalSecretGroupVersionKind.Kind && strings.HasPrefix(ref.APIVersion, ExternalSecretGroupVersionKind.Group+"/") {
+			ret = append(ret, string(api.DataFromKindExternalSecret)+"/"+ref.Name)
+		}
+	}
+	return ret
+}
+
+// DataSourceCacheKeys returns the keys of the data cached for the data stores copying the data from the provided secret, either
+// directly or through the external secret owning it. The data cached under these keys needs to be invalidated when the secret
+// changes.
+func DataSourceCacheKeys(secret *corev1.Secret) []string {
+	ret := []string{dataSourceCacheKey(SecretDataStoreScheme, secret.Namespace, secret.Name)}
+	for _, ref := range secret.OwnerReferences {
+		if ref.Kind == ExternalSecretGroupVersionKind.Kind && strings.HasPrefix(ref.APIVersion, ExternalSecretGroupVersionKind.Group+"/") {
+			ret = append(ret, dataSourceCacheKey(ExternalSecretDataStoreScheme, secret.Namespace, ref.Name))
+		}
+	}
+	return ret
+}
+
+// dataSourceCacheKey is the key of the data cached for the data source with the provided scheme and name in the provided namespace.
+func dataSourceCacheKey(scheme, namespace, name string) string {
+	if namespace == "" {
+		return ""
+	}
+	return scheme + "://" + namespace + "/" + name
+}
+
+// RemoteSecretsCopyingFrom lists the remote secrets copying the data from the provided secret using the DataSourceIndexField that needs
+// to be configured in the provided client.
+func RemoteSecretsCopyingFrom(ctx context.Context, cl client.Client, secret *corev1.Secret) ([]client.ObjectKey, error) {
+	seen := map[client.ObjectKey]bool{}
+	var ret []client.ObjectKey
+	for _, value := range secretDataSourceIndexValues(secret) {
+		list := &api.RemoteSecretList{}
+		if err := cl.List(ctx, list, client.InNamespace(secret.Namespace), client.MatchingFields{DataSourceIndexField: value}); err != nil {
+			return nil, fmt.Errorf("failed to list the remote secrets copying the data from %s: %w", value, err)
+		}
+		for i := range list.Items {
+			key := client.ObjectKeyFromObject(&list.Items[i])
+			if !seen[key] {
+				seen[key] = true
+				ret = append(ret, key)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// ClusterRemoteSecretsCopyingFrom lists the cluster remote secrets copying the data from the provided secret using the DataSourceIndexField
+// that needs to be configured in the provided client.
+func ClusterRemoteSecretsCopyingFrom(ctx context.Context, cl client.Client, secret *corev1.Secret) ([]client.ObjectKey, error) {
+	seen := map[client.ObjectKey]bool{}
+	var ret []client.ObjectKey
+	for _, value := range secretDataSourceIndexValues(secret) {
+		list := &api.ClusterRemoteSecretList{}
+		if err := cl.List(ctx, list, client.MatchingFields{DataSourceIndexField: secret.Namespace + "/" + value}); err != nil {
+			return nil, fmt.Errorf("failed to list the cluster remote secrets copying the data from %s/%s: %w", secret.Namespace, value, err)
+		}
+		for i := range list.Items {
+			key := client.ObjectKeyFromObject(&list.Items[i])
+			if !seen[key] {
+				seen[key] = true
+				ret = append(ret, key)
+			}
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,472 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// LocalDataStoreScheme is the URI scheme of the data store backed by the secret storage of the operator, i.e. the place
+	// where the data uploaded to the remote secrets are stored.
+	LocalDataStoreScheme = "local"
+	// SecretDataStoreScheme is the URI scheme of the data store that copies the data from a secret in the namespace of the remote
+	// secret. The host part of the URI is the name of the secret, e.g. "secret://my-secret".
+	SecretDataStoreScheme = "secret"
+	// ConfigMapDataStoreScheme is the URI scheme of the data store that copies the data from a config map in the namespace of
+	// the remote secret. The host part of the URI is the name of the config map, e.g. "configmap://my-config-map".
+	ConfigMapDataStoreScheme = "configmap"
+	// ConsumedSecretDataStoreScheme is the URI scheme of the data store that moves the data from a secret in the namespace of the
+	// remote secret into the secret storage of the operator and deletes the secret afterwards. The host part of the URI is the name
+	// of the secret, e.g. "consumed-secret://my-secret".
+	ConsumedSecretDataStoreScheme = "consumed-secret"
+	// ExternalSecretDataStoreScheme is the URI scheme of the data store that copies the data from the secret produced by
+	// an ExternalSecret of the External Secrets Operator in the namespace of the remote secret. The host part of the URI is
+	// the name of the ExternalSecret, e.g. "externalsecret://my-external-secret".
+	ExternalSecretDataStoreScheme = "externalsecret"
+)
+
+var (
+	InvalidDataStoreURIError         = errors.New("invalid data store URI")
+	UnknownDataStoreSchemeError      = errors.New("no data store registered for the scheme")
+	DataStoreAlreadyRegisteredError  = errors.New("a data store is already registered for the scheme")
+	DataSourceNamespaceMissingError  = errors.New("the namespace of the object to copy the data from is not specified")
+	DataSourceNamespaceMismatchError = errors.New("the data can only be copied from objects in the namespace of the remote secret")
+	DataSourceKeyConflictError       = errors.New("multiple data sources contain the same key")
+	ConsumedDataSourceMergeError     = errors.New("the data of a consumed secret cannot be merged with other data sources")
+)
+
+// DataStore is a backend from which the secret data of the objects of type K can be obtained.
+type DataStore[K any] interface {
+	// Get returns the secret data of the provided object from the provided location. The location is the URI with the scheme
+	// of the data store. If there is no data, secretstorage.NotFoundError must be returned.
+	Get(ctx context.Context, location *url.URL, obj K) (*remotesecretstorage.SecretData, error)
+}
+
+// DataStoreRegistry resolves the data store URIs to the data stores registered for their schemes.
+type DataStoreRegistry[K any] struct {
+	// Cache is the optional cache of the data obtained from the data stores implementing the CacheableDataStore interface.
+	Cache *DataCache
+	// Breaker is the optional circuit breaker stopping the calls to the failing backends of the data stores.
+	Breaker *CircuitBreaker
+	stores  map[string]DataStore[K]
+}
+
+// Register registers the data store for the provided URI scheme. Only a single data store can be registered for a scheme.
+func (r *DataStoreRegistry[K]) Register(scheme string, store DataStore[K]) error {
+	if r.stores == nil {
+		r.stores = map[string]DataStore[K]{}
+	}
+
+	if _, ok := r.stores[scheme]; ok {
+		return fmt.Errorf("%w: %s", DataStoreAlreadyRegisteredError, scheme)
+	}
+
+	r.stores[scheme] = store
+	return nil
+}
+
+// Resolve parses the provided URI and finds the data store registered for its scheme.
+func (r *DataStoreRegistry[K]) Resolve(uri string) (DataStore[K], *url.URL, error) {
+	location, err := ParseDataStoreURI(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, ok := r.stores[location.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", UnknownDataStoreSchemeError, location.Scheme)
+	}
+
+	return store, location, nil
+}
+
+// Get obtains the secret data of the provided object from the data store resolved from the provided URI.
+func (r *DataStoreRegistry[K]) Get(ctx context.Context, uri string, obj K) (*remotesecretstorage.SecretData, error) {
+	store, location, err := r.Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := ""
+	if cacheable, ok := store.(CacheableDataStore[K]); ok && r.Cache != nil {
+		cacheKey = cacheable.CacheKey(location, obj)
+	}
+	if cacheKey != "" {
+		if data, ok := r.Cache.Get(cacheKey); ok {
+			r.Cache.Share(cacheKey, objectUID(obj))
+			return data, nil
+		}
+	}
+
+	if err := r.Breaker.Allow(location.Scheme); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	data, err := store.Get(ctx, location, obj)
+	dataStoreGetTimeMetric.WithLabelValues(location.Scheme, dataStoreOutcome(err)).Observe(time.Since(start).Seconds())
+	r.Breaker.Record(location.Scheme, err)
+
+	if err == nil && cacheKey != "" {
+		r.Cache.Put(cacheKey, objectUID(obj), data)
+	}
+
+	if _, ok := store.(localDataWriter); ok && err == nil {
+		// the data cached for the object might not match the storage anymore
+		r.InvalidateCache(obj)
+	}
+
+	return data, err //nolint:wrapcheck // the errors from the data stores need to be inspected by the callers
+}
+
+// localDataWriter is implemented by the data stores that write the data they obtain to the storage read by the LocalDataStore.
+type localDataWriter interface {
+	writesLocalData()
+}
+
+// InvalidateCache removes all the cached data of the provided object.
+func (r *DataStoreRegistry[K]) InvalidateCache(obj K) {
+	r.Cache.Invalidate(objectUID(obj))
+}
+
+// GetMerged obtains the secret data of the provided object from all the provided data sources and merges it into a single
+// data map. The keys with the tombstone value of the data sources are removed from the merged data.
+func (r *DataStoreRegistry[K]) GetMerged(ctx context.Context, sources DataSources, obj K) (*remotesecretstorage.SecretData, error) {
+	if len(sources.URIs) <= 1 {
+		uri := ""
+		if len(sources.URIs) == 1 {
+			uri = sources.URIs[0]
+		}
+		data, err := r.Get(ctx, uri, obj)
+		if err != nil || data == nil || sources.Tombstone == "" {
+			return data, err
+		}
+		for k, v := range *data {
+			if string(v) == sources.Tombstone {
+				delete(*data, k)
+			}
+		}
+		return data, nil
+	}
+
+	ret := remotesecretstorage.SecretData{}
+	keySources := map[string]string{}
+	for _, uri := range sources.URIs {
+		// the consumed data is kept in the local storage of the object, which can only hold the data of a single source.
+		if strings.HasPrefix(uri, ConsumedSecretDataStoreScheme+"://") {
+			return nil, fmt.Errorf("%w: %s", ConsumedDataSourceMergeError, uri)
+		}
+
+		data, err := r.Get(ctx, uri, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain the data from the data source %s: %w", uri, err)
+		}
+		if data == nil {
+			continue
+		}
+
+		for k, v := range *data {
+			if sources.Tombstone != "" && string(v) == sources.Tombstone {
+				delete(ret, k)
+				delete(keySources, k)
+				continue
+			}
+			if previous, ok := keySources[k]; ok && sources.ConflictPolicy == api.DataSourcesConflictPolicyError {
+				return nil, fmt.Errorf("%w: the key %s is in both %s and %s", DataSourceKeyConflictError, k, previous, uri)
+			}
+			keySources[k] = uri
+			ret[k] = v
+		}
+	}
+
+	return &ret, nil
+}
+
+// objectUID returns the UID of the provided object or an empty string if it is not a kubernetes object.
+func objectUID(obj any) types.UID {
+	if o, ok := obj.(metav1.Object); ok {
+		return o.GetUID()
+	}
+	return ""
+}
+
+// ParseDataStoreURI parses the provided URI of a data store. The URI must have a scheme, e.g. "vault://path/to/secret". An empty
+// URI is interpreted as the local data store.
+func ParseDataStoreURI(uri string) (*url.URL, error) {
+	if uri == "" {
+		return &url.URL{Scheme: LocalDataStoreScheme}, nil
+	}
+
+	location, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidDataStoreURIError, err.Error())
+	}
+
+	if location.Scheme == "" {
+		return nil, fmt.Errorf("%w: the scheme is missing in %s", InvalidDataStoreURIError, uri)
+	}
+
+	return location, nil
+}
+
+// LocalDataStore is the data store reading the data from the secret storage of the operator. The location is ignored, because the
+// data is stored under the key of the object.
+type LocalDataStore[T any] struct {
+	Storage secretstorage.TypedSecretStorage[T, remotesecretstorage.SecretData]
+}
+
+var _ CacheableDataStore[*api.RemoteSecret] = (*LocalDataStore[api.RemoteSecret])(nil)
+
+func (s *LocalDataStore[T]) Get(ctx context.Context, _ *url.URL, obj *T) (*remotesecretstorage.SecretData, error) {
+	return s.Storage.Get(ctx, obj) //nolint:wrapcheck // the NotFoundError needs to be inspected by the callers
+}
+
+// CacheKey implements CacheableDataStore. The data is identified by the object it belongs to. The resource version of the object is
+// deliberately not part of the key, because it changes with every update of the status. Instead, the cached data is invalidated
+// whenever the data is written to the storage by the operator and when the forced sync is requested.
+func (s *LocalDataStore[T]) CacheKey(_ *url.URL, obj *T) string {
+	o, ok := any(obj).(metav1.Object)
+	if !ok || o.GetUID() == "" {
+		return ""
+	}
+	return LocalDataStoreScheme + "://" + o.GetNamespace() + "/" + o.GetName() + "/" + string(o.GetUID())
+}
+
+// ObjectDataStore is the data store copying the data from a secret or a config map in the namespace of the object the data is
+// obtained for. The name of the secret or config map is the host part of the location.
+type ObjectDataStore[K client.Object] struct {
+	Client client.Client
+	Kind   api.DataFromKind
+}
+
+var _ CacheableDataStore[*api.RemoteSecret] = (*ObjectDataStore[*api.RemoteSecret])(nil)
+
+func (s *ObjectDataStore[K]) Get(ctx context.Context, location *url.URL, obj K) (*remotesecretstorage.SecretData, error) {
+	namespace, err := DataSourceNamespace(location, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Name: location.Host, Namespace: namespace}
+	data := remotesecretstorage.SecretData{}
+
+	if s.Kind == api.DataFromKindConfigMap {
+		cm := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, key, cm); err != nil {
+			return nil, fmt.Errorf("failed to get the config map %s to copy the data from: %w", key, err)
+		}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+	} else {
+		secret := &corev1.Secret{}
+		if err := s.Client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get the secret %s to copy the data from: %w", key, err)
+		}
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+	}
+
+	return &data, nil
+}
+
+// CacheKey implements CacheableDataStore. The data is identified by the secret or config map it is copied from, so all the objects
+// copying the data from the same source share the cached data. The cached data of a secret is invalidated when the secret changes
+// (see DataSourceCacheKeys), the data of a config map expires with the TTL of the cache.
+func (s *ObjectDataStore[K]) CacheKey(location *url.URL, obj K) string {
+	namespace, err := DataSourceNamespace(location, obj)
+	if err != nil {
+		return ""
+	}
+	return dataSourceCacheKey(location.Scheme, namespace, location.Host)
+}
+
+// ConsumingDataStore is the data store moving the data from a secret in the namespace of the remote secret to the secret storage
+// of the operator. Once the data is stored, the secret is deleted. If the secret doesn't exist, the data is read from the storage,
+// so that the data stays available after the secret has been consumed.
+type ConsumingDataStore struct {
+	Client  client.Client
+	Storage remotesecretstorage.RemoteSecretStorage
+}
+
+var _ DataStore[*api.RemoteSecret] = (*ConsumingDataStore)(nil)
+
+// writesLocalData marks the ConsumingDataStore as writing to the storage read by the LocalDataStore.
+func (s *ConsumingDataStore) writesLocalData() {}
+
+func (s *ConsumingDataStore) Get(ctx context.Context, location *url.URL, obj *api.RemoteSecret) (*remotesecretstorage.SecretData, error) {
+	namespace, err := DataSourceNamespace(location, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Name: location.Host, Namespace: namespace}
+	secret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the secret has most probably been consumed already
+			return s.Storage.Get(ctx, obj) //nolint:wrapcheck // the NotFoundError needs to be inspected by the callers
+		}
+		return nil, fmt.Errorf("failed to get the secret %s to consume the data from: %w", key, err)
+	}
+
+	data := remotesecretstorage.SecretData{}
+	for k, v := range secret.Data {
+		data[k] = v
+	}
+
+	if err := s.Storage.Store(ctx, obj, &data); err != nil {
+		return nil, fmt.Errorf("failed to store the data consumed from the secret %s: %w", key, err)
+	}
+
+	// only delete the exact secret we read the data from. If it changed in the meantime, we will consume it again in the next
+	// reconciliation.
+	uid := secret.UID
+	resourceVersion := secret.ResourceVersion
+	if err := s.Client.Delete(ctx, secret, &client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid, ResourceVersion: &resourceVersion}}); err != nil && !apierrors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "failed to delete the consumed secret", "secret", key)
+	}
+
+	return &data, nil
+}
+
+// DataStoreURI returns the URI of the data store to obtain the data from as specified by the provided data source. The namespace
+// of the data source, if specified, is passed in the "namespace" query parameter, e.g. "secret://my-secret?namespace=my-ns".
+func DataStoreURI(dataFrom *api.DataFrom) string {
+	if dataFrom == nil {
+		return ""
+	}
+
+	query := ""
+	if dataFrom.Namespace != "" {
+		query = "?" + url.Values{dataSourceNamespaceParam: []string{dataFrom.Namespace}}.Encode()
+	}
+
+	if dataFrom.Kind == api.DataFromKindConfigMap {
+		return ConfigMapDataStoreScheme + "://" + dataFrom.Name + query
+	}
+	if dataFrom.Kind == api.DataFromKindExternalSecret {
+		return ExternalSecretDataStoreScheme + "://" + dataFrom.Name + query
+	}
+	if dataFrom.ConsumeUploadData {
+		return ConsumedSecretDataStoreScheme + "://" + dataFrom.Name + query
+	}
+	return SecretDataStoreScheme + "://" + dataFrom.Name + query
+}
+
+// dataSourceNamespaceParam is the query parameter of the data store URIs specifying the namespace of the data source.
+const dataSourceNamespaceParam = "namespace"
+
+// DataSourceNamespace returns the namespace of the object the data is copied from for the provided object. The namespaced objects
+// can only copy the data from their own namespace. The cluster-scoped objects need to specify the namespace in the location.
+func DataSourceNamespace(location *url.URL, obj metav1.Object) (string, error) {
+	namespace := location.Query().Get(dataSourceNamespaceParam)
+	if obj.GetNamespace() != "" {
+		if namespace != "" && namespace != obj.GetNamespace() {
+			return "", fmt.Errorf("%w: %s", DataSourceNamespaceMismatchError, namespace)
+		}
+		return obj.GetNamespace(), nil
+	}
+	if namespace == "" {
+		return "", DataSourceNamespaceMissingError
+	}
+	return namespace, nil
+}
+
+// DataSources describes all the data stores the data is obtained from and how their data is merged.
+type DataSources struct {
+	// URIs are the URIs of the data stores in the order in which their data is merged. If empty, the local data store is used.
+	URIs []string
+	// ConflictPolicy specifies what happens if multiple data stores contain the same key.
+	ConflictPolicy api.DataSourcesConflictPolicy
+	// Tombstone is the value marking the keys as deleted. The keys with this value are removed from the merged data. Empty means
+	// that no value is a tombstone.
+	Tombstone string
+}
+
+// DataSourcesOf returns the data sources specified by the provided spec.
+func DataSourcesOf(spec *api.RemoteSecretSpec) DataSources {
+	ret := DataSources{ConflictPolicy: spec.DataSourcesConflictPolicy, Tombstone: spec.KeyTombstone}
+	if spec.DataFrom != nil || len(spec.DataSources) == 0 {
+		ret.URIs = append(ret.URIs, DataStoreURI(spec.DataFrom))
+	}
+	for i := range spec.DataSources {
+		ret.URIs = append(ret.URIs, DataStoreURI(&spec.DataSources[i]))
+	}
+	return ret
+}
+
+// Ref returns the human-readable description of the data stores in the order in which their data is merged. The local data
+// store is described as "local://".
+func (s DataSources) Ref() string {
+	refs := make([]string, 0, len(s.URIs))
+	for _, uri := range s.URIs {
+		if uri == "" {
+			uri = LocalDataStoreScheme + "://"
+		}
+		refs = append(refs, uri)
+	}
+	if len(refs) == 0 {
+		refs = append(refs, LocalDataStoreScheme+"://")
+	}
+	return strings.Join(refs, ",")
+}
+
+// NewRemoteSecretDataStores creates the data store registry for the remote secrets with the local data store and the data stores
+// copying (or consuming) the data from the secrets and config maps registered.
+func NewRemoteSecretDataStores(storage remotesecretstorage.RemoteSecretStorage, cl client.Client) *DataStoreRegistry[*api.RemoteSecret] {
+	ret := &DataStoreRegistry[*api.RemoteSecret]{}
+	// this cannot fail on an empty registry with distinct schemes
+	_ = ret.Register(LocalDataStoreScheme, &LocalDataStore[api.RemoteSecret]{Storage: storage})
+	_ = ret.Register(SecretDataStoreScheme, &ObjectDataStore[*api.RemoteSecret]{Client: cl, Kind: api.DataFromKindSecret})
+	_ = ret.Register(ConfigMapDataStoreScheme, &ObjectDataStore[*api.RemoteSecret]{Client: cl, Kind: api.DataFromKindConfigMap})
+	_ = ret.Register(ConsumedSecretDataStoreScheme, &ConsumingDataStore{Client: cl, Storage: storage})
+	_ = ret.Register(ExternalSecretDataStoreScheme, &ExternalSecretDataStore[*api.RemoteSecret]{Client: cl})
+	return ret
+}
+
+// NewClusterRemoteSecretDataStores creates the data store registry for the cluster remote secrets with the local data store and the data
+// stores copying the data from the secrets, config maps and external secrets registered. The cluster remote secrets have no namespace of
+// their own, so the namespace of the object to copy the data from must be specified in the data source. The data cannot be consumed,
+// because the consumed data could not be uploaded again in any other way.
+func NewClusterRemoteSecretDataStores(storage remotesecretstorage.ClusterRemoteSecretStorage, cl client.Client) *DataStoreRegistry[*api.ClusterRemoteSecret] {
+	ret := &DataStoreRegistry[*api.ClusterRemoteSecret]{}
+	// this cannot fail on an empty registry with distinct schemes
+	_ = ret.Register(LocalDataStoreScheme, &LocalDataStore[api.ClusterRemoteSecret]{Storage: storage})
+	_ = ret.Register(SecretDataStoreScheme, &ObjectDataStore[*api.ClusterRemoteSecret]{Client: cl, Kind: api.DataFromKindSecret})
+	_ = ret.Register(ConfigMapDataStoreScheme, &ObjectDataStore[*api.ClusterRemoteSecret]{Client: cl, Kind: api.DataFromKindConfigMap})
+	_ = ret.Register(ExternalSecretDataStoreScheme, &ExternalSecretDataStore[*api.ClusterRemoteSecret]{Client: cl})
+	return ret
+}
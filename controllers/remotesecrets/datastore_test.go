@@ -0,0 +1,277 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/memorystorage"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type testDataStore struct {
+	location *url.URL
+}
+
+func (s *testDataStore) Get(_ context.Context, location *url.URL, _ *api.RemoteSecret) (*remotesecretstorage.SecretData, error) {
+	s.location = location
+	return &remotesecretstorage.SecretData{"a": []byte("b")}, nil
+}
+
+func TestParseDataStoreURI(t *testing.T) {
+	t.Run("empty is local", func(t *testing.T) {
+		u, err := ParseDataStoreURI("")
+		assert.NoError(t, err)
+		assert.Equal(t, LocalDataStoreScheme, u.Scheme)
+	})
+
+	t.Run("with scheme", func(t *testing.T) {
+		u, err := ParseDataStoreURI("vault://secret/path/to/data")
+		assert.NoError(t, err)
+		assert.Equal(t, "vault", u.Scheme)
+		assert.Equal(t, "secret", u.Host)
+		assert.Equal(t, "/path/to/data", u.Path)
+	})
+
+	t.Run("missing scheme", func(t *testing.T) {
+		_, err := ParseDataStoreURI("path/to/data")
+		assert.ErrorIs(t, err, InvalidDataStoreURIError)
+	})
+
+	t.Run("unparseable", func(t *testing.T) {
+		_, err := ParseDataStoreURI("vault://%zz")
+		assert.ErrorIs(t, err, InvalidDataStoreURIError)
+	})
+}
+
+func TestDataSourcesRef(t *testing.T) {
+	assert.Equal(t, "local://", DataSourcesOf(&api.RemoteSecretSpec{}).Ref())
+	assert.Equal(t, "secret://a,configmap://b", DataSourcesOf(&api.RemoteSecretSpec{
+		DataSources: []api.DataFrom{{Name: "a"}, {Kind: api.DataFromKindConfigMap, Name: "b"}},
+	}).Ref())
+}
+
+func TestDataStoreRegistry(t *testing.T) {
+	t.Run("resolves by scheme", func(t *testing.T) {
+		store := &testDataStore{}
+		r := &DataStoreRegistry[*api.RemoteSecret]{}
+		assert.NoError(t, r.Register("test", store))
+
+		data, err := r.Get(context.TODO(), "test://host/path", &api.RemoteSecret{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("b"), (*data)["a"])
+		assert.Equal(t, "host", store.location.Host)
+		assert.Equal(t, "/path", store.location.Path)
+	})
+
+	t.Run("unknown scheme", func(t *testing.T) {
+		r := &DataStoreRegistry[*api.RemoteSecret]{}
+		_, err := r.Get(context.TODO(), "test://host/path", &api.RemoteSecret{})
+		assert.ErrorIs(t, err, UnknownDataStoreSchemeError)
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		r := &DataStoreRegistry[*api.RemoteSecret]{}
+		assert.NoError(t, r.Register("test", &testDataStore{}))
+		assert.ErrorIs(t, r.Register("test", &testDataStore{}), DataStoreAlreadyRegisteredError)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		ss := &secretstorage.TestSecretStorage{
+			GetImpl: func(ctx context.Context, id secretstorage.SecretID) ([]byte, error) {
+				assert.Equal(t, "kachny", string(id.Uid))
+				return []byte(`{"a": "Yg=="}`), nil
+			},
+		}
+		r := NewRemoteSecretDataStores(remotesecretstorage.NewJSONSerializingRemoteSecretStorage(ss), nil)
+
+		data, err := r.Get(context.TODO(), "", &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{UID: "kachny"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("b"), (*data)["a"])
+	})
+
+	t.Run("objects", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		assert.NoError(t, corev1.AddToScheme(scheme))
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+				Data:       map[string][]byte{"a": []byte("secret")},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+				Data:       map[string]string{"a": "cm"},
+				BinaryData: map[string][]byte{"b": []byte("binary")},
+			},
+		).Build()
+		r := NewRemoteSecretDataStores(nil, cl)
+		rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+
+		data, err := r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "source"}), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("secret")}, *data)
+
+		data, err = r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Kind: api.DataFromKindConfigMap, Name: "source"}), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("cm"), "b": []byte("binary")}, *data)
+
+		_, err = r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "missing"}), rs)
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("objects of cluster remote secrets", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		assert.NoError(t, corev1.AddToScheme(scheme))
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "sources"},
+				Data:       map[string][]byte{"a": []byte("secret")},
+			},
+		).Build()
+		r := NewClusterRemoteSecretDataStores(nil, cl)
+		crs := &api.ClusterRemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "crs"}}
+
+		data, err := r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "source", Namespace: "sources"}), crs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("secret")}, *data)
+
+		_, err = r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "source"}), crs)
+		assert.ErrorIs(t, err, DataSourceNamespaceMissingError)
+
+		// the consumed data could never be uploaded again
+		_, err = r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "source", Namespace: "sources", ConsumeUploadData: true}), crs)
+		assert.ErrorIs(t, err, UnknownDataStoreSchemeError)
+	})
+
+	t.Run("remote secrets only copy from their namespace", func(t *testing.T) {
+		r := NewRemoteSecretDataStores(nil, fake.NewClientBuilder().Build())
+		rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+
+		_, err := r.Get(context.TODO(), DataStoreURI(&api.DataFrom{Name: "source", Namespace: "other"}), rs)
+		assert.ErrorIs(t, err, DataSourceNamespaceMismatchError)
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		assert.NoError(t, corev1.AddToScheme(scheme))
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"},
+				Data:       map[string][]byte{"tls.crt": []byte("cert"), "shared": []byte("first")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "password", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("pass"), "shared": []byte("second")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "deletions", Namespace: "default"},
+				Data:       map[string][]byte{"tls.crt": []byte("<deleted>")},
+			},
+		).Build()
+		r := NewRemoteSecretDataStores(nil, cl)
+		rs := &api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"},
+			Spec: api.RemoteSecretSpec{
+				DataSources: []api.DataFrom{{Name: "tls"}, {Name: "password"}},
+			},
+		}
+
+		data, err := r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{
+			"tls.crt":  []byte("cert"),
+			"password": []byte("pass"),
+			"shared":   []byte("second"),
+		}, *data)
+
+		rs.Spec.DataSourcesConflictPolicy = api.DataSourcesConflictPolicyError
+		_, err = r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.ErrorIs(t, err, DataSourceKeyConflictError)
+
+		rs.Spec.DataSourcesConflictPolicy = api.DataSourcesConflictPolicyLastWins
+		rs.Spec.DataSources = append(rs.Spec.DataSources, api.DataFrom{Name: "deletions"})
+		rs.Spec.KeyTombstone = "<deleted>"
+		data, err = r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"password": []byte("pass"), "shared": []byte("second")}, *data)
+
+		// the tombstones don't conflict with the values
+		rs.Spec.DataSources = []api.DataFrom{{Name: "tls"}, {Name: "deletions"}}
+		rs.Spec.DataSourcesConflictPolicy = api.DataSourcesConflictPolicyError
+		data, err = r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"shared": []byte("first")}, *data)
+
+		// a single data source is cleared of the tombstones, too
+		rs.Spec.DataSources = []api.DataFrom{{Name: "deletions"}}
+		data, err = r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.NoError(t, err)
+		assert.Empty(t, *data)
+		rs.Spec.KeyTombstone = ""
+
+		rs.Spec.DataSources = append(rs.Spec.DataSources, api.DataFrom{Name: "missing"})
+		rs.Spec.DataSourcesConflictPolicy = api.DataSourcesConflictPolicyLastWins
+		_, err = r.GetMerged(context.TODO(), DataSourcesOf(&rs.Spec), rs)
+		assert.True(t, errors.IsNotFound(err))
+		assert.Contains(t, err.Error(), "secret://missing")
+	})
+
+	t.Run("consumed", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		assert.NoError(t, corev1.AddToScheme(scheme))
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "upload", Namespace: "default"},
+				Data:       map[string][]byte{"a": []byte("secret")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			},
+		).Build()
+		storage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&memorystorage.MemoryStorage{})
+		assert.NoError(t, storage.Initialize(context.TODO()))
+		r := NewRemoteSecretDataStores(storage, cl)
+		rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default", UID: "rs-uid"}}
+		uri := DataStoreURI(&api.DataFrom{Name: "upload", ConsumeUploadData: true})
+
+		data, err := r.Get(context.TODO(), uri, rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("secret")}, *data)
+
+		err = cl.Get(context.TODO(), client.ObjectKey{Name: "upload", Namespace: "default"}, &corev1.Secret{})
+		assert.True(t, errors.IsNotFound(err))
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "other", Namespace: "default"}, &corev1.Secret{}))
+
+		stored, err := storage.Get(context.TODO(), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("secret")}, *stored)
+
+		// the data is still available after the secret has been consumed
+		data, err = r.Get(context.TODO(), uri, rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("secret")}, *data)
+	})
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ExternalSecretGroupVersionKind is the kind of the ExternalSecrets of the External Secrets Operator that the data can be
+// copied from.
+var ExternalSecretGroupVersionKind = schema.GroupVersionKind{Group: "external-secrets.io", Version: "v1beta1", Kind: "ExternalSecret"}
+
+var ExternalSecretNotReadyError = errors.New("the external secret is not ready")
+
+// ExternalSecretDataStore is the data store copying the data from the secret produced by an ExternalSecret of the External Secrets
+// Operator. The External Secrets Operator resolves the data from its backends, while we deploy it to the targets. The data is only
+// copied once the ExternalSecret is ready.
+type ExternalSecretDataStore[K client.Object] struct {
+	Client client.Client
+}
+
+func (s *ExternalSecretDataStore[K]) Get(ctx context.Context, location *url.URL, obj K) (*remotesecretstorage.SecretData, error) {
+	namespace, err := DataSourceNamespace(location, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Name: location.Host, Namespace: namespace}
+	es := &unstructured.Unstructured{}
+	es.SetGroupVersionKind(ExternalSecretGroupVersionKind)
+	if err := s.Client.Get(ctx, key, es); err != nil {
+		return nil, fmt.Errorf("failed to get the external secret %s to copy the data from: %w", key, err)
+	}
+
+	if ready, message := externalSecretReady(es); !ready {
+		return nil, fmt.Errorf("%w: %s: %s", ExternalSecretNotReadyError, key, message)
+	}
+
+	secretKey := client.ObjectKey{Name: ExternalSecretTargetName(es), Namespace: namespace}
+	secret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, secretKey, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the secret %s produced by the external secret %s: %w", secretKey, key.Name, err)
+	}
+
+	data := remotesecretstorage.SecretData{}
+	for k, v := range secret.Data {
+		data[k] = v
+	}
+	return &data, nil
+}
+
+// CacheKey implements CacheableDataStore. The data is identified by the ExternalSecret it is copied from, so all the objects copying
+// the data from the same ExternalSecret share the cached data. The cached data is invalidated when the secret produced by the
+// ExternalSecret changes (see DataSourceCacheKeys).
+func (s *ExternalSecretDataStore[K]) CacheKey(location *url.URL, obj K) string {
+	namespace, err := DataSourceNamespace(location, obj)
+	if err != nil {
+		return ""
+	}
+	return dataSourceCacheKey(location.Scheme, namespace, location.Host)
+}
+
+// ExternalSecretTargetName returns the name of the secret produced by the provided ExternalSecret. It is the name of the ExternalSecret
+// unless specified otherwise.
+func ExternalSecretTargetName(es *unstructured.Unstructured) string {
+	if name, _, _ := unstructured.NestedString(es.Object, "spec", "target", "name"); name != "" {
+		return name
+	}
+	return es.GetName()
+}
+
+// externalSecretReady returns true if the Ready condition of the ExternalSecret is true. Otherwise, the message of the condition
+// is returned, too.
+func externalSecretReady(es *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(es.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		if cond["status"] == "True" {
+			return true, ""
+		}
+		message, _ := cond["message"].(string)
+		return false, message
+	}
+	return false, "the external secret has not been processed yet"
+}
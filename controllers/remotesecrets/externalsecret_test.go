@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newExternalSecret(name string, targetName string, ready string) *unstructured.Unstructured {
+	es := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{},
+		"status": map[string]any{
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": ready, "message": "not synced"},
+			},
+		},
+	}}
+	es.SetGroupVersionKind(ExternalSecretGroupVersionKind)
+	es.SetName(name)
+	es.SetNamespace("default")
+	if targetName != "" {
+		_ = unstructured.SetNestedField(es.Object, targetName, "spec", "target", "name")
+	}
+	return es
+}
+
+func TestExternalSecretDataStore(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newExternalSecret("ready", "", "True"),
+		newExternalSecret("renamed", "produced", "True"),
+		newExternalSecret("failing", "", "False"),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default"},
+			Data:       map[string][]byte{"a": []byte("ready")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "produced", Namespace: "default"},
+			Data:       map[string][]byte{"a": []byte("produced")},
+		},
+	).Build()
+	r := NewRemoteSecretDataStores(nil, cl)
+	rs := &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+	uri := func(name string) string {
+		return DataStoreURI(&api.DataFrom{Kind: api.DataFromKindExternalSecret, Name: name})
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		data, err := r.Get(context.TODO(), uri("ready"), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("ready")}, *data)
+	})
+
+	t.Run("target name", func(t *testing.T) {
+		data, err := r.Get(context.TODO(), uri("renamed"), rs)
+		assert.NoError(t, err)
+		assert.Equal(t, remotesecretstorage.SecretData{"a": []byte("produced")}, *data)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		_, err := r.Get(context.TODO(), uri("failing"), rs)
+		assert.ErrorIs(t, err, ExternalSecretNotReadyError)
+		assert.Contains(t, err.Error(), "not synced")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := r.Get(context.TODO(), uri("missing"), rs)
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestRemoteSecretsCopyingFrom(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	newRs := func(name string, dataFrom ...api.DataFrom) *api.RemoteSecret {
+		return &api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       api.RemoteSecretSpec{DataSources: dataFrom},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&api.RemoteSecret{}, DataSourceIndexField, func(o client.Object) []string {
+			return DataSourceIndexValues(o.(*api.RemoteSecret))
+		}).
+		WithObjects(
+			newRs("direct", api.DataFrom{Name: "produced"}),
+			newRs("external", api.DataFrom{Kind: api.DataFromKindExternalSecret, Name: "es"}),
+			newRs("both", api.DataFrom{Name: "produced"}, api.DataFrom{Kind: api.DataFromKindExternalSecret, Name: "es"}),
+			newRs("other", api.DataFrom{Name: "other"}, api.DataFrom{Kind: api.DataFromKindConfigMap, Name: "produced"}),
+		).Build()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "produced",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "external-secrets.io/v1beta1", Kind: "ExternalSecret", Name: "es"},
+			},
+		},
+	}
+
+	keys, err := RemoteSecretsCopyingFrom(context.TODO(), cl, secret)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []client.ObjectKey{
+		{Name: "direct", Namespace: "default"},
+		{Name: "external", Namespace: "default"},
+		{Name: "both", Namespace: "default"},
+	}, keys)
+}
+
+func TestClusterRemoteSecretsCopyingFrom(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	newCrs := func(name string, dataFrom ...api.DataFrom) *api.ClusterRemoteSecret {
+		return &api.ClusterRemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       api.ClusterRemoteSecretSpec{RemoteSecretSpec: api.RemoteSecretSpec{DataSources: dataFrom}},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&api.ClusterRemoteSecret{}, DataSourceIndexField, func(o client.Object) []string {
+			return ClusterDataSourceIndexValues(o.(*api.ClusterRemoteSecret))
+		}).
+		WithObjects(
+			newCrs("direct", api.DataFrom{Name: "produced", Namespace: "default"}),
+			newCrs("external", api.DataFrom{Kind: api.DataFromKindExternalSecret, Name: "es", Namespace: "default"}),
+			newCrs("other-namespace", api.DataFrom{Name: "produced", Namespace: "other"}),
+		).Build()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "produced",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "external-secrets.io/v1beta1", Kind: "ExternalSecret", Name: "es"},
+			},
+		},
+	}
+
+	keys, err := ClusterRemoteSecretsCopyingFrom(context.TODO(), cl, secret)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []client.ObjectKey{{Name: "direct"}, {Name: "external"}}, keys)
+}
@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"errors"
+	"fmt"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var KeyFilterExcludesRequiredKeyError = errors.New("the key filter excludes a key required by the secret type")
+
+// ApplyKeyFilter returns the subset of the provided data that passes the provided filter. The data is returned
+// unchanged if the filter is nil. It is an error if the filter removes a key from the data that is required by
+// the provided secret type.
+func ApplyKeyFilter(filter *api.KeyFilter, secretType corev1.SecretType, data map[string][]byte) (map[string][]byte, error) {
+	if filter == nil {
+		return data, nil
+	}
+
+	included := make(map[string]bool, len(filter.Include))
+	for _, k := range filter.Include {
+		included[k] = true
+	}
+
+	excluded := make(map[string]bool, len(filter.Exclude))
+	for _, k := range filter.Exclude {
+		excluded[k] = true
+	}
+
+	ret := make(map[string][]byte, len(data))
+	for k, v := range data {
+		if excluded[k] || (len(included) > 0 && !included[k]) {
+			continue
+		}
+		ret[k] = v
+	}
+
+	for _, k := range bindings.RequiredKeys(secretType) {
+		_, wasPresent := data[k]
+		_, isPresent := ret[k]
+		if wasPresent && !isPresent {
+			return nil, fmt.Errorf("%w: key %s of secret type %s", KeyFilterExcludesRequiredKeyError, k, secretType)
+		}
+	}
+
+	return ret, nil
+}
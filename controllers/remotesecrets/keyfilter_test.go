@@ -0,0 +1,68 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestApplyKeyFilter(t *testing.T) {
+	data := map[string][]byte{
+		"a":     []byte("a"),
+		"b":     []byte("b"),
+		"admin": []byte("admin"),
+	}
+
+	t.Run("nil filter", func(t *testing.T) {
+		ret, err := ApplyKeyFilter(nil, corev1.SecretTypeOpaque, data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, ret)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		ret, err := ApplyKeyFilter(&api.KeyFilter{Exclude: []string{"admin"}}, corev1.SecretTypeOpaque, data)
+		assert.NoError(t, err)
+		assert.Len(t, ret, 2)
+		assert.NotContains(t, ret, "admin")
+	})
+
+	t.Run("include", func(t *testing.T) {
+		ret, err := ApplyKeyFilter(&api.KeyFilter{Include: []string{"a", "admin"}}, corev1.SecretTypeOpaque, data)
+		assert.NoError(t, err)
+		assert.Len(t, ret, 2)
+		assert.Contains(t, ret, "a")
+		assert.Contains(t, ret, "admin")
+	})
+
+	t.Run("exclude takes precedence", func(t *testing.T) {
+		ret, err := ApplyKeyFilter(&api.KeyFilter{Include: []string{"a", "admin"}, Exclude: []string{"admin"}}, corev1.SecretTypeOpaque, data)
+		assert.NoError(t, err)
+		assert.Len(t, ret, 1)
+		assert.Contains(t, ret, "a")
+	})
+
+	t.Run("required key excluded", func(t *testing.T) {
+		tlsData := map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		}
+		_, err := ApplyKeyFilter(&api.KeyFilter{Exclude: []string{corev1.TLSPrivateKeyKey}}, corev1.SecretTypeTLS, tlsData)
+		assert.ErrorIs(t, err, KeyFilterExcludesRequiredKeyError)
+	})
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var RedundantApiUrlError = errors.New("the apiUrl of the target points at the cluster the operator runs in")
+
+// inClusterApiHosts are the host names of the API server of the cluster as seen from within the cluster.
+var inClusterApiHosts = map[string]bool{
+	"kubernetes":                           true,
+	"kubernetes.default":                   true,
+	"kubernetes.default.svc":               true,
+	"kubernetes.default.svc.cluster.local": true,
+}
+
+// IsLocalApiUrl returns true if the provided API URL of a target points at the API server of the local cluster with the provided
+// URL. The in-cluster names of the API server are always considered local. Such targets are equivalent to the targets without
+// the API URL.
+func IsLocalApiUrl(apiUrl string, localApiUrl string) bool {
+	if apiUrl == "" {
+		return true
+	}
+
+	target, ok := normalizeApiUrl(apiUrl)
+	if !ok {
+		return false
+	}
+
+	if inClusterApiHosts[strings.TrimSuffix(target.Hostname(), ".")] {
+		return true
+	}
+
+	local, ok := normalizeApiUrl(localApiUrl)
+	return ok && *target == *local
+}
+
+// normalizeApiUrl parses the provided API URL and normalizes it so that the URLs pointing at the same API server can be compared.
+func normalizeApiUrl(apiUrl string) (*url.URL, bool) {
+	if apiUrl == "" {
+		return nil, false
+	}
+	if !strings.Contains(apiUrl, "://") {
+		// the kubernetes clients also accept the bare host names
+		apiUrl = "https://" + apiUrl
+	}
+
+	u, err := url.Parse(apiUrl)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if scheme == "http" {
+			port = "80"
+		}
+	}
+
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port), Path: strings.TrimSuffix(u.Path, "/")}, true
+}
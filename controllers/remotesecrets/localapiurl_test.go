@@ -0,0 +1,39 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLocalApiUrl(t *testing.T) {
+	local := "https://api.cluster.example.com:6443"
+
+	assert.True(t, IsLocalApiUrl("", local))
+	assert.True(t, IsLocalApiUrl("https://api.cluster.example.com:6443", local))
+	assert.True(t, IsLocalApiUrl("HTTPS://API.Cluster.Example.com:6443/", local))
+	assert.True(t, IsLocalApiUrl("api.cluster.example.com:6443", local))
+	assert.True(t, IsLocalApiUrl("https://kubernetes.default.svc", local))
+	assert.True(t, IsLocalApiUrl("https://kubernetes.default.svc.cluster.local:443", ""))
+
+	assert.False(t, IsLocalApiUrl("https://api.cluster.example.com", local))
+	assert.False(t, IsLocalApiUrl("https://api.other.example.com:6443", local))
+	assert.False(t, IsLocalApiUrl("https://api.other.example.com:6443", ""))
+	assert.False(t, IsLocalApiUrl("https://%zz", local))
+
+	assert.True(t, IsLocalApiUrl("https://10.0.0.1", "https://10.0.0.1:443"))
+}
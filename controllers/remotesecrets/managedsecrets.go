@@ -0,0 +1,68 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+)
+
+// ManagedSecretInventory computes the inventory of the secrets managed by a remote secret from the statuses of its targets. The targets
+// that currently have no secret recorded in their status (e.g. because the last deployment to them failed) keep their entries from
+// the previous inventory, because their secrets may still exist. The entries of the targets no longer in the status are dropped.
+// Nothing is managed in the Contribute deployment mode, so the inventory is always empty in that case.
+func ManagedSecretInventory(mode api.SecretDeploymentMode, previous []api.ManagedSecretReference, targets []api.TargetStatus) []api.ManagedSecretReference {
+	if mode == api.SecretDeploymentModeContribute {
+		return nil
+	}
+
+	previousByTarget := map[TargetKey][]api.ManagedSecretReference{}
+	for _, ref := range previous {
+		key := TargetKey{ApiUrl: ref.ApiUrl, Namespace: ref.Namespace}
+		previousByTarget[key] = append(previousByTarget[key], ref)
+	}
+
+	var ret []api.ManagedSecretReference
+	seen := map[api.ManagedSecretReference]bool{}
+	add := func(ref api.ManagedSecretReference) {
+		if !seen[ref] {
+			seen[ref] = true
+			ret = append(ret, ref)
+		}
+	}
+
+	for i := range targets {
+		ts := &targets[i]
+		if ts.SecretName != "" {
+			add(api.ManagedSecretReference{ApiUrl: ts.ApiUrl, Namespace: ts.Namespace, Name: ts.SecretName})
+			continue
+		}
+		for _, ref := range previousByTarget[TargetKey{ApiUrl: ts.ApiUrl, Namespace: ts.Namespace}] {
+			add(ref)
+		}
+	}
+
+	return ret
+}
+
+// InventoriedSecretName returns the name of the secret recorded in the inventory for the target with the provided API URL and
+// namespace or an empty string if there is none.
+func InventoriedSecretName(inventory []api.ManagedSecretReference, apiUrl, namespace string) string {
+	for _, ref := range inventory {
+		if ref.ApiUrl == apiUrl && ref.Namespace == namespace {
+			return ref.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagedSecretInventory(t *testing.T) {
+	t.Run("from statuses", func(t *testing.T) {
+		inv := ManagedSecretInventory(api.SecretDeploymentModeManage, nil, []api.TargetStatus{
+			{Namespace: "a", SecretName: "s-a"},
+			{ApiUrl: "https://cluster", Namespace: "b", SecretName: "s-b"},
+			{Namespace: "c", Error: "failed"},
+		})
+
+		assert.Equal(t, []api.ManagedSecretReference{
+			{Namespace: "a", Name: "s-a"},
+			{ApiUrl: "https://cluster", Namespace: "b", Name: "s-b"},
+		}, inv)
+	})
+
+	t.Run("keeps the secrets of failed targets", func(t *testing.T) {
+		previous := []api.ManagedSecretReference{
+			{Namespace: "a", Name: "old"},
+			{Namespace: "b", Name: "s-b"},
+			{Namespace: "removed", Name: "s-removed"},
+		}
+		inv := ManagedSecretInventory(api.SecretDeploymentModeManage, previous, []api.TargetStatus{
+			{Namespace: "a", SecretName: "new"},
+			{Namespace: "b", Error: "failed"},
+		})
+
+		assert.Equal(t, []api.ManagedSecretReference{
+			{Namespace: "a", Name: "new"},
+			{Namespace: "b", Name: "s-b"},
+		}, inv)
+	})
+
+	t.Run("nothing managed when contributing", func(t *testing.T) {
+		inv := ManagedSecretInventory(api.SecretDeploymentModeContribute, nil, []api.TargetStatus{
+			{Namespace: "a", SecretName: "s-a"},
+		})
+		assert.Empty(t, inv)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		inv := []api.ManagedSecretReference{{ApiUrl: "https://cluster", Namespace: "a", Name: "s-a"}}
+		assert.Equal(t, "s-a", InventoriedSecretName(inv, "https://cluster", "a"))
+		assert.Empty(t, InventoriedSecretName(inv, "", "a"))
+	})
+}
@@ -0,0 +1,60 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redhat-appstudio/remote-secret/pkg/config"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	dataStoreOutcomeSuccess  = "success"
+	dataStoreOutcomeNotFound = "not_found"
+	dataStoreOutcomeError    = "error"
+)
+
+var dataStoreGetTimeMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: config.MetricsNamespace,
+	Subsystem: config.MetricsSubsystem,
+	Name:      "data_store_get_time_seconds",
+	Help:      "The time it takes to obtain the secret data from the data stores categorized by the scheme of the data store and the outcome",
+}, []string{"backend", "outcome"})
+
+// RegisterMetrics registers the metrics of the data stores with the provided registerer.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	if err := registerer.Register(dataStoreGetTimeMetric); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			return fmt.Errorf("failed to register the data store get time metric: %w", err)
+		}
+	}
+	return nil
+}
+
+// dataStoreOutcome categorizes the result of the data store Get call for the metrics.
+func dataStoreOutcome(err error) string {
+	switch {
+	case err == nil:
+		return dataStoreOutcomeSuccess
+	case errors.Is(err, secretstorage.NotFoundError) || apierrors.IsNotFound(err):
+		return dataStoreOutcomeNotFound
+	default:
+		return dataStoreOutcomeError
+	}
+}
@@ -0,0 +1,53 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDataStoreMetrics(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, RegisterMetrics(registry))
+	assert.NoError(t, RegisterMetrics(registry))
+	dataStoreGetTimeMetric.Reset()
+
+	r := NewRemoteSecretDataStores(remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&secretstorage.TestSecretStorage{
+		GetImpl: func(ctx context.Context, id secretstorage.SecretID) ([]byte, error) {
+			if id.Uid == "missing" {
+				return nil, secretstorage.NotFoundError
+			}
+			return []byte(`{"a": "Yg=="}`), nil
+		},
+	}), nil)
+
+	_, err := r.Get(context.TODO(), "", &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{UID: "present"}})
+	assert.NoError(t, err)
+	_, err = r.Get(context.TODO(), "", &api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{UID: "missing"}})
+	assert.ErrorIs(t, err, secretstorage.NotFoundError)
+
+	// one series for each of the outcomes
+	assert.Equal(t, 2, testutil.CollectAndCount(dataStoreGetTimeMetric))
+	assert.Equal(t, dataStoreOutcomeError, dataStoreOutcome(UnknownDataStoreSchemeError))
+}
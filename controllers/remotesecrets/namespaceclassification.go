@@ -53,8 +53,13 @@ type NamespaceClassification struct {
 // Targets in spec that do not have a corresponding status (i.e. the new targets that have not yet been
 // deployed to) have the status index set to -1 in the returned classification's Sync map.
 func ClassifyTargetNamespaces(rs *api.RemoteSecret) NamespaceClassification {
-	specIndices, duplicateSpecs := specNamespaceIndices(rs.Spec.Targets)
-	statusIndices, duplicateStatuses := statusNamespaceIndices(rs.Status.Targets)
+	return ClassifyTargets(rs.Spec.Targets, rs.Status.Targets)
+}
+
+// ClassifyTargets is the same as ClassifyTargetNamespaces but works with the explicitly provided spec and status targets.
+func ClassifyTargets(specTargets []api.RemoteSecretTarget, statusTargets []api.TargetStatus) NamespaceClassification {
+	specIndices, duplicateSpecs := specNamespaceIndices(specTargets)
+	statusIndices, duplicateStatuses := statusNamespaceIndices(statusTargets)
 
 	ret := NamespaceClassification{
 		Sync:                 map[SpecTargetIndex]StatusTargetIndex{},
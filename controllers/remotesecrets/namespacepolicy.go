@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TargetNamespacePolicyType is the type of the restriction of the namespaces the remote secrets can deploy to.
+type TargetNamespacePolicyType string
+
+const (
+	// TargetNamespacePolicyAny allows the remote secrets to deploy to any namespace. This is the default.
+	TargetNamespacePolicyAny TargetNamespacePolicyType = "any"
+	// TargetNamespacePolicyOwnNamespace only allows the remote secrets to deploy to their own namespace.
+	TargetNamespacePolicyOwnNamespace TargetNamespacePolicyType = "own-namespace"
+	// TargetNamespacePolicySameTenant only allows the remote secrets to deploy to the namespaces with the same value of the tenant
+	// label as their own namespace.
+	TargetNamespacePolicySameTenant TargetNamespacePolicyType = "same-tenant"
+)
+
+var (
+	TargetNamespaceNotAllowedError       = errors.New("the remote secret is not allowed to deploy to the namespace")
+	UnknownTargetNamespacePolicyError    = errors.New("unknown target namespace policy")
+	TargetNamespaceTenantLabelEmptyError = errors.New("the tenant label must be configured for the same-tenant target namespace policy")
+)
+
+// TargetNamespacePolicy restricts the namespaces in the local cluster the remote secrets can deploy to. The targets in the remote
+// clusters are not restricted, because the permissions there are given by the credentials used to connect to them.
+type TargetNamespacePolicy struct {
+	Type TargetNamespacePolicyType
+	// TenantLabel is the label of the namespaces the value of which identifies the tenant owning the namespace. It is required
+	// by the same-tenant policy.
+	TenantLabel string
+}
+
+// Validate checks that the policy is well-formed.
+func (p TargetNamespacePolicy) Validate() error {
+	switch p.Type {
+	case "", TargetNamespacePolicyAny, TargetNamespacePolicyOwnNamespace:
+		return nil
+	case TargetNamespacePolicySameTenant:
+		if p.TenantLabel == "" {
+			return TargetNamespaceTenantLabelEmptyError
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", UnknownTargetNamespacePolicyError, p.Type)
+	}
+}
+
+// Check returns TargetNamespaceNotAllowedError if the remote secret in the provided namespace is not allowed to deploy to
+// the provided target. Other errors are returned if the namespaces cannot be inspected.
+func (p TargetNamespacePolicy) Check(ctx context.Context, cl client.Client, namespace string, target *api.RemoteSecretTarget) error {
+	if target.ApiUrl != "" || target.Namespace == namespace {
+		return nil
+	}
+
+	switch p.Type {
+	case TargetNamespacePolicyOwnNamespace:
+		return fmt.Errorf("%w %s: only the namespace of the remote secret can be deployed to", TargetNamespaceNotAllowedError, target.Namespace)
+	case TargetNamespacePolicySameTenant:
+		tenant, err := p.tenantOf(ctx, cl, namespace)
+		if err != nil {
+			return err
+		}
+		targetTenant, err := p.tenantOf(ctx, cl, target.Namespace)
+		if err != nil {
+			return err
+		}
+		if tenant == "" || tenant != targetTenant {
+			return fmt.Errorf("%w %s: the namespace doesn't belong to the same tenant (label %s) as the namespace of the remote secret",
+				TargetNamespaceNotAllowedError, target.Namespace, p.TenantLabel)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (p TargetNamespacePolicy) tenantOf(ctx context.Context, cl client.Client, namespace string) (string, error) {
+	ns := &corev1.Namespace{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return "", fmt.Errorf("failed to get the namespace %s to check its tenant: %w", namespace, err)
+	}
+	return ns.Labels[p.TenantLabel], nil
+}
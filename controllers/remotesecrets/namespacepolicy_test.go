@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTargetNamespacePolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a-1", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a-2", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b-1", Labels: map[string]string{"tenant": "b"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "none"}},
+	).Build()
+
+	check := func(policy TargetNamespacePolicy, namespace string, target api.RemoteSecretTarget) error {
+		return policy.Check(context.TODO(), cl, namespace, &target)
+	}
+
+	t.Run("any", func(t *testing.T) {
+		assert.NoError(t, check(TargetNamespacePolicy{}, "a-1", api.RemoteSecretTarget{Namespace: "b-1"}))
+		assert.NoError(t, check(TargetNamespacePolicy{Type: TargetNamespacePolicyAny}, "a-1", api.RemoteSecretTarget{Namespace: "b-1"}))
+	})
+
+	t.Run("own namespace", func(t *testing.T) {
+		policy := TargetNamespacePolicy{Type: TargetNamespacePolicyOwnNamespace}
+		assert.NoError(t, check(policy, "a-1", api.RemoteSecretTarget{Namespace: "a-1"}))
+		assert.ErrorIs(t, check(policy, "a-1", api.RemoteSecretTarget{Namespace: "a-2"}), TargetNamespaceNotAllowedError)
+		assert.NoError(t, check(policy, "a-1", api.RemoteSecretTarget{Namespace: "b-1", ApiUrl: "https://other.cluster"}))
+	})
+
+	t.Run("same tenant", func(t *testing.T) {
+		policy := TargetNamespacePolicy{Type: TargetNamespacePolicySameTenant, TenantLabel: "tenant"}
+		assert.NoError(t, check(policy, "a-1", api.RemoteSecretTarget{Namespace: "a-2"}))
+		assert.ErrorIs(t, check(policy, "a-1", api.RemoteSecretTarget{Namespace: "b-1"}), TargetNamespaceNotAllowedError)
+		assert.ErrorIs(t, check(policy, "none", api.RemoteSecretTarget{Namespace: "a-1"}), TargetNamespaceNotAllowedError)
+
+		err := check(policy, "a-1", api.RemoteSecretTarget{Namespace: "missing"})
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, TargetNamespacePolicy{}.Validate())
+		assert.NoError(t, TargetNamespacePolicy{Type: TargetNamespacePolicyOwnNamespace}.Validate())
+		assert.ErrorIs(t, TargetNamespacePolicy{Type: TargetNamespacePolicySameTenant}.Validate(), TargetNamespaceTenantLabelEmptyError)
+		assert.ErrorIs(t, TargetNamespacePolicy{Type: "nonsense"}.Validate(), UnknownTargetNamespacePolicyError)
+	})
+}
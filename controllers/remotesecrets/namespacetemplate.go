@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	InvalidTargetNamespaceTemplateError = errors.New("invalid target namespace template")
+	TargetNamespaceNotRenderedError     = errors.New("failed to render the target namespace")
+)
+
+// TargetNamespaceTemplate derives the namespace to deploy to from a namespace selected by a cluster remote secret.
+type TargetNamespaceTemplate struct {
+	template *template.Template
+}
+
+// targetNamespaceTemplateValues are the values the TargetNamespaceTemplate can reference.
+type targetNamespaceTemplateValues struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// ParseTargetNamespaceTemplate parses the provided template. Only the builtin functions of text/template are available to the template.
+func ParseTargetNamespaceTemplate(text string) (*TargetNamespaceTemplate, error) {
+	tmpl, err := template.New("targetNamespace").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidTargetNamespaceTemplateError, err.Error())
+	}
+	return &TargetNamespaceTemplate{template: tmpl}, nil
+}
+
+// Render returns the name of the namespace derived from the provided namespace. TargetNamespaceNotRenderedError is returned if the template
+// references a label or an annotation the namespace doesn't have or if the result is not a valid namespace name.
+func (t *TargetNamespaceTemplate) Render(ns *corev1.Namespace) (string, error) {
+	values := targetNamespaceTemplateValues{
+		Name:        ns.Name,
+		Labels:      ns.Labels,
+		Annotations: ns.Annotations,
+	}
+	// the templates need to see the empty maps rather than nils so that the missing keys are reported consistently
+	if values.Labels == nil {
+		values.Labels = map[string]string{}
+	}
+	if values.Annotations == nil {
+		values.Annotations = map[string]string{}
+	}
+
+	buf := bytes.Buffer{}
+	if err := t.template.Execute(&buf, values); err != nil {
+		return "", fmt.Errorf("%w: %s", TargetNamespaceNotRenderedError, err.Error())
+	}
+
+	name := strings.TrimSpace(buf.String())
+	if problems := validation.IsDNS1123Label(name); len(problems) > 0 {
+		return "", fmt.Errorf("%w: %q is not a valid namespace name: %s", TargetNamespaceNotRenderedError, name, strings.Join(problems, ", "))
+	}
+
+	return name, nil
+}
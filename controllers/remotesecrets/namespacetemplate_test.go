@@ -0,0 +1,66 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTargetNamespaceTemplate(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Labels:      map[string]string{"team": "kachny"},
+			Annotations: map[string]string{"suffix": "shared"},
+		},
+	}
+
+	t.Run("renders", func(t *testing.T) {
+		tmpl, err := ParseTargetNamespaceTemplate("{{ .Labels.team }}-{{ .Annotations.suffix }}")
+		assert.NoError(t, err)
+
+		name, err := tmpl.Render(ns)
+		assert.NoError(t, err)
+		assert.Equal(t, "kachny-shared", name)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := ParseTargetNamespaceTemplate("{{ .Labels.team ")
+		assert.ErrorIs(t, err, InvalidTargetNamespaceTemplateError)
+	})
+
+	t.Run("missing label", func(t *testing.T) {
+		tmpl, err := ParseTargetNamespaceTemplate("{{ .Labels.owner }}-shared")
+		assert.NoError(t, err)
+
+		_, err = tmpl.Render(ns)
+		assert.ErrorIs(t, err, TargetNamespaceNotRenderedError)
+
+		_, err = tmpl.Render(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}})
+		assert.ErrorIs(t, err, TargetNamespaceNotRenderedError)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		tmpl, err := ParseTargetNamespaceTemplate("{{ .Name }}_SHARED")
+		assert.NoError(t, err)
+
+		_, err = tmpl.Render(ns)
+		assert.ErrorIs(t, err, TargetNamespaceNotRenderedError)
+	})
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DeleteOrphanedManagedSecrets deletes the secrets in the cluster of the provided client that are managed by the remote secret with
+// the provided key but live in namespaces that are not among the provided targets. Such secrets are left behind when the cleanup of
+// a removed target doesn't happen, e.g. because the target was removed from the status before its secret could be deleted.
+//
+// Only the namespaces the remote secret is known to have deployed to, i.e. the namespaces of the provided previous target statuses
+// and of the previous inventory of the managed secrets, are searched so that the reconciliation doesn't need to list the secrets
+// in the whole cluster. Nothing is listed at all if all those namespaces are still targeted.
+//
+// The secrets in the namespaces of the targets are left intact, because the stale secrets there are cleaned up during the deployment
+// to the target. Just like in UnreferenceObjectsOutOfScope, only the targets in the local cluster are considered, because the secrets
+// in the remote clusters are not visible through the provided client.
+func DeleteOrphanedManagedSecrets(ctx context.Context, cl client.Client, marker bindings.ObjectMarker, key client.ObjectKey, targets []api.RemoteSecretTarget, previousTargets []api.TargetStatus, previousInventory []api.ManagedSecretReference) error {
+	inScope := map[string]bool{}
+	for _, t := range targets {
+		if t.ApiUrl == "" {
+			inScope[t.Namespace] = true
+		}
+	}
+
+	candidates := map[string]bool{}
+	for _, ts := range previousTargets {
+		if ts.ApiUrl == "" && ts.Namespace != "" && !inScope[ts.Namespace] {
+			candidates[ts.Namespace] = true
+		}
+	}
+	for _, ref := range previousInventory {
+		if ref.ApiUrl == "" && ref.Namespace != "" && !inScope[ref.Namespace] {
+			candidates[ref.Namespace] = true
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	namespaces := make([]string, 0, len(candidates))
+	for ns := range candidates {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	opts, err := marker.ListManagedOptions(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to construct the list options of the secrets managed by the remote secret %s: %w", key, err)
+	}
+
+	for _, ns := range namespaces {
+		if err := deleteOrphanedManagedSecretsInNamespace(ctx, cl, marker, key, ns, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteOrphanedManagedSecretsInNamespace(ctx context.Context, cl client.Client, marker bindings.ObjectMarker, key client.ObjectKey, namespace string, opts []client.ListOption) error {
+	sl := &corev1.SecretList{}
+	if err := cl.List(ctx, sl, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
+		return fmt.Errorf("failed to list the secrets managed by the remote secret %s in the namespace %s: %w", key, namespace, err)
+	}
+
+	lg := log.FromContext(ctx).V(logs.DebugLevel)
+
+	for i := range sl.Items {
+		secret := &sl.Items[i]
+
+		// the list options are only a pre-filter, the marker has the final say
+		if managed, err := marker.IsManagedBy(ctx, key, secret); err != nil {
+			return fmt.Errorf("failed to determine whether the secret %s is managed: %w", client.ObjectKeyFromObject(secret), err)
+		} else if !managed {
+			continue
+		}
+
+		lg.Info("deleting the orphaned secret managed by the remote secret", "remoteSecret", key, "secret", client.ObjectKeyFromObject(secret))
+		if err := cl.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the orphaned secret %s managed by the remote secret %s: %w", client.ObjectKeyFromObject(secret), key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeleteOrphanedManagedSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	key := client.ObjectKey{Name: "rs", Namespace: "default"}
+	marker := &namespacetarget.NamespaceObjectMarker{}
+
+	managedSecret := func(ns, name string, managedBy client.ObjectKey) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+		_, err := marker.MarkManaged(context.TODO(), managedBy, s)
+		assert.NoError(t, err)
+		return s
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		managedSecret("kept", "secret", key),
+		managedSecret("removed", "secret", key),
+		managedSecret("removed", "other", client.ObjectKey{Name: "other", Namespace: "default"}),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmarked", Namespace: "removed"}},
+		managedSecret("inventoried", "secret", key),
+		managedSecret("unknown", "secret", key),
+	).Build()
+
+	targets := []api.RemoteSecretTarget{
+		{Namespace: "kept"},
+		// the remote targets don't keep the secrets in the same namespace of the local cluster
+		{Namespace: "removed", ApiUrl: "https://remote.cluster"},
+	}
+	previousTargets := []api.TargetStatus{
+		{Namespace: "kept"},
+		{Namespace: "removed"},
+	}
+	previousInventory := []api.ManagedSecretReference{
+		{Namespace: "kept", Name: "secret"},
+		{Namespace: "inventoried", Name: "secret"},
+		// the secrets in the remote clusters are not visible through the client
+		{Namespace: "unknown", Name: "secret", ApiUrl: "https://remote.cluster"},
+	}
+
+	assert.NoError(t, DeleteOrphanedManagedSecrets(context.TODO(), cl, marker, key, targets, previousTargets, previousInventory))
+
+	exists := func(ns, name string) bool {
+		err := cl.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: ns}, &corev1.Secret{})
+		if errors.IsNotFound(err) {
+			return false
+		}
+		assert.NoError(t, err)
+		return true
+	}
+
+	assert.True(t, exists("kept", "secret"))
+	assert.False(t, exists("removed", "secret"))
+	assert.True(t, exists("removed", "other"))
+	assert.True(t, exists("removed", "unmarked"))
+	assert.False(t, exists("inventoried", "secret"))
+	// only the namespaces the remote secret previously deployed to are searched
+	assert.True(t, exists("unknown", "secret"))
+}
+
+func TestDeleteOrphanedManagedSecretsWithUnchangedTargets(t *testing.T) {
+	cl := &failingListClient{Client: fake.NewClientBuilder().Build()}
+
+	targets := []api.RemoteSecretTarget{{Namespace: "ns"}}
+	previousTargets := []api.TargetStatus{{Namespace: "ns"}}
+	previousInventory := []api.ManagedSecretReference{{Namespace: "ns", Name: "secret"}}
+
+	assert.NoError(t, DeleteOrphanedManagedSecrets(context.TODO(), cl, &namespacetarget.NamespaceObjectMarker{}, client.ObjectKey{Name: "rs", Namespace: "default"}, targets, previousTargets, previousInventory))
+}
+
+// failingListClient fails all the List calls.
+type failingListClient struct {
+	client.Client
+}
+
+func (c *failingListClient) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return stdErrors.New("no listing expected")
+}
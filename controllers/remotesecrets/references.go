@@ -0,0 +1,140 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// UnreferenceObjectsOutOfScope looks for the secrets and service accounts in the cluster of the provided client that are referenced
+// but not managed by the remote secret with the provided key and that live in namespaces that are not among the provided targets. The references
+// to the remote secret are removed from such objects using the object marker and the objects are updated in the cluster.
+//
+// The managed objects are left intact, because those are deleted during the cleanup of the removed targets.
+//
+// Only the targets in the local cluster (i.e. the ones without an API URL) are considered in scope, because the objects in the remote
+// clusters are not visible through the provided client.
+func UnreferenceObjectsOutOfScope(ctx context.Context, cl client.Client, marker bindings.ObjectMarker, key client.ObjectKey, targets []api.RemoteSecretTarget) error {
+	inScope := map[string]bool{}
+	for _, t := range targets {
+		if t.ApiUrl == "" {
+			inScope[t.Namespace] = true
+		}
+	}
+
+	if err := unreferenceOutOfScope(ctx, cl, marker, key, inScope, &corev1.SecretList{}); err != nil {
+		return fmt.Errorf("failed to remove the stale references to the remote secret %s from the secrets: %w", key, err)
+	}
+
+	if err := unreferenceOutOfScope(ctx, cl, marker, key, inScope, &corev1.ServiceAccountList{}); err != nil {
+		return fmt.Errorf("failed to remove the stale references to the remote secret %s from the service accounts: %w", key, err)
+	}
+
+	return nil
+}
+
+func unreferenceOutOfScope(ctx context.Context, cl client.Client, marker bindings.ObjectMarker, key client.ObjectKey, inScope map[string]bool, list client.ObjectList) error {
+	opts, err := marker.ListReferencedOptions(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to construct the list options: %w", err)
+	}
+
+	if err := cl.List(ctx, list, opts...); err != nil {
+		return fmt.Errorf("failed to list the referenced objects: %w", err)
+	}
+
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return fmt.Errorf("failed to extract the objects from the list: %w", err)
+	}
+
+	lg := log.FromContext(ctx).V(logs.DebugLevel)
+
+	for _, o := range objs {
+		obj, ok := o.(client.Object)
+		if !ok || inScope[obj.GetNamespace()] {
+			continue
+		}
+
+		if refed, err := marker.IsReferencedBy(ctx, key, obj); err != nil {
+			return fmt.Errorf("failed to determine whether the object %s is referenced: %w", client.ObjectKeyFromObject(obj), err)
+		} else if !refed {
+			continue
+		}
+
+		if managed, err := marker.IsManagedBy(ctx, key, obj); err != nil {
+			return fmt.Errorf("failed to determine whether the object %s is managed: %w", client.ObjectKeyFromObject(obj), err)
+		} else if managed {
+			continue
+		}
+
+		changed, err := marker.UnmarkReferenced(ctx, key, obj)
+		if err != nil {
+			return fmt.Errorf("failed to unmark the object %s as referenced: %w", client.ObjectKeyFromObject(obj), err)
+		}
+
+		if changed {
+			lg.Info("removing the stale reference to the remote secret", "remoteSecret", key, "object", client.ObjectKeyFromObject(obj))
+			if err := cl.Update(ctx, obj); err != nil {
+				return fmt.Errorf("failed to update the object %s after removing the reference: %w", client.ObjectKeyFromObject(obj), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReferencingRemoteSecrets returns the remote secrets referenced by the provided object according to its markings read using the provided
+// marker. The provided client must be the client of the cluster the remote secrets live in, which is not necessarily the cluster of
+// the object if it lives in a remote target. The references to the remote secrets that no longer exist are skipped, as are the
+// references to the cluster remote secrets. Each remote secret is returned only once even if the object references it from multiple
+// clusters.
+func ReferencingRemoteSecrets(ctx context.Context, cl client.Client, marker bindings.ObjectMarker, obj client.Object) ([]*api.RemoteSecret, error) {
+	refs, err := marker.GetReferencingTargets(ctx, obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the references to the remote secrets from the object %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	ret := make([]*api.RemoteSecret, 0, len(refs))
+	seen := map[client.ObjectKey]bool{}
+	for _, ref := range refs {
+		// the cluster remote secrets are not namespaced
+		if ref.Namespace == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		rs := &api.RemoteSecret{}
+		if err := cl.Get(ctx, ref, rs); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get the remote secret %s referenced by the object %s: %w", ref, client.ObjectKeyFromObject(obj), err)
+		}
+		ret = append(ret, rs)
+	}
+
+	return ret, nil
+}
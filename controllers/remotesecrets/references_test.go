@@ -0,0 +1,138 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUnreferenceObjectsOutOfScope(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	rs := &api.RemoteSecret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+		},
+		Spec: api.RemoteSecretSpec{
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "kept"},
+			},
+		},
+	}
+
+	referencedBy := func(value string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Labels: map[string]string{
+				namespacetarget.LinkedByRemoteSecretLabel: "true",
+			},
+			Annotations: map[string]string{
+				namespacetarget.LinkedRemoteSecretsAnnotation: value,
+			},
+		}
+	}
+
+	withKey := func(meta metav1.ObjectMeta, ns, name string) metav1.ObjectMeta {
+		meta.Namespace = ns
+		meta.Name = name
+		return meta
+	}
+
+	managed := referencedBy("default/rs")
+	managed.Annotations[namespacetarget.ManagingRemoteSecretNameAnnotation] = "default/rs"
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ServiceAccount{ObjectMeta: withKey(referencedBy("default/rs"), "kept", "sa")},
+		&corev1.ServiceAccount{ObjectMeta: withKey(referencedBy("default/rs"), "removed", "sa-single")},
+		&corev1.ServiceAccount{ObjectMeta: withKey(referencedBy("default/rs,default/other"), "removed", "sa-multi")},
+		&corev1.Secret{ObjectMeta: withKey(managed, "removed", "managed")},
+	).Build()
+
+	assert.NoError(t, UnreferenceObjectsOutOfScope(context.TODO(), cl, &namespacetarget.NamespaceObjectMarker{}, client.ObjectKeyFromObject(rs), rs.Spec.Targets))
+
+	get := func(obj client.Object, ns, name string) client.Object {
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: ns}, obj))
+		return obj
+	}
+
+	t.Run("in scope objects untouched", func(t *testing.T) {
+		sa := get(&corev1.ServiceAccount{}, "kept", "sa")
+		assert.Equal(t, "default/rs", sa.GetAnnotations()[namespacetarget.LinkedRemoteSecretsAnnotation])
+	})
+
+	t.Run("last reference removes the label", func(t *testing.T) {
+		sa := get(&corev1.ServiceAccount{}, "removed", "sa-single")
+		assert.NotContains(t, sa.GetLabels(), namespacetarget.LinkedByRemoteSecretLabel)
+		assert.NotContains(t, sa.GetAnnotations(), namespacetarget.LinkedRemoteSecretsAnnotation)
+	})
+
+	t.Run("other references kept", func(t *testing.T) {
+		sa := get(&corev1.ServiceAccount{}, "removed", "sa-multi")
+		assert.Equal(t, "true", sa.GetLabels()[namespacetarget.LinkedByRemoteSecretLabel])
+		assert.Equal(t, "default/other", sa.GetAnnotations()[namespacetarget.LinkedRemoteSecretsAnnotation])
+	})
+
+	t.Run("managed objects untouched", func(t *testing.T) {
+		s := get(&corev1.Secret{}, "removed", "managed")
+		assert.Equal(t, "default/rs", s.GetAnnotations()[namespacetarget.ManagingRemoteSecretNameAnnotation])
+	})
+}
+
+func TestReferencingRemoteSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}},
+		&api.RemoteSecret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other-ns"}},
+	).Build()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "target",
+			Labels: map[string]string{
+				namespacetarget.LinkedByRemoteSecretLabel: "true",
+			},
+			Annotations: map[string]string{
+				namespacetarget.LinkedRemoteSecretsAnnotation: "default/rs,default/deleted,other-ns/other@https://api.cluster:6443,default/rs@https://api.cluster:6443,/cluster-wide",
+			},
+		},
+	}
+
+	rss, err := ReferencingRemoteSecrets(context.TODO(), cl, &namespacetarget.NamespaceObjectMarker{}, secret)
+	assert.NoError(t, err)
+
+	keys := make([]client.ObjectKey, len(rss))
+	for i, rs := range rss {
+		keys[i] = client.ObjectKeyFromObject(rs)
+	}
+	assert.ElementsMatch(t, []client.ObjectKey{
+		{Name: "rs", Namespace: "default"},
+		{Name: "other", Namespace: "other-ns"},
+	}, keys)
+}
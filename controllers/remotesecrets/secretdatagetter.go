@@ -25,22 +25,73 @@ import (
 	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
 
 	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	corev1 "k8s.io/api/core/v1"
 )
 
 type SecretDataGetter struct {
-	Storage remotesecretstorage.RemoteSecretStorage
+	// DataStores is the registry of the data stores the data can be obtained from.
+	DataStores *DataStoreRegistry[*api.RemoteSecret]
+	// DataSources are the data stores to obtain the data from. If empty, the local data store is used.
+	DataSources DataSources
+	// KeyFilter is the optional filter of the keys of the secret data returned from the storage.
+	KeyFilter *api.KeyFilter
+	// SecretType is the type of the secret the data is deployed as, if overridden by the target. The type from the secret spec
+	// is used if empty.
+	SecretType corev1.SecretType
 }
 
 func (sb *SecretDataGetter) GetData(ctx context.Context, obj *api.RemoteSecret) (map[string][]byte, string, error) {
-	data, err := sb.Storage.Get(ctx, obj)
+	data, err := sb.DataStores.GetMerged(ctx, sb.DataSources, obj)
+	return filterStoredData(data, err, sb.KeyFilter, secretTypeOr(sb.SecretType, obj.Spec.Secret.Type))
+}
+
+var _ bindings.SecretDataGetter[*api.RemoteSecret] = (*SecretDataGetter)(nil)
+
+// ClusterSecretDataGetter is the SecretDataGetter for the ClusterRemoteSecret objects.
+type ClusterSecretDataGetter struct {
+	// DataStores is the registry of the data stores the data can be obtained from.
+	DataStores *DataStoreRegistry[*api.ClusterRemoteSecret]
+	// DataSources are the data stores to obtain the data from. If empty, the local data store is used.
+	DataSources DataSources
+	// KeyFilter is the optional filter of the keys of the secret data returned from the storage.
+	KeyFilter *api.KeyFilter
+	// SecretType is the type of the secret the data is deployed as, if overridden by the target. The type from the secret spec
+	// is used if empty.
+	SecretType corev1.SecretType
+}
+
+func (sb *ClusterSecretDataGetter) GetData(ctx context.Context, obj *api.ClusterRemoteSecret) (map[string][]byte, string, error) {
+	data, err := sb.DataStores.GetMerged(ctx, sb.DataSources, obj)
+	return filterStoredData(data, err, sb.KeyFilter, secretTypeOr(sb.SecretType, obj.Spec.Secret.Type))
+}
+
+var _ bindings.SecretDataGetter[*api.ClusterRemoteSecret] = (*ClusterSecretDataGetter)(nil)
+
+// secretTypeOr returns the provided secret type or the default if it is empty.
+func secretTypeOr(secretType corev1.SecretType, def corev1.SecretType) corev1.SecretType {
+	if secretType == "" {
+		return def
+	}
+	return secretType
+}
+
+// filterStoredData converts the result of the storage Get call to the result of the SecretDataGetter.GetData call applying
+// the key filter on the data.
+func filterStoredData(data *remotesecretstorage.SecretData, err error, keyFilter *api.KeyFilter, secretType corev1.SecretType) (map[string][]byte, string, error) {
 	if err != nil {
 		if errors.Is(err, secretstorage.NotFoundError) {
 			return map[string][]byte{}, string(api.RemoteSecretErrorReasonTokenRetrieval), fmt.Errorf("%w: %s", bindings.SecretDataNotFoundError, err.Error())
 		}
+		if errors.Is(err, CircuitOpenError) {
+			return nil, string(api.RemoteSecretErrorReasonCircuitOpen), err
+		}
 		return nil, string(api.RemoteSecretErrorReasonTokenRetrieval), fmt.Errorf("failed to get the token data from token storage: %w", err)
 	}
 
-	return *data, string(api.RemoteSecretErrorReasonNoError), nil
-}
+	filtered, err := ApplyKeyFilter(keyFilter, secretType, *data)
+	if err != nil {
+		return nil, string(bindings.ErrorReasonInvalidSecretData), fmt.Errorf("failed to filter the secret data: %w", err)
+	}
 
-var _ bindings.SecretDataGetter[*api.RemoteSecret] = (*SecretDataGetter)(nil)
+	return filtered, string(api.RemoteSecretErrorReasonNoError), nil
+}
@@ -41,7 +41,7 @@ func TestSecretDataGetter_GetData(t *testing.T) {
 		}
 
 		sdg := SecretDataGetter{
-			Storage: st,
+			DataStores: NewRemoteSecretDataStores(st, nil),
 		}
 
 		data, reason, err := sdg.GetData(context.TODO(), &api.RemoteSecret{
@@ -63,7 +63,7 @@ func TestSecretDataGetter_GetData(t *testing.T) {
 		}
 
 		sdg := SecretDataGetter{
-			Storage: st,
+			DataStores: NewRemoteSecretDataStores(st, nil),
 		}
 
 		data, reason, err := sdg.GetData(context.TODO(), &api.RemoteSecret{
@@ -85,7 +85,7 @@ func TestSecretDataGetter_GetData(t *testing.T) {
 		}
 
 		sdg := SecretDataGetter{
-			Storage: st,
+			DataStores: NewRemoteSecretDataStores(st, nil),
 		}
 
 		data, reason, err := sdg.GetData(context.TODO(), &api.RemoteSecret{
@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// TargetKey is the identity of a target. The targets with the same key deploy to the same namespace in the same cluster. The name
+// of the secret is not part of the key, because it is the same for all the targets of a remote secret.
+type TargetKey struct {
+	ApiUrl    string
+	Namespace string
+}
+
+// KeyOfTarget returns the key of the provided target.
+func KeyOfTarget(target *api.RemoteSecretTarget) TargetKey {
+	return TargetKey{ApiUrl: target.ApiUrl, Namespace: target.Namespace}
+}
+
+// DiffTargets compares the current and the desired targets by their keys. The added targets are the desired targets with the keys not
+// among the current targets, the removed targets are the current targets with the keys not among the desired targets and the changed
+// targets are the desired targets that have the same key as some current target but differ from it in other fields. Only the first
+// of the targets with the same key is considered in either list. The added and changed targets are in the order of the desired targets
+// and the removed targets are in the order of the current targets.
+func DiffTargets(current, desired []api.RemoteSecretTarget) (added, removed, changed []api.RemoteSecretTarget) {
+	currentByKey := make(map[TargetKey]*api.RemoteSecretTarget, len(current))
+	for i := range current {
+		key := KeyOfTarget(&current[i])
+		if _, ok := currentByKey[key]; !ok {
+			currentByKey[key] = &current[i]
+		}
+	}
+
+	desiredKeys := make(map[TargetKey]bool, len(desired))
+	for i := range desired {
+		t := &desired[i]
+		key := KeyOfTarget(t)
+		if desiredKeys[key] {
+			continue
+		}
+		desiredKeys[key] = true
+
+		if c, ok := currentByKey[key]; !ok {
+			added = append(added, *t)
+		} else if !equality.Semantic.DeepEqual(c, t) {
+			changed = append(changed, *t)
+		}
+	}
+
+	removedKeys := make(map[TargetKey]bool, len(current))
+	for i := range current {
+		key := KeyOfTarget(&current[i])
+		if !desiredKeys[key] && !removedKeys[key] {
+			removedKeys[key] = true
+			removed = append(removed, current[i])
+		}
+	}
+
+	return
+}
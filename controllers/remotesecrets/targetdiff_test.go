@@ -0,0 +1,60 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTargets(t *testing.T) {
+	current := []api.RemoteSecretTarget{
+		{Namespace: "kept"},
+		{Namespace: "changed", Wave: 1},
+		{Namespace: "removed"},
+		{Namespace: "kept", ApiUrl: "https://other.cluster"},
+		{Namespace: "removed"},
+	}
+	desired := []api.RemoteSecretTarget{
+		{Namespace: "added"},
+		{Namespace: "kept"},
+		{Namespace: "changed", Wave: 2},
+		{Namespace: "kept", ApiUrl: "https://other.cluster"},
+		{Namespace: "added", Wave: 5},
+	}
+
+	added, removed, changed := DiffTargets(current, desired)
+	assert.Equal(t, []api.RemoteSecretTarget{{Namespace: "added"}}, added)
+	assert.Equal(t, []api.RemoteSecretTarget{{Namespace: "removed"}}, removed)
+	assert.Equal(t, []api.RemoteSecretTarget{{Namespace: "changed", Wave: 2}}, changed)
+
+	t.Run("same cluster matters", func(t *testing.T) {
+		added, removed, changed := DiffTargets(
+			[]api.RemoteSecretTarget{{Namespace: "ns"}},
+			[]api.RemoteSecretTarget{{Namespace: "ns", ApiUrl: "https://other.cluster"}})
+		assert.Len(t, added, 1)
+		assert.Len(t, removed, 1)
+		assert.Empty(t, changed)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		added, removed, changed := DiffTargets(nil, nil)
+		assert.Empty(t, added)
+		assert.Empty(t, removed)
+		assert.Empty(t, changed)
+	})
+}
@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// TargetProbeWebhookPath is the path the TargetProbeWebhook is served at.
+const TargetProbeWebhookPath = "/validate-appstudio-redhat-com-v1beta1-remotesecret"
+
+//+kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1beta1-remotesecret,mutating=false,failurePolicy=ignore,sideEffects=None,groups=appstudio.redhat.com,resources=remotesecrets,verbs=create,versions=v1beta1,name=vremotesecret.appstudio.redhat.com,admissionReviewVersions=v1,timeoutSeconds=10
+
+// TargetProbe checks that the remote targets are reachable using their cluster credentials. It is only a best-effort check
+// meant to catch the wrong API URLs and credentials early, the deployment to the targets reports the actual problems.
+type TargetProbe struct {
+	// Client is the client of the local cluster used to read the cluster credentials secrets and the cluster registry.
+	Client client.Client
+	// RemoteClients creates the clients of the remote clusters. If nil, the clients are created using the cluster credentials
+	// secrets read using the Client.
+	RemoteClients RemoteClientFactory
+	// ClusterRegistry looks up the clusters referenced by the targets. If nil, the SecretClusterRegistry is used.
+	ClusterRegistry ClusterRegistry
+	// Timeout limits the time all the targets are probed for. The probe is disabled if not positive.
+	Timeout time.Duration
+}
+
+// Probe tries to read the namespaces of the remote targets of the provided remote secret and returns the warnings describing
+// the targets that could not be reached. The targets are probed concurrently and the whole probe takes at most the Timeout.
+func (p *TargetProbe) Probe(ctx context.Context, rs *api.RemoteSecret) []string {
+	if p.Timeout <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	registry := p.ClusterRegistry
+	if registry == nil {
+		registry = &SecretClusterRegistry{Client: p.Client}
+	}
+	factory := p.RemoteClients
+	if factory == nil {
+		factory = CredentialsClientFactory(p.Client)
+	}
+
+	targets, unresolved := ResolveClusterTargets(ctx, registry, rs.Namespace, rs.Spec.Targets)
+
+	warnings := make([]string, len(targets))
+	wg := sync.WaitGroup{}
+	for i := range targets {
+		target := &targets[i]
+		if err, ok := unresolved[SpecTargetIndex(i)]; ok {
+			warnings[i] = fmt.Sprintf("the cluster %s of the target at the index %d could not be resolved: %s", target.Cluster, i, err.Error())
+			continue
+		}
+		// the local targets and the targets without the credentials are checked by the validation of the spec.
+		if target.ApiUrl == "" || target.ClusterCredentialsSecret == "" {
+			continue
+		}
+
+		idx := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := probeTarget(ctx, factory, rs.Namespace, target); err != nil {
+				warnings[idx] = fmt.Sprintf("the target at the index %d in the cluster %s could not be reached: %s", idx, target.ApiUrl, err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	ret := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		if w != "" {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// probeTarget reads the namespace of the target. The lack of the permissions to read the namespaces is not considered a failure,
+// because it proves that the cluster accepted the credentials.
+func probeTarget(ctx context.Context, factory RemoteClientFactory, namespace string, target *api.RemoteSecretTarget) error {
+	cl, err := factory(ctx, target.ApiUrl, client.ObjectKey{Name: target.ClusterCredentialsSecret, Namespace: namespace})
+	if err != nil {
+		return err
+	}
+
+	if err := cl.Get(ctx, client.ObjectKey{Name: target.Namespace}, &corev1.Namespace{}); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("the namespace %s doesn't exist", target.Namespace)
+		}
+		if errors.IsForbidden(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read the namespace %s: %w", target.Namespace, err)
+	}
+
+	return nil
+}
+
+// TargetProbeWebhook is the validating webhook probing the remote targets of the created remote secrets. It never refuses
+// the remote secrets, the unreachable targets are only reported in the warnings.
+type TargetProbeWebhook struct {
+	Probe   *TargetProbe
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*TargetProbeWebhook)(nil)
+
+// NewTargetProbeWebhook creates a new webhook using the provided probe. The scheme of the client of the probe is used to decode
+// the remote secrets.
+func NewTargetProbeWebhook(probe *TargetProbe) (*TargetProbeWebhook, error) {
+	decoder, err := admission.NewDecoder(probe.Client.Scheme())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the decoder of the target probe webhook: %w", err)
+	}
+	return &TargetProbeWebhook{Probe: probe, decoder: decoder}, nil
+}
+
+// Handle implements admission.Handler
+func (w *TargetProbeWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if w.Probe.Timeout <= 0 {
+		return admission.Allowed("")
+	}
+
+	rs := &api.RemoteSecret{}
+	if err := w.decoder.Decode(req, rs); err != nil {
+		// the probe is best-effort only, so even the failure to decode the object doesn't refuse it.
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("failed to probe the targets: %s", err.Error()))
+	}
+
+	return admission.Allowed("").WithWarnings(w.Probe.Probe(ctx, rs)...)
+}
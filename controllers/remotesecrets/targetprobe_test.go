@@ -0,0 +1,110 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestTargetProbe(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, api.AddToScheme(scheme))
+
+	local := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default", Labels: map[string]string{ClusterNameLabel: "prod"}},
+		Data:       map[string][]byte{ClusterApiUrlKey: []byte("https://prod.cluster")},
+	}).Build()
+	remote := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}).Build()
+
+	probe := &TargetProbe{
+		Client: local,
+		RemoteClients: func(_ context.Context, apiUrl string, credentials client.ObjectKey) (client.Client, error) {
+			if apiUrl == "https://down.cluster" {
+				return nil, errors.New("connection refused")
+			}
+			return remote, nil
+		},
+		Timeout: time.Second,
+	}
+
+	rs := &api.RemoteSecret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: api.GroupVersion.String(), Kind: "RemoteSecret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"},
+		Spec: api.RemoteSecretSpec{
+			Targets: []api.RemoteSecretTarget{
+				{Namespace: "local"},
+				{Namespace: "ns", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "creds"},
+				{Namespace: "missing", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "creds"},
+				{Namespace: "ns", ApiUrl: "https://down.cluster", ClusterCredentialsSecret: "creds"},
+				{Namespace: "ns", Cluster: "prod"},
+				{Namespace: "ns", Cluster: "staging"},
+			},
+		},
+	}
+
+	t.Run("probe", func(t *testing.T) {
+		warnings := probe.Probe(context.TODO(), rs)
+		assert.Len(t, warnings, 3)
+		assert.Contains(t, warnings[0], "index 2")
+		assert.Contains(t, warnings[0], "the namespace missing doesn't exist")
+		assert.Contains(t, warnings[1], "index 3")
+		assert.Contains(t, warnings[1], "connection refused")
+		assert.Contains(t, warnings[2], "the cluster staging of the target at the index 5")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := *probe
+		disabled.Timeout = 0
+		assert.Empty(t, disabled.Probe(context.TODO(), rs))
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		w, err := NewTargetProbeWebhook(probe)
+		assert.NoError(t, err)
+
+		raw, err := json.Marshal(rs)
+		assert.NoError(t, err)
+
+		resp := w.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Warnings, 3)
+
+		// even the objects that cannot be decoded are not refused
+		resp = w.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte("{")},
+		}})
+		assert.True(t, resp.Allowed)
+		assert.Len(t, resp.Warnings, 1)
+	})
+}
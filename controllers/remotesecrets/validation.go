@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+)
+
+var (
+	InvalidRemoteSecretSpecError = errors.New("invalid remote secret spec")
+	SelfReferencingTargetError   = errors.New("the target would write to the secret the data is copied from, so each deployment would change the source of the data and trigger another deployment")
+)
+
+// ValidateSpec checks the spec of the provided remote secret for the problems that can be found without access to the cluster
+// and without the secret data, e.g. in a CI pipeline linting the manifests. All the found problems are reported in the returned
+// error that wraps InvalidRemoteSecretSpecError.
+func ValidateSpec(rs *api.RemoteSecret) error {
+	spec := &rs.Spec
+	var problems []string
+
+	for _, uri := range DataSourcesOf(spec).URIs {
+		location, err := ParseDataStoreURI(uri)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if ns := location.Query().Get(dataSourceNamespaceParam); ns != "" && rs.Namespace != "" && ns != rs.Namespace {
+			problems = append(problems, fmt.Sprintf("the data source %s: %s", uri, DataSourceNamespaceMismatchError))
+		}
+	}
+
+	seen := map[string]int{}
+	for i, t := range spec.Targets {
+		key := t.ApiUrl + "/" + t.Namespace
+		if t.Cluster != "" {
+			key = "cluster:" + t.Cluster + "/" + t.Namespace
+		}
+		if original, ok := seen[key]; ok {
+			problems = append(problems, fmt.Sprintf("the target at the index %d is a duplicate of the target at the index %d", i, original))
+		} else {
+			seen[key] = i
+		}
+		if t.Cluster != "" && (t.ApiUrl != "" || t.ClusterCredentialsSecret != "") {
+			problems = append(problems, fmt.Sprintf("the target at the index %d references the cluster %s and therefore cannot specify the API URL nor the cluster credentials secret", i, t.Cluster))
+		} else if t.ApiUrl != "" && IsLocalApiUrl(t.ApiUrl, "") {
+			problems = append(problems, fmt.Sprintf("the target at the index %d: %s", i, RedundantApiUrlError))
+		} else if t.ApiUrl != "" && t.ClusterCredentialsSecret == "" {
+			problems = append(problems, fmt.Sprintf("the target at the index %d points to a remote cluster but has no cluster credentials secret", i))
+		}
+		if err := CheckSelfReferencingTarget(rs.Namespace, DataSourcesOf(spec), spec.Secret.Name, &spec.Targets[i]); err != nil {
+			problems = append(problems, fmt.Sprintf("the target at the index %d: %s", i, err.Error()))
+		}
+	}
+
+	if spec.MaxTargets > 0 && len(spec.Targets) > spec.MaxTargets {
+		problems = append(problems, fmt.Sprintf("there are %d targets but at most %d are allowed", len(spec.Targets), spec.MaxTargets))
+	}
+
+	problems = append(problems, bindings.SecretSpecProblems(&spec.Secret)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", InvalidRemoteSecretSpecError, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CheckSelfReferencingTarget returns the SelfReferencingTargetError if the secret with the provided name in the provided target is the same
+// secret as one of the provided data sources. The namespace is the namespace of the object, which is also the namespace of the data sources
+// unless they specify one explicitly. Only the targets in the local cluster can be self-referencing.
+func CheckSelfReferencingTarget(namespace string, sources DataSources, secretName string, target *api.RemoteSecretTarget) error {
+	if secretName == "" || target.ApiUrl != "" || target.Cluster != "" {
+		return nil
+	}
+
+	for _, uri := range sources.URIs {
+		location, err := ParseDataStoreURI(uri)
+		if err != nil || (location.Scheme != SecretDataStoreScheme && location.Scheme != ConsumedSecretDataStoreScheme) {
+			continue
+		}
+		sourceNamespace := namespace
+		if sourceNamespace == "" {
+			sourceNamespace = location.Query().Get(dataSourceNamespaceParam)
+		}
+		if sourceNamespace != "" && sourceNamespace == target.Namespace && location.Host == secretName {
+			return fmt.Errorf("%w: the data source %s is the secret %s in the namespace %s of the target, use a different name of the secret or a different target namespace", SelfReferencingTargetError, uri, secretName, target.Namespace)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecrets
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateSpec(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		rs := &api.RemoteSecret{
+			Spec: api.RemoteSecretSpec{
+				Secret: api.LinkableSecretSpec{
+					Name:      "secret",
+					Labels:    map[string]string{"app": "test"},
+					Templates: map[string]string{"url": "{{.host}}"},
+				},
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "a"},
+					{Namespace: "a", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "creds"},
+					{Namespace: "a", Cluster: "prod"},
+				},
+			},
+		}
+		assert.NoError(t, ValidateSpec(rs))
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		rs := &api.RemoteSecret{
+			Spec: api.RemoteSecretSpec{
+				MaxTargets: 3,
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "a"},
+					{Namespace: "a"},
+					{Namespace: "b", ApiUrl: "https://remote.cluster"},
+					{Namespace: "c", ApiUrl: "https://kubernetes.default.svc", ClusterCredentialsSecret: "creds"},
+					{Namespace: "d", Cluster: "prod", ClusterCredentialsSecret: "creds"},
+				},
+			},
+		}
+		err := ValidateSpec(rs)
+		assert.ErrorIs(t, err, InvalidRemoteSecretSpecError)
+		assert.Contains(t, err.Error(), "index 1 is a duplicate of the target at the index 0")
+		assert.Contains(t, err.Error(), "index 2 points to a remote cluster")
+		assert.Contains(t, err.Error(), "index 3: the apiUrl of the target points at the cluster the operator runs in")
+		assert.Contains(t, err.Error(), "index 4 references the cluster prod")
+		assert.Contains(t, err.Error(), "at most 3 are allowed")
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		rs := &api.RemoteSecret{
+			Spec: api.RemoteSecretSpec{
+				Secret: api.LinkableSecretSpec{
+					Type:             corev1.SecretTypeSSHAuth,
+					Labels:           map[string]string{"not valid": "x"},
+					OptionalKeyNames: []string{corev1.SSHAuthPrivateKey},
+					Templates:        map[string]string{"url": "{{.host"},
+					Registries:       []api.RegistryCredentials{{Registry: "quay.io", UsernameKey: "u", PasswordKey: "p"}},
+				},
+			},
+		}
+		err := ValidateSpec(rs)
+		assert.ErrorIs(t, err, InvalidRemoteSecretSpecError)
+		assert.Contains(t, err.Error(), "invalid labels or annotations")
+		assert.Contains(t, err.Error(), "optional keys include keys required")
+		assert.Contains(t, err.Error(), "registries can only be used")
+		assert.Contains(t, err.Error(), "failed to render the template url")
+	})
+
+	t.Run("data sources", func(t *testing.T) {
+		rs := &api.RemoteSecret{
+			Spec: api.RemoteSecretSpec{
+				DataSources: []api.DataFrom{{Name: "%zz"}},
+			},
+		}
+		assert.ErrorIs(t, ValidateSpec(rs), InvalidRemoteSecretSpecError)
+
+		rs = &api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"},
+			Spec: api.RemoteSecretSpec{
+				DataFrom: &api.DataFrom{Name: "source", Namespace: "other"},
+			},
+		}
+		err := ValidateSpec(rs)
+		assert.ErrorIs(t, err, InvalidRemoteSecretSpecError)
+		assert.Contains(t, err.Error(), DataSourceNamespaceMismatchError.Error())
+
+		rs.Spec.DataFrom.Namespace = "default"
+		assert.NoError(t, ValidateSpec(rs))
+	})
+
+	t.Run("self-referencing target", func(t *testing.T) {
+		rs := &api.RemoteSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"},
+			Spec: api.RemoteSecretSpec{
+				DataFrom: &api.DataFrom{Name: "source"},
+				Secret:   api.LinkableSecretSpec{Name: "source"},
+				Targets: []api.RemoteSecretTarget{
+					{Namespace: "other"},
+					{Namespace: "remote", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "creds"},
+				},
+			},
+		}
+		assert.NoError(t, ValidateSpec(rs))
+
+		rs.Spec.Targets = append(rs.Spec.Targets, api.RemoteSecretTarget{Namespace: "default"})
+		err := ValidateSpec(rs)
+		assert.ErrorIs(t, err, InvalidRemoteSecretSpecError)
+		assert.Contains(t, err.Error(), "the target at the index 2")
+		assert.Contains(t, err.Error(), SelfReferencingTargetError.Error())
+	})
+
+	t.Run("self-referencing target of cluster remote secret", func(t *testing.T) {
+		sources := DataSourcesOf(&api.RemoteSecretSpec{DataFrom: &api.DataFrom{Name: "source", Namespace: "sources"}})
+		assert.ErrorIs(t, CheckSelfReferencingTarget("", sources, "source", &api.RemoteSecretTarget{Namespace: "sources"}), SelfReferencingTargetError)
+		assert.NoError(t, CheckSelfReferencingTarget("", sources, "source", &api.RemoteSecretTarget{Namespace: "other"}))
+		assert.NoError(t, CheckSelfReferencingTarget("", sources, "other", &api.RemoteSecretTarget{Namespace: "sources"}))
+	})
+}
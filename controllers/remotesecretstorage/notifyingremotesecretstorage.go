@@ -32,6 +32,9 @@ import (
 type NotifyingRemoteSecretStorage struct {
 	ClientFactory kubernetesclient.K8sClientFactory
 	SecretStorage secretstorage.SecretStorage
+	// OnDataUpdate is called, if set, after the data is modified in the secret storage but before the RemoteSecret is notified
+	// about it, so that any state derived from the previous data can be discarded before the object is reconciled again.
+	OnDataUpdate func(id secretstorage.SecretID)
 }
 
 var _ secretstorage.SecretStorage = (*NotifyingRemoteSecretStorage)(nil)
@@ -71,6 +74,9 @@ func (s *NotifyingRemoteSecretStorage) Store(ctx context.Context, id secretstora
 }
 
 func (s *NotifyingRemoteSecretStorage) createDataUpdate(ctx context.Context, id secretstorage.SecretID) error {
+	if s.OnDataUpdate != nil {
+		s.OnDataUpdate(id)
+	}
 
 	lg := log.FromContext(ctx)
 	lg.Info("Adding label to RemoteSecret")
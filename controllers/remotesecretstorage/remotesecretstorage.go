@@ -36,3 +36,18 @@ func NewJSONSerializingRemoteSecretStorage(secretStorage secretstorage.SecretSto
 		Deserialize:   secretstorage.DeserializeJSON[SecretData],
 	}
 }
+
+type ClusterRemoteSecretStorage secretstorage.TypedSecretStorage[api.ClusterRemoteSecret, SecretData]
+
+// NewJSONSerializingClusterRemoteSecretStorage is the same as NewJSONSerializingRemoteSecretStorage only working with
+// the ClusterRemoteSecret objects as data keys.
+// NOTE that the provided secret storage MUST BE initialized before this call.
+func NewJSONSerializingClusterRemoteSecretStorage(secretStorage secretstorage.SecretStorage) ClusterRemoteSecretStorage {
+	return &secretstorage.DefaultTypedSecretStorage[api.ClusterRemoteSecret, SecretData]{
+		DataTypeName:  "cluster remote secret",
+		SecretStorage: secretStorage,
+		ToID:          secretstorage.ObjectToID[*api.ClusterRemoteSecret],
+		Serialize:     secretstorage.SerializeJSON[SecretData],
+		Deserialize:   secretstorage.DeserializeJSON[SecretData],
+	}
+}
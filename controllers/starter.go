@@ -18,27 +18,68 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
 	"github.com/redhat-appstudio/remote-secret/controllers/remotesecretstorage"
 	"github.com/redhat-appstudio/remote-secret/pkg/config"
 	"github.com/redhat-appstudio/remote-secret/pkg/kubernetesclient"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
 	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 func SetupAllReconcilers(mgr controllerruntime.Manager, cfg *config.OperatorConfiguration, secretStorage secretstorage.SecretStorage) error {
 	ctx := context.Background()
 
+	if err := remotesecrets.RegisterMetrics(metrics.Registry); err != nil {
+		return fmt.Errorf("failed to register the data store metrics: %w", err)
+	}
+	if err := bindings.RegisterMetrics(metrics.Registry); err != nil {
+		return fmt.Errorf("failed to register the deployment metrics: %w", err)
+	}
+
 	remoteSecretStorage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(secretStorage)
 	if err := remoteSecretStorage.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize the remote secret storage: %w", err)
 	}
 
+	if err := targetNamespacePolicy(cfg).Validate(); err != nil {
+		return fmt.Errorf("invalid target namespace policy: %w", err)
+	}
+
+	dataCache := remotesecrets.NewDataCache(cfg.DataCacheTTL, cfg.DataCacheMaxSize)
+	// the backends are shared, so is the circuit breaker
+	circuitBreaker := remotesecrets.NewCircuitBreaker(cfg.DataStoreFailureThreshold, cfg.DataStoreCircuitCooldown)
+	// the remote secrets and the cluster remote secrets can contribute to the same secrets
+	coalescer := bindings.NewContributionCoalescer(cfg.ContributionBatchWindow)
+	errorLogSuppressor := logs.NewRepeatSuppressor(cfg.SyncErrorLogWindow)
+
 	if cfg.EnableRemoteSecrets {
 		if err := (&RemoteSecretReconciler{
 			Client:              mgr.GetClient(),
 			Scheme:              mgr.GetScheme(),
 			Configuration:       cfg,
 			RemoteSecretStorage: remoteSecretStorage,
+			DataCache:           dataCache,
+			CircuitBreaker:      circuitBreaker,
+			Coalescer:           coalescer,
+			LocalApiUrl:         mgr.GetConfig().Host,
+			ErrorLogSuppressor:  errorLogSuppressor,
+		}).SetupWithManager(mgr); err != nil {
+			return err
+		}
+
+		if err := (&ClusterRemoteSecretReconciler{
+			Client:                     mgr.GetClient(),
+			Scheme:                     mgr.GetScheme(),
+			Configuration:              cfg,
+			ClusterRemoteSecretStorage: remotesecretstorage.NewJSONSerializingClusterRemoteSecretStorage(secretStorage),
+			DataCache:                  dataCache,
+			CircuitBreaker:             circuitBreaker,
+			Coalescer:                  coalescer,
+			LocalApiUrl:                mgr.GetConfig().Host,
+			ErrorLogSuppressor:         errorLogSuppressor,
 		}).SetupWithManager(mgr); err != nil {
 			return err
 		}
@@ -47,7 +88,9 @@ func SetupAllReconcilers(mgr controllerruntime.Manager, cfg *config.OperatorConf
 	if cfg.EnableTokenUpload {
 		remoteSecretStorage := remotesecretstorage.NewJSONSerializingRemoteSecretStorage(&remotesecretstorage.NotifyingRemoteSecretStorage{SecretStorage: secretStorage,
 			ClientFactory: kubernetesclient.SingleInstanceClientFactory{
-				Client: mgr.GetClient()}})
+				Client: mgr.GetClient()},
+			// the cached data of the remote secret must not outlive the upload of the new data
+			OnDataUpdate: func(id secretstorage.SecretID) { dataCache.Invalidate(id.Uid) }})
 		if err := remoteSecretStorage.Initialize(ctx); err != nil {
 			return fmt.Errorf("failed to initialize the notifying remote secret storage: %w", err)
 		}
@@ -0,0 +1,690 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	gosync "sync"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/bindings"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	opconfig "github.com/redhat-appstudio/remote-secret/pkg/config"
+	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	"github.com/redhat-appstudio/remote-secret/pkg/rerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var objectSupersededError = errors.New("the object has been updated since the deployment started")
+
+// targetsProcessor contains the logic of deploying the secret to the targets and cleaning up after them that is shared
+// by the reconcilers of RemoteSecret and ClusterRemoteSecret.
+type targetsProcessor[K client.Object] struct {
+	Client client.Client
+	// Object is the object owning the targets. It is used as the key to obtain the secret data with and its status is updated
+	// in the cluster after each deployment.
+	Object K
+	// SecretSpec is the spec of the secret to deploy to the targets.
+	SecretSpec *api.LinkableSecretSpec
+	// Targets is the list of the targets the secret should be deployed to.
+	Targets []api.RemoteSecretTarget
+	// Status is the status of the Object.
+	Status *api.RemoteSecretStatus
+	// DeletionPolicy determines whether the dependent objects are deleted or orphaned during the cleanup.
+	DeletionPolicy api.DeletionPolicy
+	// DataSources are the data sources of the Object. The targets in the local cluster must not deploy to the secrets the data
+	// is copied from.
+	DataSources remotesecrets.DataSources
+	// NewSecretDataGetter creates the secret data getter for a target with the provided key filter and the secret type overridden
+	// by the target (empty if not overridden).
+	NewSecretDataGetter func(keyFilter *api.KeyFilter, secretType corev1.SecretType) bindings.SecretDataGetter[K]
+	// Concurrency is the maximum number of targets that are deployed to concurrently. Values lower than 1 mean that the targets
+	// are deployed to one by one.
+	Concurrency int
+	// ServerSideApply makes the secrets in the targets written using the server-side apply.
+	ServerSideApply bool
+	// NamespacePolicy restricts the namespaces the Object can deploy to. It is not set for the cluster remote secrets.
+	NamespacePolicy remotesecrets.TargetNamespacePolicy
+	// MarkerDomain is the domain of the label and annotations the objects in the targets are marked with. The default domain of
+	// the namespacetarget.NamespaceObjectMarker is used if empty.
+	MarkerDomain string
+	// MaxTargets is the maximum number of the Targets to deploy to. Nothing is deployed if there are more. Zero means no limit.
+	MaxTargets int
+	// RemoteClients creates the clients of the targets in the remote clusters. If nil, the clients are created using the cluster
+	// credentials secrets read using the Client.
+	RemoteClients remotesecrets.RemoteClientFactory
+	// ClusterRegistry looks up the remote clusters referenced by the targets by name. If nil, the clusters are looked up using
+	// the remotesecrets.SecretClusterRegistry.
+	ClusterRegistry remotesecrets.ClusterRegistry
+	// Coalescer optionally batches the contributions to the same secret in the local cluster. The contributions to the secrets
+	// in the remote clusters are never coalesced.
+	Coalescer *bindings.ContributionCoalescer
+	// LocalApiUrl is the URL of the API server of the cluster the operator runs in. The targets with the API URL pointing at it
+	// are refused, because they should be configured as the local targets.
+	LocalApiUrl string
+	// LogSuppressor optionally suppresses the repeated logging of the same deployment errors.
+	LogSuppressor *logs.RepeatSuppressor
+	// RequeueJitter is applied to the delays after which the failed deployments are retried.
+	RequeueJitter Jitter
+
+	// failedOptionalTargets is the number of the optional targets that failed to be deployed to during processTargets.
+	failedOptionalTargets int
+
+	// statusLock guards the writes to the Status and the updates of the Object in the cluster while deploying to the targets
+	// concurrently.
+	statusLock gosync.Mutex
+}
+
+// processTargets uses remotesecrets.ClassifyTargets to find out what to do with targets in the spec and status
+// and does what the classification tells it to.
+func (p *targetsProcessor[K]) processTargets(ctx context.Context, errorAggregate *rerror.AggregatedError) {
+	// the targets referencing the clusters by name are classified by the API URLs of the clusters like any other remote target.
+	var unresolvedClusters map[remotesecrets.SpecTargetIndex]error
+	p.Targets, unresolvedClusters = remotesecrets.ResolveClusterTargets(ctx, p.clusterRegistry(), p.Object.GetNamespace(), p.Targets)
+
+	namespaceClassification := remotesecrets.ClassifyTargets(p.Targets, p.Status.Targets)
+	synced := 0
+	p.failedOptionalTargets = 0
+
+	// the targets are deployed to in waves. The targets in a wave are only deployed to if all the targets in the previous
+	// waves have been deployed to successfully.
+	waves := map[int][]remotesecrets.SpecTargetIndex{}
+	for specIdx, statusIdx := range namespaceClassification.Sync {
+		if err, ok := unresolvedClusters[specIdx]; ok {
+			// the target must not be deployed to, because without the API URL it would point at the local cluster.
+			status := p.targetStatus(statusIdx)
+			status.ApiUrl = ""
+			status.Namespace = p.Targets[specIdx].Namespace
+			status.Error = fmt.Sprintf("failed to resolve the cluster %s: %s", p.Targets[specIdx].Cluster, err.Error())
+			status.ErrorReason = string(bindings.ClassifyTargetError(err))
+			errorAggregate.Add(err)
+			continue
+		}
+
+		if apiUrl := p.Targets[specIdx].ApiUrl; apiUrl != "" && remotesecrets.IsLocalApiUrl(apiUrl, p.LocalApiUrl) {
+			// the local targets with the API URL would escape the uniqueness checks of the targets, so we refuse them. Retrying
+			// doesn't help, so this is not reported as an error, either.
+			status := p.targetStatus(statusIdx)
+			status.ApiUrl = apiUrl
+			status.Namespace = p.Targets[specIdx].Namespace
+			status.Error = fmt.Sprintf("%s: remove the apiUrl to deploy to the local cluster", remotesecrets.RedundantApiUrlError)
+			status.ErrorReason = string(bindings.ErrorReasonRedundantApiUrl)
+			continue
+		}
+
+		if err := remotesecrets.CheckSelfReferencingTarget(p.Object.GetNamespace(), p.DataSources, p.SecretSpec.Name, &p.Targets[specIdx]); err != nil {
+			// deploying would overwrite the data source with its own data over and over again. Retrying doesn't help, so this is
+			// not reported as an error, just like the other refused targets.
+			status := p.targetStatus(statusIdx)
+			status.ApiUrl = p.Targets[specIdx].ApiUrl
+			status.Namespace = p.Targets[specIdx].Namespace
+			status.Error = err.Error()
+			status.ErrorReason = string(bindings.ErrorReasonSelfReferencingTarget)
+			continue
+		}
+
+		if err := p.NamespacePolicy.Check(ctx, p.Client, p.Object.GetNamespace(), &p.Targets[specIdx]); err != nil {
+			status := p.targetStatus(statusIdx)
+			status.ApiUrl = p.Targets[specIdx].ApiUrl
+			status.Namespace = p.Targets[specIdx].Namespace
+			status.Error = err.Error()
+			if errors.Is(err, remotesecrets.TargetNamespaceNotAllowedError) {
+				// retrying doesn't help with the disallowed targets, so they are not reported as errors, just like the duplicates.
+				status.ErrorReason = string(bindings.ErrorReasonTargetNotAllowed)
+			} else {
+				status.ErrorReason = ""
+				errorAggregate.Add(err)
+			}
+			continue
+		}
+
+		wave := p.Targets[specIdx].Wave
+		waves[wave] = append(waves[wave], specIdx)
+	}
+	waveNumbers := make([]int, 0, len(waves))
+	for wave := range waves {
+		waveNumbers = append(waveNumbers, wave)
+	}
+	sort.Ints(waveNumbers)
+
+	// the generation of the object the data has been computed for. We check that it is still current before writing to the
+	// remote clusters, because the writes can take a long time and we don't want to overwrite newer data with older.
+	generation := p.Object.GetGeneration()
+
+	blockingWave := 0
+	blocked := false
+	for _, wave := range waveNumbers {
+		specIdxs := waves[wave]
+		sort.Slice(specIdxs, func(i, j int) bool {
+			return specIdxs[i] < specIdxs[j]
+		})
+
+		// as per docs, ClassifyTargets uses -1 to indicate that the target is not in the status.
+		// So we just add a new empty entry to status and use that to deploy to the namespace.
+		// deployToNamespace will fill it in. All the entries of the wave are added before the deployment starts so that
+		// the pointers to them stay valid while the targets are deployed to concurrently.
+		statusIdxs := make([]remotesecrets.StatusTargetIndex, len(specIdxs))
+		for i, specIdx := range specIdxs {
+			statusIdx := namespaceClassification.Sync[specIdx]
+			if statusIdx == -1 {
+				p.Status.Targets = append(p.Status.Targets, api.TargetStatus{})
+				statusIdx = remotesecrets.StatusTargetIndex(len(p.Status.Targets) - 1)
+			}
+			statusIdxs[i] = statusIdx
+		}
+
+		if blocked {
+			for i, specIdx := range specIdxs {
+				spec := &p.Targets[specIdx]
+				status := &p.Status.Targets[statusIdxs[i]]
+				status.ApiUrl = spec.ApiUrl
+				status.Namespace = spec.Namespace
+				status.Error = fmt.Sprintf("waiting for the targets in the wave %d to be deployed to", blockingWave)
+				status.ErrorReason = ""
+			}
+			continue
+		}
+
+		errs := p.deployWave(ctx, generation, specIdxs, statusIdxs)
+
+		waveFailed := false
+		for i, err := range errs {
+			if err == nil {
+				synced++
+			} else if p.Targets[specIdxs[i]].Optional {
+				// the failure is recorded in the status of the target but it doesn't fail the deployment.
+				p.failedOptionalTargets++
+			} else {
+				errorAggregate.Add(err)
+				waveFailed = true
+			}
+		}
+
+		if waveFailed && !blocked {
+			blocked = true
+			blockingWave = wave
+		}
+	}
+
+	toRemove := make([]remotesecrets.StatusTargetIndex, 0, len(namespaceClassification.Remove)+len(namespaceClassification.OrphanDuplicateStatuses))
+
+	for _, statusIdx := range namespaceClassification.Remove {
+		err := p.deleteFromNamespace(ctx, &p.Status.Targets[statusIdx])
+		if err != nil {
+			// we keep the target in the status so that we can retry the cleanup later.
+			errorAggregate.Add(err)
+		} else {
+			toRemove = append(toRemove, statusIdx)
+		}
+	}
+
+	// the objects that were only referenced (not managed) by the removed targets don't get deleted, so we need to make sure they no longer
+	// reference the remote secret.
+	if err := remotesecrets.UnreferenceObjectsOutOfScope(ctx, p.Client, p.objectMarker(""), client.ObjectKeyFromObject(p.Object), p.Targets); err != nil {
+		errorAggregate.Add(err)
+	}
+
+	// the secrets left behind in the namespaces that are no longer targeted, e.g. because their cleanup was skipped, are deleted, too.
+	// The status targets still contain the removed targets and the inventory is not yet updated at this point, so they describe
+	// where the secrets were deployed to previously.
+	if err := remotesecrets.DeleteOrphanedManagedSecrets(ctx, p.Client, p.objectMarker(""), client.ObjectKeyFromObject(p.Object), p.Targets, p.Status.Targets, p.Status.ManagedSecrets); err != nil {
+		errorAggregate.Add(err)
+	}
+
+	// mark the duplicates...
+	for originalIdx, duplicates := range namespaceClassification.DuplicateTargetSpecs {
+		for specIdx, statusIdx := range duplicates {
+			status := p.targetStatus(statusIdx)
+			// clear out the status and just set the key and error
+			*status = api.TargetStatus{
+				ApiUrl:    p.Targets[specIdx].ApiUrl,
+				Namespace: p.Targets[specIdx].Namespace,
+				Error:     fmt.Sprintf("the target at the index %d is a duplicate of the target at the index %d", specIdx, originalIdx),
+			}
+		}
+	}
+
+	// and finally, remove the orphaned and deleted targets from the status
+	toRemove = append(toRemove, namespaceClassification.OrphanDuplicateStatuses...)
+	// sort the array in reverse order so that we can remove from the status without reindexing
+	sort.Slice(toRemove, func(i, j int) bool {
+		return toRemove[i] > toRemove[j]
+	})
+
+	for _, stIdx := range toRemove {
+		p.Status.Targets = append(p.Status.Targets[:stIdx], p.Status.Targets[stIdx+1:]...)
+	}
+
+	p.Status.ManagedSecrets = remotesecrets.ManagedSecretInventory(p.SecretSpec.DeploymentMode, p.Status.ManagedSecrets, p.Status.Targets)
+
+	// the duplicate targets are counted in the total but are never synced.
+	p.Status.TotalTargets = len(p.Targets)
+	p.Status.SyncedTargets = synced
+
+	// the forced sync is retried until it succeeds for all targets. The generation is only recorded as observed once
+	// all its targets have been deployed to, so that the status never claims success for outdated data.
+	if !errorAggregate.HasErrors() {
+		p.Status.ObservedForceSync = p.Object.GetAnnotations()[api.ForceSyncAnnotation]
+		p.Status.ObservedGeneration = generation
+	}
+}
+
+// updateStatus updates the status of the Object in the cluster. It must be called with the statusLock held. A copy of the Object is
+// updated so that the concurrent deployments can keep reading the Object while the response of the cluster is being read. Only the
+// resource version is copied back to the Object.
+func (p *targetsProcessor[K]) updateStatus(ctx context.Context) error {
+	obj := p.Object.DeepCopyObject().(client.Object)
+	if err := p.Client.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update the status: %w", err)
+	}
+	p.Object.SetResourceVersion(obj.GetResourceVersion())
+	return nil
+}
+
+// checkNotSuperseded returns an error if the object in the cluster has a newer generation than the provided one.
+func (p *targetsProcessor[K]) checkNotSuperseded(ctx context.Context, generation int64) error {
+	p.statusLock.Lock()
+	current := p.Object.DeepCopyObject().(client.Object)
+	p.statusLock.Unlock()
+
+	if err := p.Client.Get(ctx, client.ObjectKeyFromObject(p.Object), current); err != nil {
+		return fmt.Errorf("failed to check the current generation of the object before deploying to the remote target: %w", err)
+	}
+
+	if current.GetGeneration() != generation {
+		return fmt.Errorf("%w: deploying generation %d but the current generation is %d", objectSupersededError, generation, current.GetGeneration())
+	}
+
+	return nil
+}
+
+// deployWave deploys to the targets with the provided spec indices using the status entries with the provided indices. The targets
+// are deployed to concurrently, at most Concurrency at a time. The failure to deploy to one target doesn't prevent the deployment
+// to the others. The returned errors correspond to the provided indices.
+func (p *targetsProcessor[K]) deployWave(ctx context.Context, generation int64, specIdxs []remotesecrets.SpecTargetIndex, statusIdxs []remotesecrets.StatusTargetIndex) []error {
+	concurrency := p.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(specIdxs))
+	semaphore := make(chan struct{}, concurrency)
+	wg := gosync.WaitGroup{}
+
+	for i := range specIdxs {
+		spec := &p.Targets[specIdxs[i]]
+		status := &p.Status.Targets[statusIdxs[i]]
+		idx := i
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			var err error
+			if spec.ApiUrl != "" {
+				err = p.checkNotSuperseded(ctx, generation)
+			}
+			if err != nil {
+				p.statusLock.Lock()
+				status.ApiUrl = spec.ApiUrl
+				status.Namespace = spec.Namespace
+				status.Error = err.Error()
+				status.ErrorReason = ""
+				p.statusLock.Unlock()
+			} else {
+				err = p.deployToNamespace(ctx, spec, status)
+			}
+			errs[idx] = err
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// targetStatus returns the pointer to the target status with the provided index. If the index is -1, a new empty status is appended
+// to the status targets and returned.
+func (p *targetsProcessor[K]) targetStatus(statusIdx remotesecrets.StatusTargetIndex) *api.TargetStatus {
+	if statusIdx == -1 {
+		p.Status.Targets = append(p.Status.Targets, api.TargetStatus{})
+		return &p.Status.Targets[len(p.Status.Targets)-1]
+	}
+	return &p.Status.Targets[statusIdx]
+}
+
+// deployToNamespace deploys the secret to the provided tartet and fills in the provided status with the result of the deployment. The status will also contain the error
+// if the deployment failed. This returns an error if the deployment fails (this is recorded in the target status) OR if the update of the status in k8s fails (this is,
+// obviously, not recorded in the target status).
+func (p *targetsProcessor[K]) deployToNamespace(ctx context.Context, targetSpec *api.RemoteSecretTarget, targetStatus *api.TargetStatus) error {
+	debugLog := log.FromContext(ctx).V(logs.DebugLevel)
+
+	depHandler, err := p.newDependentsHandler(ctx, targetSpec, targetStatus)
+	if err != nil {
+		return p.recordClientError(ctx, targetSpec, targetStatus, err)
+	}
+
+	checkPoint, syncErr := depHandler.CheckPoint(ctx)
+	if syncErr != nil {
+		return fmt.Errorf("failed to construct a checkpoint before dependent objects deployment: %w", syncErr)
+	}
+
+	deps, errorReason, syncErr := depHandler.Sync(ctx, p.Object)
+
+	p.statusLock.Lock()
+	targetStatus.ApiUrl = targetSpec.ApiUrl
+	targetStatus.ClusterCredentialsSecret = targetSpec.ClusterCredentialsSecret
+
+	if syncErr == nil {
+		targetStatus.Namespace = deps.Secret.Namespace
+		targetStatus.SecretName = deps.Secret.Name
+		targetStatus.DefaultSecretName = p.SecretSpec.Name == "" && p.SecretSpec.GenerateName == "" && p.SecretSpec.GenerateNamePrefix == ""
+		targetStatus.ConfigMapName = deps.ConfigMapName
+		targetStatus.SecretUID = deps.Secret.UID
+		targetStatus.SecretResourceVersion = deps.Secret.ResourceVersion
+
+		targetStatus.ServiceAccountNames = make([]string, len(deps.ServiceAccounts))
+		for i, sa := range deps.ServiceAccounts {
+			targetStatus.ServiceAccountNames[i] = sa.Name
+		}
+		targetStatus.Error = ""
+		targetStatus.ErrorReason = ""
+		if targetStatus.DataHash != deps.DataHash || targetStatus.DataSyncTime == nil {
+			now := metav1.Now()
+			targetStatus.DataHash = deps.DataHash
+			targetStatus.DataSyncTime = &now
+		}
+	} else {
+		targetStatus.Namespace = targetSpec.Namespace
+		targetStatus.SecretName = ""
+		targetStatus.DefaultSecretName = false
+		targetStatus.SecretUID = ""
+		targetStatus.SecretResourceVersion = ""
+		targetStatus.ServiceAccountNames = []string{}
+		targetStatus.Error = syncErr.Error()
+		// prefer the classification of the errors caused by the cluster of the target, because those tell the most about what to fix.
+		if reason := bindings.ClassifyTargetError(syncErr); reason != bindings.ErrorReasonNone {
+			targetStatus.ErrorReason = string(reason)
+		} else {
+			targetStatus.ErrorReason = errorReason
+		}
+	}
+
+	updateErr := p.updateStatus(ctx)
+	p.statusLock.Unlock()
+
+	if syncErr != nil || updateErr != nil {
+		if syncErr != nil {
+			p.LogSuppressor.Error(debugLog, p.targetLogKey(targetSpec), syncErr, "failed to sync the dependent objects")
+		}
+
+		if updateErr != nil {
+			debugLog.Error(updateErr, "failed to update the status with the info about dependent objects")
+		}
+
+		if rerr := depHandler.RevertTo(ctx, checkPoint); rerr != nil {
+			debugLog.Error(rerr, "failed to revert the sync of the dependent objects of the remote secret after a failure", "statusUpdateError", updateErr, "syncError", syncErr)
+		}
+	} else {
+		p.LogSuppressor.Forget(p.targetLogKey(targetSpec))
+	}
+
+	if syncErr == nil && updateErr == nil && debugLog.Enabled() {
+		saks := make([]client.ObjectKey, len(deps.ServiceAccounts))
+		for i, sa := range deps.ServiceAccounts {
+			saks[i] = client.ObjectKeyFromObject(sa)
+		}
+		debugLog.Info("successfully synced dependent objects of remote secret", "remoteSecret", client.ObjectKeyFromObject(p.Object), "syncedSecret", client.ObjectKeyFromObject(deps.Secret))
+	}
+	//TODO Think about proper fix. this fix is not working.
+	//return fmt.Errorf("aggregate error: %w", rerror.AggregateNonNilErrors(syncErr, updateErr))
+	//nolint:wrapcheck
+	return rerror.AggregateNonNilErrors(syncErr, updateErr)
+}
+
+// recordClientError records the failure to create the client of the target in the target status. Nothing has been deployed
+// to the target in this case, so there is nothing to revert.
+func (p *targetsProcessor[K]) recordClientError(ctx context.Context, targetSpec *api.RemoteSecretTarget, targetStatus *api.TargetStatus, clientErr error) error {
+	p.statusLock.Lock()
+	defer p.statusLock.Unlock()
+
+	targetStatus.ApiUrl = targetSpec.ApiUrl
+	targetStatus.ClusterCredentialsSecret = targetSpec.ClusterCredentialsSecret
+	targetStatus.Namespace = targetSpec.Namespace
+	targetStatus.SecretName = ""
+	targetStatus.DefaultSecretName = false
+	targetStatus.SecretUID = ""
+	targetStatus.SecretResourceVersion = ""
+	targetStatus.ServiceAccountNames = []string{}
+	targetStatus.Error = clientErr.Error()
+	targetStatus.ErrorReason = string(bindings.ClassifyTargetError(clientErr))
+
+	//nolint:wrapcheck
+	return rerror.AggregateNonNilErrors(clientErr, p.updateStatus(ctx))
+}
+
+// deleteFromNamespace cleans up the dependent objects of the target with the provided status. It is up to the caller to remove the status from
+// the list of the target statuses.
+func (p *targetsProcessor[K]) deleteFromNamespace(ctx context.Context, targetStatus *api.TargetStatus) error {
+	if targetStatus.SecretName == "" {
+		// the secret might still exist even though the last deployment to the target failed. Let's look for it using the inventory
+		// but don't modify the status, because it is still being used by the caller.
+		if name := remotesecrets.InventoriedSecretName(p.Status.ManagedSecrets, targetStatus.ApiUrl, targetStatus.Namespace); name != "" {
+			inventoried := *targetStatus
+			inventoried.SecretName = name
+			targetStatus = &inventoried
+		}
+	}
+
+	dep, err := p.newDependentsHandler(ctx, nil, targetStatus)
+	if err != nil {
+		return fmt.Errorf("failed to clean up dependent objects of the target in the namespace %s: %w", targetStatus.Namespace, err)
+	}
+
+	if err := dep.Cleanup(ctx); err != nil {
+		return fmt.Errorf("failed to clean up dependent objects of the target in the namespace %s: %w", targetStatus.Namespace, err)
+	}
+
+	return nil
+}
+
+// cleanup cleans up the dependent objects in all targets recorded in the status. The objects are either deleted or orphaned
+// depending on the deletion policy.
+func (p *targetsProcessor[K]) cleanup(ctx context.Context) error {
+	for i := range p.Status.Targets {
+		var err error
+		if p.DeletionPolicy == api.DeletionPolicyOrphan {
+			err = p.orphanInNamespace(ctx, &p.Status.Targets[i])
+		} else {
+			err = p.deleteFromNamespace(ctx, &p.Status.Targets[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orphanInNamespace leaves the dependent objects of the target with the provided status in place but removes the markings linking
+// them to the remote secret.
+func (p *targetsProcessor[K]) orphanInNamespace(ctx context.Context, targetStatus *api.TargetStatus) error {
+	dep, err := p.newDependentsHandler(ctx, nil, targetStatus)
+	if err != nil {
+		return fmt.Errorf("failed to orphan the dependent objects of the target in the namespace %s: %w", targetStatus.Namespace, err)
+	}
+
+	if err := dep.Orphan(ctx); err != nil {
+		return fmt.Errorf("failed to orphan the dependent objects of the target in the namespace %s: %w", targetStatus.Namespace, err)
+	}
+
+	return nil
+}
+
+// newDependentsHandler creates a new dependents handler for the target. The target spec can be nil if the handler is only used to clean up
+// the dependent objects of a target that is no longer in the spec.
+func (p *targetsProcessor[K]) newDependentsHandler(ctx context.Context, targetSpec *api.RemoteSecretTarget, targetStatus *api.TargetStatus) (bindings.DependentsHandler[K], error) {
+	apiUrl := targetStatus.ApiUrl
+	credentialsSecret := targetStatus.ClusterCredentialsSecret
+	var keyFilter *api.KeyFilter
+	var secretType corev1.SecretType
+	if targetSpec != nil {
+		apiUrl = targetSpec.ApiUrl
+		credentialsSecret = targetSpec.ClusterCredentialsSecret
+		keyFilter = targetSpec.KeyFilter
+		secretType = targetSpec.Type
+	}
+
+	cl, err := p.clientForTarget(ctx, apiUrl, credentialsSecret)
+	if err != nil {
+		return bindings.DependentsHandler[K]{}, err
+	}
+
+	var coalescer *bindings.ContributionCoalescer
+	if apiUrl == "" {
+		coalescer = p.Coalescer
+	}
+
+	return bindings.DependentsHandler[K]{
+		Target: &namespacetarget.NamespaceTarget{
+			Client:       cl,
+			TargetKey:    client.ObjectKeyFromObject(p.Object),
+			SecretSpec:   p.SecretSpec,
+			TargetSpec:   targetSpec,
+			TargetStatus: targetStatus,
+		},
+		SecretDataGetter:      p.NewSecretDataGetter(keyFilter, secretType),
+		ObjectMarker:          p.objectMarker(apiUrl),
+		ForceSecretUpdate:     p.forceSync(),
+		SpecChanged:           p.Object.GetGeneration() != p.Status.ObservedGeneration,
+		RecordedDataHash:      targetStatus.DataHash,
+		RecordedSecretUID:     targetStatus.SecretUID,
+		ServerSideApply:       p.ServerSideApply,
+		ContributionCoalescer: coalescer,
+	}, nil
+}
+
+// logKey identifies the Object in the LogSuppressor.
+func (p *targetsProcessor[K]) logKey() string {
+	return fmt.Sprintf("%T:%s", p.Object, client.ObjectKeyFromObject(p.Object))
+}
+
+// targetLogKey identifies the target of the Object in the LogSuppressor.
+func (p *targetsProcessor[K]) targetLogKey(targetSpec *api.RemoteSecretTarget) string {
+	return fmt.Sprintf("%s:%s:%s", p.logKey(), targetSpec.ApiUrl, targetSpec.Namespace)
+}
+
+// objectMarker returns the marker of the objects in the targets in the cluster with the provided API URL.
+func (p *targetsProcessor[K]) objectMarker(apiUrl string) *namespacetarget.NamespaceObjectMarker {
+	return &namespacetarget.NamespaceObjectMarker{ApiUrl: apiUrl, Domain: p.MarkerDomain}
+}
+
+// forceSync returns true if a full re-sync of the targets has been requested using the force-sync annotation and not yet
+// processed.
+func (p *targetsProcessor[K]) forceSync() bool {
+	return forceSyncRequested(p.Object, p.Status)
+}
+
+// forceSyncRequested returns true if the force-sync annotation of the object differs from the value last processed by the controller.
+func forceSyncRequested(obj client.Object, status *api.RemoteSecretStatus) bool {
+	return obj.GetAnnotations()[api.ForceSyncAnnotation] != status.ObservedForceSync
+}
+
+// clientForTarget returns the client to use with the target in the cluster with the provided API URL. The client of the remote
+// cluster is created using the credentials in the secret with the provided name in the namespace of the Object.
+func (p *targetsProcessor[K]) clientForTarget(ctx context.Context, apiUrl string, credentialsSecret string) (client.Client, error) {
+	if apiUrl == "" {
+		return p.Client, nil
+	}
+
+	if credentialsSecret == "" {
+		return nil, fmt.Errorf("%w: no cluster credentials secret specified for the target in the cluster %s", bindings.InvalidClusterCredentialsError, apiUrl)
+	}
+
+	if p.Object.GetNamespace() == "" {
+		return nil, fmt.Errorf("%w: the targets in the remote clusters are only supported for the namespaced objects", bindings.InvalidClusterCredentialsError)
+	}
+
+	factory := p.RemoteClients
+	if factory == nil {
+		factory = remotesecrets.CredentialsClientFactory(p.Client)
+	}
+
+	return factory(ctx, apiUrl, client.ObjectKey{Name: credentialsSecret, Namespace: p.Object.GetNamespace()})
+}
+
+// clusterRegistry returns the ClusterRegistry or the registry of the cluster secrets if it is not configured.
+func (p *targetsProcessor[K]) clusterRegistry() remotesecrets.ClusterRegistry {
+	if p.ClusterRegistry != nil {
+		return p.ClusterRegistry
+	}
+	return &remotesecrets.SecretClusterRegistry{Client: p.Client}
+}
+
+// targetDeploymentConcurrency returns the concurrency of the deployment to the targets configured in the provided operator configuration.
+func targetDeploymentConcurrency(cfg *opconfig.OperatorConfiguration) int {
+	if cfg == nil {
+		return 1
+	}
+	return cfg.TargetDeploymentConcurrency
+}
+
+// targetNamespacePolicy returns the policy restricting the target namespaces configured in the provided operator configuration.
+func targetNamespacePolicy(cfg *opconfig.OperatorConfiguration) remotesecrets.TargetNamespacePolicy {
+	if cfg == nil {
+		return remotesecrets.TargetNamespacePolicy{}
+	}
+	return remotesecrets.TargetNamespacePolicy{
+		Type:        remotesecrets.TargetNamespacePolicyType(cfg.TargetNamespacePolicy),
+		TenantLabel: cfg.TenantLabel,
+	}
+}
+
+// markerDomain returns the domain of the label and annotations marking the objects in the targets configured in the provided
+// operator configuration.
+func markerDomain(cfg *opconfig.OperatorConfiguration) string {
+	if cfg == nil {
+		return ""
+	}
+	return cfg.MarkerDomain
+}
+
+// maxTargets returns the limit of the number of targets given by both the operator configuration and the provided limit of the remote
+// secret. Zero means no limit.
+func maxTargets(cfg *opconfig.OperatorConfiguration, limit int) int {
+	if cfg != nil && cfg.MaxTargets > 0 && (limit <= 0 || cfg.MaxTargets < limit) {
+		return cfg.MaxTargets
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// serverSideApply returns true if the server-side apply of the secrets is enabled in the provided operator configuration.
+func serverSideApply(cfg *opconfig.OperatorConfiguration) bool {
+	return cfg != nil && cfg.ServerSideApply
+}
@@ -27,6 +27,7 @@ import (
 	kuberrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	"github.com/redhat-appstudio/remote-secret/pkg/upload"
 
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -46,10 +47,10 @@ import (
 )
 
 const (
-	uploadSecretLabel          = "appstudio.redhat.com/upload-secret"     //#nosec G101 -- false positive, this is not a token
-	remoteSecretNameAnnotation = "appstudio.redhat.com/remotesecret-name" //#nosec G101 -- false positive, this is not a token
-	targetTypeAnnotation       = "appstudio.redhat.com/remotesecret-target-type"
-	targetNameAnnotation       = "appstudio.redhat.com/remotesecret-target-name"
+	uploadSecretLabel          = upload.SecretLabel
+	remoteSecretNameAnnotation = upload.RemoteSecretNameAnnotation
+	targetTypeAnnotation       = upload.TargetTypeAnnotation
+	targetNameAnnotation       = upload.TargetNameAnnotation
 )
 
 var (
@@ -58,7 +59,7 @@ var (
 )
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;delete
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;watch;create;update;list;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;watch;create;update;patch;list;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;delete
 
 // TokenUploadReconciler reconciles a Secret object
@@ -148,7 +149,7 @@ func (r *TokenUploadReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		MatchExpressions: []metav1.LabelSelectorRequirement{
 			{
 				Key:      uploadSecretLabel,
-				Values:   []string{"remotesecret"},
+				Values:   []string{upload.SecretLabelValue},
 				Operator: metav1.LabelSelectorOpIn,
 			},
 		},
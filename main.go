@@ -20,16 +20,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/alexflint/go-arg"
 	"github.com/redhat-appstudio/remote-secret/controllers"
+	"github.com/redhat-appstudio/remote-secret/controllers/namespacetarget"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
 	"github.com/redhat-appstudio/remote-secret/pkg/cmd"
 	"github.com/redhat-appstudio/remote-secret/pkg/config"
 	"github.com/redhat-appstudio/remote-secret/pkg/logs"
+	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -38,12 +44,16 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	apiv1beta2 "github.com/redhat-appstudio/remote-secret/api/v1beta2"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// secretStorageHealthCheckTimeout is the maximum time the readiness check waits for the secret storage to respond.
+const secretStorageHealthCheckTimeout = 5 * time.Second
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -53,6 +63,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(api.AddToScheme(scheme))
+	utilruntime.Must(apiv1beta2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -74,6 +85,16 @@ func main() {
 	ctx = context.WithValue(ctx, config.InstanceIdContextKey, args.CommonCliArgs.InstanceId)
 	ctx = log.IntoContext(ctx, ctrl.Log.WithValues("instanceId", args.CommonCliArgs.InstanceId))
 
+	if args.NormalizeLinks {
+		normalizeLinkAnnotations(ctx)
+		return
+	}
+
+	if args.MigrateMarkerDomain {
+		migrateMarkerDomain(ctx, args.MarkerDomain)
+		return
+	}
+
 	mgr, mgrErr := createManager(args)
 	if mgrErr != nil {
 		setupLog.Error(mgrErr, "unable to start manager")
@@ -97,6 +118,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	if args.EnableWebhooks {
+		if err = (&apiv1beta2.RemoteSecret{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "failed to set up the webhooks")
+			os.Exit(1)
+		}
+		if err = (&api.RemoteSecret{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "failed to set up the webhooks")
+			os.Exit(1)
+		}
+		probeWebhook, err := remotesecrets.NewTargetProbeWebhook(&remotesecrets.TargetProbe{Client: mgr.GetClient(), Timeout: cfg.RemoteTargetProbeTimeout})
+		if err != nil {
+			setupLog.Error(err, "failed to set up the webhooks")
+			os.Exit(1)
+		}
+		mgr.GetWebhookServer().Register(remotesecrets.TargetProbeWebhookPath, &webhook.Admission{Handler: probeWebhook})
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -107,6 +145,10 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("secretstorage", secretstorage.ReadinessCheck(secretStorageHealthCheckTimeout, secretStorage)); err != nil {
+		setupLog.Error(err, "unable to set up the secret storage ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -115,8 +157,48 @@ func main() {
 	}
 }
 
+// normalizeLinkAnnotations runs the one-shot normalization of the link annotations in the whole cluster.
+func normalizeLinkAnnotations(ctx context.Context) {
+	cl, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "failed to create the client")
+		os.Exit(1)
+	}
+
+	updated, err := namespacetarget.NormalizeLinkAnnotations(ctx, cl)
+	if err != nil {
+		setupLog.Error(err, "failed to normalize the link annotations", "updatedObjects", updated)
+		os.Exit(1)
+	}
+
+	setupLog.Info("normalized the link annotations", "updatedObjects", updated)
+}
+
+// migrateMarkerDomain runs the one-shot move of the marks of the objects linked to the remote secrets in the whole cluster to the
+// provided domain.
+func migrateMarkerDomain(ctx context.Context, domain string) {
+	if domain == namespacetarget.DefaultMarkerDomain {
+		setupLog.Info("the marker domain is the default one, nothing to migrate", "domain", domain)
+		return
+	}
+
+	cl, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "failed to create the client")
+		os.Exit(1)
+	}
+
+	updated, err := namespacetarget.MigrateMarkerDomain(ctx, cl, domain)
+	if err != nil {
+		setupLog.Error(err, "failed to migrate the link annotations to the configured domain", "domain", domain, "updatedObjects", updated)
+		os.Exit(1)
+	}
+
+	setupLog.Info("migrated the link annotations to the configured domain", "domain", domain, "updatedObjects", updated)
+}
+
 func LoadFrom(args *cmd.OperatorCliArgs) (config.OperatorConfiguration, error) {
-	ret := config.OperatorConfiguration{EnableRemoteSecrets: args.EnableRemoteSecrets, EnableTokenUpload: args.EnableRemoteSecrets}
+	ret := config.OperatorConfiguration{EnableRemoteSecrets: args.EnableRemoteSecrets, EnableTokenUpload: args.EnableRemoteSecrets, RequeueJitterPercent: args.RequeueJitterPercent, TargetDeploymentConcurrency: args.TargetConcurrency, DataCacheTTL: args.DataCacheTTL, DataCacheMaxSize: args.DataCacheMaxSize, DataStoreFailureThreshold: args.DataStoreFailures, DataStoreCircuitCooldown: args.DataStoreCooldown, ServerSideApply: args.ServerSideApply, TargetNamespacePolicy: args.TargetNsPolicy, TenantLabel: args.TenantLabel, MarkerDomain: args.MarkerDomain, MaxTargets: args.MaxTargets, ContributionBatchWindow: args.ContributionWindow, SyncErrorLogWindow: args.SyncErrorLogWindow, RemoteTargetProbeTimeout: args.TargetProbeTimeout}
 	return ret, nil
 }
 
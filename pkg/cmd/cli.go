@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/awsstorage/awscli"
 	"github.com/redhat-appstudio/remote-secret/pkg/secretstorage/vaultstorage/vaultcli"
 )
@@ -44,8 +46,25 @@ type CommonCliArgs struct {
 type OperatorCliArgs struct {
 	CommonCliArgs
 	LoggingCliArgs
-	EnableLeaderElection bool `arg:"--leader-elect, env" default:"false" help:"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager."`
-	EnableRemoteSecrets  bool `arg:"--enable-remote-secrets, env" default:"true" help:"Enable the RemoteSecret controller."`
+	EnableLeaderElection bool          `arg:"--leader-elect, env" default:"false" help:"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager."`
+	EnableRemoteSecrets  bool          `arg:"--enable-remote-secrets, env" default:"true" help:"Enable the RemoteSecret controller."`
+	RequeueJitterPercent int           `arg:"--requeue-jitter-percent, env" default:"10" help:"The maximum random jitter added to the requeue intervals, in percent of the interval."`
+	EnableWebhooks       bool          `arg:"--enable-webhooks, env" default:"false" help:"Enable the conversion and defaulting webhooks. The webhook server requires the serving certificates to be mounted."`
+	TargetConcurrency    int           `arg:"--target-deployment-concurrency, env" default:"4" help:"The maximum number of targets of a single remote secret that are deployed to concurrently."`
+	DataCacheTTL         time.Duration `arg:"--data-cache-ttl, env" default:"0s" help:"The time for which the secret data obtained from the data stores is cached in memory. The remote secrets copying the data from the same source share the cached data. Zero (the default) disables the caching."`
+	DataCacheMaxSize     int           `arg:"--data-cache-max-size, env" default:"1000" help:"The maximum number of the secret data entries cached in memory. Zero disables the caching."`
+	DataStoreFailures    int           `arg:"--data-store-failure-threshold, env" default:"0" help:"The number of the consecutive failures of a data store backend after which it is not called for the cooldown period. The circuit breaking is opt-in: it is disabled by the default of zero and enabled by a positive value."`
+	DataStoreCooldown    time.Duration `arg:"--data-store-circuit-cooldown, env" default:"30s" help:"The time for which a failing data store backend is not called. It is doubled each time the backend keeps failing."`
+	ServerSideApply      bool          `arg:"--server-side-apply, env" default:"false" help:"Write the secrets in the targets using the server-side apply so that the fields set by other controllers are left intact."`
+	TargetNsPolicy       string        `arg:"--target-namespace-policy, env" default:"any" help:"Restricts the namespaces the remote secrets can deploy to. One of 'any', 'own-namespace' or 'same-tenant'. Doesn't apply to the cluster remote secrets."`
+	TenantLabel          string        `arg:"--tenant-label, env" default:"" help:"The label of the namespaces identifying their tenant. Required by the 'same-tenant' target namespace policy."`
+	MarkerDomain         string        `arg:"--marker-domain, env" default:"appstudio.redhat.com" help:"The domain of the label and annotations linking the objects in the targets to the remote secrets. The objects linked using the default domain are still recognized but can be moved to the configured domain using --migrate-marker-domain."`
+	MaxTargets           int           `arg:"--max-targets, env" default:"0" help:"The maximum number of the targets a single remote secret can deploy to. The remote secrets with more targets are not deployed at all. Zero means no limit."`
+	ContributionWindow   time.Duration `arg:"--contribution-batch-window, env" default:"0s" help:"The time for which the contributions of multiple remote secrets to the same secret are collected to be written in a single update. Zero disables the coalescing."`
+	SyncErrorLogWindow   time.Duration `arg:"--sync-error-log-window, env" default:"10m" help:"The time for which the identical repeated errors of the deployment of a remote secret are not logged again. Zero disables the suppression."`
+	TargetProbeTimeout   time.Duration `arg:"--remote-target-probe-timeout, env" default:"0s" help:"The time for which the validating webhook probes the reachability of the remote targets of the created remote secrets. The unreachable targets are only reported as warnings. Zero disables the probe. Requires the webhooks to be enabled."`
+	NormalizeLinks       bool          `arg:"--normalize-link-annotations, env" default:"false" help:"Sort the values of the annotations linking the secrets and service accounts to the remote secrets in the whole cluster and exit."`
+	MigrateMarkerDomain  bool          `arg:"--migrate-marker-domain, env" default:"false" help:"Move the label and annotations linking the secrets and service accounts to the remote secrets in the whole cluster from the default domain to the --marker-domain and exit."`
 }
 
 type TokenStorageType string
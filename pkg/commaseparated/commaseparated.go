@@ -14,7 +14,10 @@
 
 package commaseparated
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // CommaSeparated represents a set of non-empty strings that can be
 // constructed from and serialized into a comma-separated string.
@@ -90,6 +93,17 @@ func (cs *CommaSeparated) Contains(str string) bool {
 	return false
 }
 
+// Sort sorts the values so that the string representation is deterministic.
+func (cs *CommaSeparated) Sort() *CommaSeparated {
+	sort.Strings(cs.value)
+	return cs
+}
+
+// IsSorted returns true if the values are sorted.
+func (cs *CommaSeparated) IsSorted() bool {
+	return sort.StringsAreSorted(cs.value)
+}
+
 func (cs *CommaSeparated) String() string {
 	return strings.Join(cs.value, ",")
 }
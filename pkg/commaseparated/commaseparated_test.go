@@ -88,3 +88,10 @@ func TestContains(t *testing.T) {
 	assert.True(t, cs.Contains("c"))
 	assert.False(t, cs.Contains("d"))
 }
+
+func TestSort(t *testing.T) {
+	cs := Value("c,a,b")
+	assert.False(t, cs.IsSorted())
+	assert.Equal(t, "a,b,c", cs.Sort().String())
+	assert.True(t, cs.IsSorted())
+}
@@ -13,6 +13,8 @@
 
 package config
 
+import "time"
+
 type instanceIdContextKeyType struct{}
 
 var InstanceIdContextKey = instanceIdContextKeyType{}
@@ -22,6 +24,44 @@ type OperatorConfiguration struct {
 	EnableTokenUpload bool
 	// Enable RemoteSecret controller
 	EnableRemoteSecrets bool
+	// RequeueJitterPercent is the maximum random jitter added to the requeue intervals of the reconcilers, expressed
+	// as the percentage of the interval.
+	RequeueJitterPercent int
+	// TargetDeploymentConcurrency is the maximum number of targets of a single remote secret that are deployed to concurrently.
+	TargetDeploymentConcurrency int
+	// DataCacheTTL is the time for which the secret data obtained from the data stores is cached in memory. The caching is
+	// disabled if not positive.
+	DataCacheTTL time.Duration
+	// DataCacheMaxSize is the maximum number of the cached secret data entries. The caching is disabled if not positive.
+	DataCacheMaxSize int
+	// DataStoreFailureThreshold is the number of the consecutive failures of a data store backend after which it is not called
+	// for the DataStoreCircuitCooldown. The circuit breaking is opt-in: it is disabled unless positive, and the default is zero.
+	DataStoreFailureThreshold int
+	// DataStoreCircuitCooldown is the time for which a failing data store backend is not called. It is doubled each time
+	// the backend fails again after the cooldown. The circuit breaking is disabled if not positive.
+	DataStoreCircuitCooldown time.Duration
+	// ServerSideApply makes the secrets in the targets written using the server-side apply instead of being updated as a whole.
+	ServerSideApply bool
+	// TargetNamespacePolicy restricts the namespaces the remote secrets can deploy to. One of "any" (the default),
+	// "own-namespace" or "same-tenant". The cluster remote secrets are not restricted.
+	TargetNamespacePolicy string
+	// TenantLabel is the label of the namespaces identifying their tenant. It is used by the "same-tenant" target namespace policy.
+	TenantLabel string
+	// MarkerDomain is the domain of the label and annotations the objects in the targets are marked with. The objects marked
+	// in the default domain are still recognized if it is changed.
+	MarkerDomain string
+	// MaxTargets is the maximum number of the targets a single remote secret can deploy to. The remote secrets with more targets
+	// are not deployed at all. Zero means no limit.
+	MaxTargets int
+	// ContributionBatchWindow is the time for which the contributions of multiple remote secrets to the same secret in the local
+	// cluster are collected to be written in a single update. The contributions are not coalesced if not positive.
+	ContributionBatchWindow time.Duration
+	// SyncErrorLogWindow is the time for which the identical errors of the deployment of a remote secret are not logged again.
+	// The errors are always logged if not positive.
+	SyncErrorLogWindow time.Duration
+	// RemoteTargetProbeTimeout is the time for which the validating webhook probes the reachability of the remote targets of
+	// the created remote secrets. The probe is disabled if not positive.
+	RemoteTargetProbeTimeout time.Duration
 }
 
 const (
@@ -0,0 +1,123 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// repeatSuppressorMaxEntries is the number of the tracked keys above which the entries outside of the window are forgotten.
+const repeatSuppressorMaxEntries = 1024
+
+// RepeatSuppressor suppresses the identical messages logged repeatedly under the same key within a time window. Once the window
+// passes, the message is logged again together with the number of the suppressed repetitions. A different message under the same
+// key is always logged.
+//
+// The nil suppressor is valid and doesn't suppress anything.
+type RepeatSuppressor struct {
+	window time.Duration
+	now    func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*repeatEntry
+}
+
+type repeatEntry struct {
+	message    string
+	lastLogged time.Time
+	suppressed int
+}
+
+// NewRepeatSuppressor creates a new suppressor with the provided window. If the window is not positive, nil is returned and nothing
+// is suppressed.
+func NewRepeatSuppressor(window time.Duration) *RepeatSuppressor {
+	if window <= 0 {
+		return nil
+	}
+
+	return &RepeatSuppressor{
+		window:  window,
+		now:     time.Now,
+		entries: map[string]*repeatEntry{},
+	}
+}
+
+// Allow returns true if the provided message should be logged under the provided key. It also returns the number of the repetitions
+// of the message that have been suppressed since it was last logged.
+func (s *RepeatSuppressor) Allow(key string, message string) (bool, int) {
+	if s == nil {
+		return true, 0
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	entry, ok := s.entries[key]
+	if ok && entry.message == message && now.Sub(entry.lastLogged) < s.window {
+		entry.suppressed++
+		return false, 0
+	}
+
+	suppressed := 0
+	if ok && entry.message == message {
+		suppressed = entry.suppressed
+	}
+
+	if !ok && len(s.entries) >= repeatSuppressorMaxEntries {
+		s.prune(now)
+	}
+
+	s.entries[key] = &repeatEntry{message: message, lastLogged: now}
+	return true, suppressed
+}
+
+// Forget removes the record of the messages logged under the provided key, e.g. when the object the key belongs to no longer fails.
+func (s *RepeatSuppressor) Forget(key string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.entries, key)
+}
+
+// Error logs the provided error using the provided logger unless the same error has already been logged under the provided key
+// within the window. The number of the suppressed repetitions is added to the logged key-value pairs.
+func (s *RepeatSuppressor) Error(lg logr.Logger, key string, err error, msg string, keysAndValues ...any) {
+	allowed, suppressed := s.Allow(key, msg+": "+err.Error())
+	if !allowed {
+		return
+	}
+
+	if suppressed > 0 {
+		keysAndValues = append(keysAndValues, "suppressedRepeats", suppressed)
+	}
+	lg.Error(err, msg, keysAndValues...)
+}
+
+// prune removes the entries that would not suppress anything anymore. It must be called with the lock held.
+func (s *RepeatSuppressor) prune(now time.Time) {
+	for k, e := range s.entries {
+		if now.Sub(e.lastLogged) >= s.window {
+			delete(s.entries, k)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatSuppressor(t *testing.T) {
+	now := time.Now()
+	newSuppressor := func() *RepeatSuppressor {
+		s := NewRepeatSuppressor(time.Minute)
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, NewRepeatSuppressor(0))
+
+		var s *RepeatSuppressor
+		for i := 0; i < 3; i++ {
+			allowed, _ := s.Allow("k", "msg")
+			assert.True(t, allowed)
+		}
+	})
+
+	t.Run("suppresses within window", func(t *testing.T) {
+		s := newSuppressor()
+
+		allowed, suppressed := s.Allow("k", "msg")
+		assert.True(t, allowed)
+		assert.Equal(t, 0, suppressed)
+
+		for i := 0; i < 3; i++ {
+			allowed, _ = s.Allow("k", "msg")
+			assert.False(t, allowed)
+		}
+
+		// different keys are independent
+		allowed, _ = s.Allow("other", "msg")
+		assert.True(t, allowed)
+
+		s.now = func() time.Time { return now.Add(time.Minute) }
+		allowed, suppressed = s.Allow("k", "msg")
+		assert.True(t, allowed)
+		assert.Equal(t, 3, suppressed)
+	})
+
+	t.Run("different message is logged", func(t *testing.T) {
+		s := newSuppressor()
+
+		allowed, _ := s.Allow("k", "msg")
+		assert.True(t, allowed)
+		allowed, _ = s.Allow("k", "msg")
+		assert.False(t, allowed)
+		allowed, suppressed := s.Allow("k", "another")
+		assert.True(t, allowed)
+		assert.Equal(t, 0, suppressed)
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		s := newSuppressor()
+
+		s.Allow("k", "msg")
+		s.Forget("k")
+		allowed, _ := s.Allow("k", "msg")
+		assert.True(t, allowed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := newSuppressor()
+		var logged []string
+		lg := funcr.New(func(prefix, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{})
+
+		for i := 0; i < 3; i++ {
+			s.Error(lg, "k", errors.New("boom"), "failed")
+		}
+		s.now = func() time.Time { return now.Add(time.Minute) }
+		s.Error(lg, "k", errors.New("boom"), "failed")
+
+		assert.Len(t, logged, 2)
+		assert.NotContains(t, logged[0], "suppressedRepeats")
+		assert.Contains(t, logged[1], `"suppressedRepeats"=2`)
+	})
+}
@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remotesecret contains the helpers for the programmatic construction of the remote secrets. The Builder assembles
+// the remote secret and checks its spec using the same static validation the controller uses.
+package remotesecret
+
+import (
+	"fmt"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Builder assembles a remote secret. The zero value is not usable, use NewBuilder to create a new builder. The methods modify
+// the builder and return it so that the calls can be chained.
+type Builder struct {
+	rs *api.RemoteSecret
+}
+
+// NewBuilder creates a new builder of a remote secret with the provided name in the provided namespace.
+func NewBuilder(name, namespace string) *Builder {
+	return &Builder{
+		rs: &api.RemoteSecret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api.GroupVersion.String(),
+				Kind:       "RemoteSecret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+// WithLabels adds the provided labels to the remote secret itself.
+func (b *Builder) WithLabels(labels map[string]string) *Builder {
+	b.rs.Labels = mergeMaps(b.rs.Labels, labels)
+	return b
+}
+
+// WithAnnotations adds the provided annotations to the remote secret itself.
+func (b *Builder) WithAnnotations(annotations map[string]string) *Builder {
+	b.rs.Annotations = mergeMaps(b.rs.Annotations, annotations)
+	return b
+}
+
+// WithSecretName sets the name of the secret deployed to the targets.
+func (b *Builder) WithSecretName(name string) *Builder {
+	b.rs.Spec.Secret.Name = name
+	return b
+}
+
+// WithSecretGenerateName sets the prefix of the random name of the secret deployed to the targets.
+func (b *Builder) WithSecretGenerateName(generateName string) *Builder {
+	b.rs.Spec.Secret.GenerateName = generateName
+	return b
+}
+
+// WithType sets the type of the secret deployed to the targets.
+func (b *Builder) WithType(secretType corev1.SecretType) *Builder {
+	b.rs.Spec.Secret.Type = secretType
+	return b
+}
+
+// WithSecretLabels adds the provided labels to the secret deployed to the targets.
+func (b *Builder) WithSecretLabels(labels map[string]string) *Builder {
+	b.rs.Spec.Secret.Labels = mergeMaps(b.rs.Spec.Secret.Labels, labels)
+	return b
+}
+
+// WithSecretAnnotations adds the provided annotations to the secret deployed to the targets.
+func (b *Builder) WithSecretAnnotations(annotations map[string]string) *Builder {
+	b.rs.Spec.Secret.Annotations = mergeMaps(b.rs.Spec.Secret.Annotations, annotations)
+	return b
+}
+
+// WithOptionalKeys adds the provided keys to the optional keys of the secret deployed to the targets.
+func (b *Builder) WithOptionalKeys(keys ...string) *Builder {
+	b.rs.Spec.Secret.OptionalKeyNames = append(b.rs.Spec.Secret.OptionalKeyNames, keys...)
+	return b
+}
+
+// WithDeploymentMode sets the mode of the deployment of the secret to the targets.
+func (b *Builder) WithDeploymentMode(mode api.SecretDeploymentMode) *Builder {
+	b.rs.Spec.Secret.DeploymentMode = mode
+	return b
+}
+
+// WithTarget adds the target in the provided namespace of the local cluster.
+func (b *Builder) WithTarget(namespace string) *Builder {
+	b.rs.Spec.Targets = append(b.rs.Spec.Targets, api.RemoteSecretTarget{Namespace: namespace})
+	return b
+}
+
+// WithRemoteTarget adds the target in the provided namespace of the remote cluster with the provided API URL. The credentials to the
+// cluster are read from the secret with the provided name in the namespace of the remote secret.
+func (b *Builder) WithRemoteTarget(namespace, apiUrl, clusterCredentialsSecret string) *Builder {
+	b.rs.Spec.Targets = append(b.rs.Spec.Targets, api.RemoteSecretTarget{
+		Namespace:                namespace,
+		ApiUrl:                   apiUrl,
+		ClusterCredentialsSecret: clusterCredentialsSecret,
+	})
+	return b
+}
+
+// WithClusterTarget adds the target in the provided namespace of the remote cluster with the provided name in the cluster registry.
+func (b *Builder) WithClusterTarget(namespace, cluster string) *Builder {
+	b.rs.Spec.Targets = append(b.rs.Spec.Targets, api.RemoteSecretTarget{Namespace: namespace, Cluster: cluster})
+	return b
+}
+
+// WithDataFrom adds the object of the provided kind and name in the namespace of the remote secret to the data sources.
+func (b *Builder) WithDataFrom(kind api.DataFromKind, name string) *Builder {
+	b.rs.Spec.DataSources = append(b.rs.Spec.DataSources, api.DataFrom{Kind: kind, Name: name})
+	return b
+}
+
+// WithDeletionPolicy sets what happens with the deployed secrets when the remote secret is deleted.
+func (b *Builder) WithDeletionPolicy(policy api.DeletionPolicy) *Builder {
+	b.rs.Spec.DeletionPolicy = policy
+	return b
+}
+
+// Build returns a copy of the assembled remote secret. An error is returned if the spec of the remote secret is not valid
+// according to remotesecrets.ValidateSpec.
+func (b *Builder) Build() (*api.RemoteSecret, error) {
+	if b.rs.Name == "" || b.rs.Namespace == "" {
+		return nil, fmt.Errorf("%w: both the name and the namespace of the remote secret must be specified", remotesecrets.InvalidRemoteSecretSpecError)
+	}
+
+	if err := remotesecrets.ValidateSpec(b.rs); err != nil {
+		return nil, fmt.Errorf("failed to build the remote secret %s/%s: %w", b.rs.Namespace, b.rs.Name, err)
+	}
+
+	return b.rs.DeepCopy(), nil
+}
+
+func mergeMaps(target map[string]string, source map[string]string) map[string]string {
+	if len(source) == 0 {
+		return target
+	}
+	if target == nil {
+		target = make(map[string]string, len(source))
+	}
+	for k, v := range source {
+		target[k] = v
+	}
+	return target
+}
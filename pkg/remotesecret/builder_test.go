@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesecret
+
+import (
+	"testing"
+
+	api "github.com/redhat-appstudio/remote-secret/api/v1beta1"
+	"github.com/redhat-appstudio/remote-secret/controllers/remotesecrets"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		b := NewBuilder("rs", "default").
+			WithLabels(map[string]string{"team": "a"}).
+			WithSecretName("creds").
+			WithType(corev1.SecretTypeBasicAuth).
+			WithSecretLabels(map[string]string{"app": "x"}).
+			WithTarget("ns").
+			WithRemoteTarget("ns", "https://remote.cluster", "remote-creds").
+			WithClusterTarget("ns", "prod").
+			WithDataFrom(api.DataFromKindSecret, "source")
+
+		rs, err := b.Build()
+		assert.NoError(t, err)
+		assert.Equal(t, "RemoteSecret", rs.Kind)
+		assert.Equal(t, "rs", rs.Name)
+		assert.Equal(t, "default", rs.Namespace)
+		assert.Equal(t, map[string]string{"team": "a"}, rs.Labels)
+		assert.Equal(t, "creds", rs.Spec.Secret.Name)
+		assert.Equal(t, corev1.SecretTypeBasicAuth, rs.Spec.Secret.Type)
+		assert.Equal(t, map[string]string{"app": "x"}, rs.Spec.Secret.Labels)
+		assert.Equal(t, []api.RemoteSecretTarget{
+			{Namespace: "ns"},
+			{Namespace: "ns", ApiUrl: "https://remote.cluster", ClusterCredentialsSecret: "remote-creds"},
+			{Namespace: "ns", Cluster: "prod"},
+		}, rs.Spec.Targets)
+		assert.Equal(t, []api.DataFrom{{Kind: api.DataFromKindSecret, Name: "source"}}, rs.Spec.DataSources)
+
+		// the built objects are independent of the builder
+		rs.Spec.Targets = nil
+		again, err := b.Build()
+		assert.NoError(t, err)
+		assert.Len(t, again.Spec.Targets, 3)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewBuilder("rs", "default").WithTarget("ns").WithTarget("ns").Build()
+		assert.ErrorIs(t, err, remotesecrets.InvalidRemoteSecretSpecError)
+
+		_, err = NewBuilder("rs", "default").WithRemoteTarget("ns", "https://remote.cluster", "").Build()
+		assert.ErrorIs(t, err, remotesecrets.InvalidRemoteSecretSpecError)
+
+		_, err = NewBuilder("", "default").Build()
+		assert.ErrorIs(t, err, remotesecrets.InvalidRemoteSecretSpecError)
+	})
+}
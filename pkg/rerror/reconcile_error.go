@@ -71,3 +71,8 @@ func (ae *AggregatedError) Error() string {
 func (ae *AggregatedError) HasErrors() bool {
 	return len(ae.errors) > 0
 }
+
+// Errors returns the aggregated errors.
+func (ae *AggregatedError) Errors() []error {
+	return ae.errors
+}
@@ -130,6 +130,13 @@ func (s *AwsSecretStorage) Delete(ctx context.Context, id secretstorage.SecretID
 	return nil
 }
 
+var _ secretstorage.HealthChecker = (*AwsSecretStorage)(nil)
+
+// CheckHealth implements secretstorage.HealthChecker.
+func (s *AwsSecretStorage) CheckHealth(ctx context.Context) error {
+	return s.checkCredentials(ctx)
+}
+
 func (s *AwsSecretStorage) checkCredentials(ctx context.Context) error {
 	// let's try to do simple request to verify that credentials are correct or fail fast
 	_, err := s.client.ListSecrets(ctx, &secretsmanager.ListSecretsInput{MaxResults: aws.Int32(1)})
@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretstorage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// HealthChecker is implemented by the secret storages that can check whether their backend is reachable and the credentials
+// used to access it are still valid.
+type HealthChecker interface {
+	// CheckHealth returns an error if the backend of the storage cannot be used.
+	CheckHealth(ctx context.Context) error
+}
+
+// ReadinessCheck returns a checker for the manager's readiness endpoint that fails if any of the provided storages reports
+// its backend as unhealthy. The storages not implementing the HealthChecker interface are considered healthy. Each check is
+// given at most the provided timeout.
+func ReadinessCheck(timeout time.Duration, storages ...SecretStorage) healthz.Checker {
+	return func(req *http.Request) error {
+		for _, s := range storages {
+			hc, ok := s.(HealthChecker)
+			if !ok {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			err := hc.CheckHealth(ctx)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("the secret storage %T is not healthy: %w", s, err)
+			}
+		}
+
+		return nil
+	}
+}
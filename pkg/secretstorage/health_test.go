@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretstorage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthCheckingStorage struct {
+	TestSecretStorage
+	err error
+}
+
+func (s *healthCheckingStorage) CheckHealth(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("no timeout")
+	}
+	return s.err
+}
+
+func TestReadinessCheck(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+	t.Run("healthy", func(t *testing.T) {
+		check := ReadinessCheck(time.Second, &healthCheckingStorage{}, TestSecretStorage{})
+		assert.NoError(t, check(req))
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		unreachable := errors.New("unreachable")
+		check := ReadinessCheck(time.Second, TestSecretStorage{}, &healthCheckingStorage{err: unreachable})
+		assert.ErrorIs(t, check(req), unreachable)
+	})
+}
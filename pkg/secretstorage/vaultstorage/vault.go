@@ -195,6 +195,19 @@ func (v *VaultSecretStorage) Delete(ctx context.Context, id secretstorage.Secret
 	return nil
 }
 
+var _ secretstorage.HealthChecker = (*VaultSecretStorage)(nil)
+
+// CheckHealth implements secretstorage.HealthChecker. It looks up the token used to access Vault, which checks both that Vault
+// is reachable and that the token is still valid.
+func (v *VaultSecretStorage) CheckHealth(ctx context.Context) error {
+	ctx = httptransport.ContextWithMetrics(ctx, &requestMetricConfig)
+
+	if _, err := v.client.Auth().Token().LookupSelfWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to look up the Vault token: %w", err)
+	}
+	return nil
+}
+
 func (v *VaultSecretStorage) initFields() error {
 	// These fields are only non-nil at the point in time they're called
 	// from init if called from tests that pre-initialize these to work with
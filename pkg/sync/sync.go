@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -165,7 +166,7 @@ func (s *Syncer) update(ctx context.Context, owner client.Object, actual client.
 
 		actualKey := client.ObjectKeyFromObject(actual)
 
-		if isUpdateUsingDeleteCreate(actual.GetObjectKind().GroupVersionKind().Kind) {
+		if isUpdateUsingDeleteCreate(actual.GetObjectKind().GroupVersionKind().Kind) || isImmutabilityPreventingUpdate(actual, blueprint) || isTypeChangePreventingUpdate(actual, blueprint) {
 			err := s.client.Delete(ctx, actual)
 			if err != nil {
 				lg.Error(err, "failed to delete object before re-creating it", "Object", actualKey)
@@ -207,3 +208,51 @@ func isUpdateUsingDeleteCreate(kind string) bool {
 	// ingresses and services have been identified to needs this, too, for reasons that I don't know..
 	return kind == "Service" || kind == "Ingress" || kind == "Route"
 }
+
+// isImmutabilityPreventingUpdate returns true if the actual object cannot be updated in place to match the blueprint because
+// of its immutability. Kubernetes rejects any update of an immutable secret or config map and also doesn't allow to
+// change the immutability of an existing object, so such objects need to be re-created.
+func isImmutabilityPreventingUpdate(actual client.Object, blueprint client.Object) bool {
+	var actualImmutable, blueprintImmutable *bool
+	switch a := actual.(type) {
+	case *corev1.Secret:
+		actualImmutable = a.Immutable
+		if b, ok := blueprint.(*corev1.Secret); ok {
+			blueprintImmutable = b.Immutable
+		}
+	case *corev1.ConfigMap:
+		actualImmutable = a.Immutable
+		if b, ok := blueprint.(*corev1.ConfigMap); ok {
+			blueprintImmutable = b.Immutable
+		}
+	default:
+		return false
+	}
+
+	isTrue := func(b *bool) bool { return b != nil && *b }
+
+	return isTrue(actualImmutable) || isTrue(actualImmutable) != isTrue(blueprintImmutable)
+}
+
+// isTypeChangePreventingUpdate returns true if the actual object is a secret of a different type than the blueprint. The type of
+// a secret is immutable, so the secret needs to be re-created to change it.
+func isTypeChangePreventingUpdate(actual client.Object, blueprint client.Object) bool {
+	a, ok := actual.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	b, ok := blueprint.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+
+	return SecretType(a) != SecretType(b)
+}
+
+// SecretType returns the type of the secret, defaulting to the opaque secrets as Kubernetes does.
+func SecretType(secret *corev1.Secret) corev1.SecretType {
+	if secret.Type == "" {
+		return corev1.SecretTypeOpaque
+	}
+	return secret.Type
+}
@@ -16,6 +16,7 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/redhat-appstudio/remote-secret/pkg/infrastructure"
@@ -26,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -215,3 +217,135 @@ func TestSyncKeepsAdditionalAnnosAndLabels(t *testing.T) {
 	assert.Equal(t, expectedValues, synced.Labels, "Unexpected labels on the synced object")
 	assert.Equal(t, expectedValues, synced.Annotations, "Unexpected annotations on the synced object")
 }
+
+// immutabilityEnforcingClient rejects the updates of the secrets that Kubernetes would reject because of their immutability or
+// the change of their type. The fake client doesn't check that.
+type immutabilityEnforcingClient struct {
+	client.Client
+}
+
+func (c *immutabilityEnforcingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if s, ok := obj.(*corev1.Secret); ok {
+		current := &corev1.Secret{}
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(s), current); err != nil {
+			return err //nolint:wrapcheck // this is just a test
+		}
+		if (current.Immutable != nil && *current.Immutable) || (s.Immutable != nil && *s.Immutable) {
+			return errors.New("field is immutable")
+		}
+		if SecretType(current) != SecretType(s) {
+			return errors.New("type: field is immutable")
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...) //nolint:wrapcheck // this is just a test
+}
+
+func TestSyncRecreatesOnImmutabilityChange(t *testing.T) {
+	test := func(t *testing.T, actualImmutable *bool, blueprintImmutable *bool) {
+		preexisting := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Data:      map[string][]byte{"a": []byte("old")},
+			Immutable: actualImmutable,
+		}
+
+		blueprint := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Data:      map[string][]byte{"a": []byte("new")},
+			Immutable: blueprintImmutable,
+		}
+
+		cl := &immutabilityEnforcingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(preexisting).Build()}
+		syncer := Syncer{client: cl}
+
+		_, _, err := syncer.Sync(context.TODO(), nil, blueprint, cmp.Options{})
+		assert.NoError(t, err)
+
+		synced := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(blueprint), synced))
+		assert.Equal(t, []byte("new"), synced.Data["a"])
+		assert.Equal(t, blueprintImmutable, synced.Immutable)
+	}
+
+	t.Run("mutable to immutable", func(t *testing.T) {
+		test(t, nil, pointer.Bool(true))
+	})
+
+	t.Run("immutable to mutable", func(t *testing.T) {
+		test(t, pointer.Bool(true), pointer.Bool(false))
+	})
+
+	t.Run("immutable data change", func(t *testing.T) {
+		test(t, pointer.Bool(true), pointer.Bool(true))
+	})
+}
+
+func TestSyncRecreatesOnTypeChange(t *testing.T) {
+	test := func(t *testing.T, actualType corev1.SecretType, blueprintType corev1.SecretType) {
+		preexisting := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{"tls.crt": []byte("old"), "tls.key": []byte("old")},
+			Type: actualType,
+		}
+
+		blueprint := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "secret",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{"tls.crt": []byte("new"), "tls.key": []byte("new")},
+			Type: blueprintType,
+		}
+
+		cl := &immutabilityEnforcingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(preexisting).Build()}
+		syncer := Syncer{client: cl}
+
+		_, _, err := syncer.Sync(context.TODO(), nil, blueprint, cmp.Options{})
+		assert.NoError(t, err)
+
+		synced := &corev1.Secret{}
+		assert.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(blueprint), synced))
+		assert.Equal(t, []byte("new"), synced.Data["tls.crt"])
+		assert.Equal(t, SecretType(blueprint), SecretType(synced))
+	}
+
+	t.Run("opaque to tls", func(t *testing.T) {
+		test(t, corev1.SecretTypeOpaque, corev1.SecretTypeTLS)
+	})
+
+	t.Run("tls to opaque", func(t *testing.T) {
+		test(t, corev1.SecretTypeTLS, corev1.SecretTypeOpaque)
+	})
+
+	t.Run("tls to default", func(t *testing.T) {
+		test(t, corev1.SecretTypeTLS, "")
+	})
+
+	t.Run("default to opaque", func(t *testing.T) {
+		test(t, "", corev1.SecretTypeOpaque)
+	})
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upload contains the means to upload the data into the remote secrets out-of-band, i.e. without putting them
+// into the remote secret objects themselves. The data is put into an ephemeral upload secret in the namespace of the remote
+// secret. The controller stores the data of the upload secret as the data of the remote secret and deletes the upload secret.
+package upload
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretLabel is the label that marks the secret as an upload secret. Its value must be SecretLabelValue.
+	SecretLabel = "appstudio.redhat.com/upload-secret" //#nosec G101 -- false positive, this is not a token
+	// SecretLabelValue is the value of the SecretLabel that the controller looks for.
+	SecretLabelValue = "remotesecret"
+	// RemoteSecretNameAnnotation is the annotation on the upload secret with the name of the remote secret to upload the data to.
+	// If the remote secret doesn't exist, it is created.
+	RemoteSecretNameAnnotation = "appstudio.redhat.com/remotesecret-name" //#nosec G101 -- false positive, this is not a token
+	// TargetTypeAnnotation is the annotation on the upload secret with the type of the target of the remote secret that is created
+	// if it doesn't exist. Only "namespace" is supported.
+	TargetTypeAnnotation = "appstudio.redhat.com/remotesecret-target-type"
+	// TargetNameAnnotation is the annotation on the upload secret with the name of the target of the remote secret that is created
+	// if it doesn't exist.
+	TargetNameAnnotation = "appstudio.redhat.com/remotesecret-target-name"
+)
+
+// NewSecret creates the upload secret that uploads the provided data to the existing remote secret with the provided key.
+// The secret needs to be created in the cluster for the upload to happen.
+func NewSecret(remoteSecret client.ObjectKey, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: remoteSecret.Name + "-upload-",
+			Namespace:    remoteSecret.Namespace,
+			Labels: map[string]string{
+				SecretLabel: SecretLabelValue,
+			},
+			Annotations: map[string]string{
+				RemoteSecretNameAnnotation: remoteSecret.Name,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// Data uploads the provided data to the remote secret with the provided key by creating an upload secret in the cluster. The upload
+// itself happens asynchronously once the controller processes the upload secret.
+func Data(ctx context.Context, cl client.Client, remoteSecret client.ObjectKey, data map[string][]byte) (*corev1.Secret, error) {
+	secret := NewSecret(remoteSecret, data)
+	if err := cl.Create(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to create the upload secret for the remote secret %s: %w", remoteSecret, err)
+	}
+
+	return secret, nil
+}
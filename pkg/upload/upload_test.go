@@ -0,0 +1,43 @@
+//
+// Copyright (c) 2021 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestData(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	_, err := Data(context.TODO(), cl, client.ObjectKey{Name: "rs", Namespace: "default"}, map[string][]byte{"a": []byte("b")})
+	assert.NoError(t, err)
+
+	secrets := &corev1.SecretList{}
+	assert.NoError(t, cl.List(context.TODO(), secrets, client.InNamespace("default"), client.MatchingLabels{SecretLabel: SecretLabelValue}))
+	assert.Len(t, secrets.Items, 1)
+
+	s := secrets.Items[0]
+	assert.Equal(t, "rs", s.Annotations[RemoteSecretNameAnnotation])
+	assert.Equal(t, []byte("b"), s.Data["a"])
+}